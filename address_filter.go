@@ -0,0 +1,55 @@
+package comparison
+
+import (
+	"regexp"
+	"strings"
+)
+
+// filterAddresses drops the resources excluded by the IncludeAddresses and ExcludeAddresses options,
+// and by the IncludeTypes and ExcludeTypes options. The resources map is returned unchanged when no
+// filter is configured.
+func filterAddresses(resources map[string]interface{}, opts *CompareOptions) map[string]interface{} {
+	if len(opts.IncludeAddresses) == 0 && len(opts.ExcludeAddresses) == 0 && len(opts.IncludeTypes) == 0 && len(opts.ExcludeTypes) == 0 {
+		return resources
+	}
+
+	include := compileAddressPatterns(opts.IncludeAddresses)
+	exclude := compileAddressPatterns(opts.ExcludeAddresses)
+	includeTypes := compileAddressPatterns(opts.IncludeTypes)
+	excludeTypes := compileAddressPatterns(opts.ExcludeTypes)
+
+	result := make(map[string]interface{}, len(resources))
+	for address, resource := range resources {
+		if !patternsAllow(address, include, exclude) {
+			continue
+		}
+		if !patternsAllow(resourceType(address, resource), includeTypes, excludeTypes) {
+			continue
+		}
+		result[address] = resource
+	}
+	return result
+}
+
+// patternsAllow reports whether s matches one of the include patterns, or there are none, and none
+// of the exclude patterns.
+func patternsAllow(s string, include, exclude []*regexp.Regexp) bool {
+	if len(include) > 0 && !matchesAnyPattern(s, include) {
+		return false
+	}
+	return !matchesAnyPattern(s, exclude)
+}
+
+// compileAddressPatterns compiles address wildcard patterns, where `*` matches any run of characters
+// and everything else matches literally.
+func compileAddressPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		compiled = append(compiled, regexp.MustCompile("^"+strings.Join(parts, ".*")+"$"))
+	}
+	return compiled
+}
@@ -0,0 +1,139 @@
+package comparison
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterAddresses(t *testing.T) {
+	resources := map[string]interface{}{
+		"aws_instance.web":                       1,
+		"aws_instance.api[0]":                    2,
+		"module.network.aws_vpc.main":            3,
+		`module.network.aws_subnet.private["a"]`: 4,
+		"module.app.aws_instance.web":            5,
+	}
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{`module.network.aws_subnet.private["a"]`, "aws_instance.api[0]", "aws_instance.web", "module.app.aws_instance.web", "module.network.aws_vpc.main"},
+		},
+		{
+			name:     "include module",
+			include:  []string{"module.network.*"},
+			expected: []string{`module.network.aws_subnet.private["a"]`, "module.network.aws_vpc.main"},
+		},
+		{
+			name:     "include resource type",
+			include:  []string{"aws_instance.*"},
+			expected: []string{"aws_instance.api[0]", "aws_instance.web"},
+		},
+		{
+			name:     "brackets match literally",
+			include:  []string{"aws_instance.api[0]", `*.private["a"]`},
+			expected: []string{`module.network.aws_subnet.private["a"]`, "aws_instance.api[0]"},
+		},
+		{
+			name:     "exclude",
+			exclude:  []string{"module.*"},
+			expected: []string{"aws_instance.api[0]", "aws_instance.web"},
+		},
+		{
+			name:     "exclude wins over include",
+			include:  []string{"*aws_instance.*"},
+			exclude:  []string{"module.app.*"},
+			expected: []string{"aws_instance.api[0]", "aws_instance.web"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered := filterAddresses(resources, &CompareOptions{IncludeAddresses: tc.include, ExcludeAddresses: tc.exclude})
+
+			addresses := make([]string, 0, len(filtered))
+			for address := range filtered {
+				addresses = append(addresses, address)
+			}
+			sort.Strings(addresses)
+			sort.Strings(tc.expected)
+			assert.Equal(t, tc.expected, addresses)
+		})
+	}
+}
+
+func TestComparePlans_AddressFilter(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "1"}}},
+		{"address": "module.network.aws_vpc.main", "change": {"after": {"cidr": "10.0.0.0/16"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "2"}}},
+		{"address": "module.network.aws_vpc.main", "change": {"after": {"cidr": "10.1.0.0/16"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{ExcludeAddresses: []string{"module.network.*"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, result.Text, "aws_instance.web")
+	assert.NotContains(t, result.Text, "module.network")
+	assert.Equal(t, []ExpectedChange{{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"}}, actualChanges(result.Changes))
+
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{IncludeAddresses: []string{"module.other.*"}})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+}
+
+func TestFilterAddresses_Types(t *testing.T) {
+	resources := map[string]interface{}{
+		"aws_iam_role.app":                       map[string]interface{}{"type": "aws_iam_role"},
+		"aws_iam_policy.app":                     1,
+		`module.iam.aws_iam_user.ci["deploy"]`:   2,
+		"module.storage[0].aws_s3_bucket.assets": 3,
+		"data.aws_iam_policy_document.assume":    4,
+		"aws_instance.web":                       5,
+	}
+
+	tests := []struct {
+		name     string
+		opts     *CompareOptions
+		expected []string
+	}{
+		{
+			name:     "include type wildcard",
+			opts:     &CompareOptions{IncludeTypes: []string{"aws_iam_*"}},
+			expected: []string{"aws_iam_role.app", "aws_iam_policy.app", `module.iam.aws_iam_user.ci["deploy"]`, "data.aws_iam_policy_document.assume"},
+		},
+		{
+			name:     "type inside a module",
+			opts:     &CompareOptions{IncludeTypes: []string{"aws_s3_bucket"}},
+			expected: []string{"module.storage[0].aws_s3_bucket.assets"},
+		},
+		{
+			name:     "exclude type",
+			opts:     &CompareOptions{ExcludeTypes: []string{"aws_iam_*"}},
+			expected: []string{"module.storage[0].aws_s3_bucket.assets", "aws_instance.web"},
+		},
+		{
+			name:     "combined with address filters",
+			opts:     &CompareOptions{IncludeTypes: []string{"aws_iam_*"}, ExcludeAddresses: []string{"module.*", "data.*"}},
+			expected: []string{"aws_iam_role.app", "aws_iam_policy.app"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			addresses := getSortedKeys(filterAddresses(resources, tc.opts), nil)
+			sort.Strings(tc.expected)
+			assert.Equal(t, tc.expected, addresses)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package comparison
+
+import "strings"
+
+// aliasResourceAddresses returns the resources rekeyed by the given address aliases. An alias that
+// collides with another resource is not applied.
+func aliasResourceAddresses(resources map[string]interface{}, aliases map[string]string) map[string]interface{} {
+	return rekeyResources(resources, func(address string, _ interface{}) string {
+		return aliases[address]
+	})
+}
+
+// aliasResourceTypes returns the resources rekeyed with their resource type renamed by the given aliases,
+// e.g. `module.lb.aws_alb.main` becomes `module.lb.aws_lb.main` for an aws_alb => aws_lb alias. A renamed
+// address that collides with another resource is not applied.
+func aliasResourceTypes(resources map[string]interface{}, aliases map[string]string) map[string]interface{} {
+	return rekeyResources(resources, func(address string, _ interface{}) string {
+		return renameResourceType(address, aliases)
+	})
+}
+
+// renameResourceType replaces the resource type in an address when it has an alias.
+func renameResourceType(address string, aliases map[string]string) string {
+	modulePath := modulePathPattern.FindString(address)
+	rest := strings.TrimPrefix(address, modulePath)
+
+	mode := ""
+	if strings.HasPrefix(rest, "data.") {
+		mode, rest = "data.", strings.TrimPrefix(rest, "data.")
+	}
+
+	resType, name, ok := strings.Cut(rest, ".")
+	if !ok {
+		return address
+	}
+
+	alias, ok := aliases[resType]
+	if !ok {
+		return address
+	}
+
+	return modulePath + mode + alias + "." + name
+}
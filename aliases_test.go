@@ -0,0 +1,122 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareResourceSections_AddressAliases(t *testing.T) {
+	origPlan := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "aws_instance.legacy_web",
+				"change":  map[string]interface{}{"after": map[string]interface{}{"instance_type": "t3.micro"}},
+			},
+			map[string]interface{}{
+				"address": "aws_s3_bucket.logs",
+				"change":  map[string]interface{}{"after": map[string]interface{}{"bucket": "logs"}},
+			},
+		},
+	}
+	newPlan := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "aws_instance.web",
+				"change":  map[string]interface{}{"after": map[string]interface{}{"instance_type": "t3.large"}},
+			},
+			map[string]interface{}{
+				"address": "aws_s3_bucket.logs",
+				"change":  map[string]interface{}{"after": map[string]interface{}{"bucket": "logs"}},
+			},
+		},
+	}
+
+	// Without aliases the rename is an add and a remove
+	diff, _, _ := compareResourceSections(origPlan, newPlan, &CompareOptions{})
+	assert.Contains(t, diff, "+ aws_instance.web")
+	assert.Contains(t, diff, "- aws_instance.legacy_web")
+
+	opts := &CompareOptions{AddressAliases: map[string]string{"aws_instance.legacy_web": "aws_instance.web"}}
+	diff, diffMap, hasDiff := compareResourceSections(origPlan, newPlan, opts)
+
+	assert.True(t, hasDiff)
+	assert.NotContains(t, diff, "+ aws_instance.web")
+	assert.NotContains(t, diff, "legacy_web")
+	assert.Contains(t, diff, "~ instance_type: t3.micro => t3.large")
+	assert.Empty(t, diffMap["added"])
+	assert.Empty(t, diffMap["removed"])
+
+	changed := diffMap["changed"].([]map[string]interface{})
+	assert.Len(t, changed, 1)
+	assert.Equal(t, "aws_instance.web", changed[0]["address"])
+}
+
+func TestCompareResourceSections_TypeAliases(t *testing.T) {
+	makePlan := func(address, listener string) map[string]interface{} {
+		return map[string]interface{}{
+			"resource_changes": []interface{}{
+				map[string]interface{}{
+					"address": address,
+					"change":  map[string]interface{}{"after": map[string]interface{}{"listener": listener}},
+				},
+			},
+		}
+	}
+	origPlan := makePlan("module.lb.aws_alb.main", "http")
+	newPlan := makePlan("module.lb.aws_lb.main", "https")
+
+	tests := []struct {
+		name        string
+		aliases     map[string]string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "unaliased type rename is an add and a remove",
+			contains: []string{"+ module.lb.aws_lb.main", "- module.lb.aws_alb.main"},
+		},
+		{
+			name:        "aliased type rename is compared as the same resource",
+			aliases:     map[string]string{"aws_alb": "aws_lb"},
+			contains:    []string{"module.lb.aws_lb.main\n", "~ listener: http => https"},
+			notContains: []string{"+ module.lb.aws_lb.main", "aws_alb"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _, hasDiff := compareResourceSections(origPlan, newPlan, &CompareOptions{TypeAliases: tc.aliases})
+
+			assert.True(t, hasDiff)
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff, notExpected)
+			}
+		})
+	}
+}
+
+func TestAliasResourceTypes_Collision(t *testing.T) {
+	resources := map[string]interface{}{
+		"aws_alb.main": map[string]interface{}{"address": "aws_alb.main"},
+		"aws_lb.main":  map[string]interface{}{"address": "aws_lb.main"},
+		"aws_alb.api":  map[string]interface{}{"address": "aws_alb.api"},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"aws_alb.main": resources["aws_alb.main"],
+		"aws_lb.main":  resources["aws_lb.main"],
+		"aws_lb.api":   resources["aws_alb.api"],
+	}, aliasResourceTypes(resources, map[string]string{"aws_alb": "aws_lb"}))
+}
+
+func TestRenameResourceType(t *testing.T) {
+	aliases := map[string]string{"aws_alb": "aws_lb"}
+
+	assert.Equal(t, "aws_lb.main", renameResourceType("aws_alb.main", aliases))
+	assert.Equal(t, `module.a["x"].data.aws_lb.main[0]`, renameResourceType(`module.a["x"].data.aws_alb.main[0]`, aliases))
+	assert.Equal(t, "aws_instance.web", renameResourceType("aws_instance.web", aliases))
+}
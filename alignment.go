@@ -0,0 +1,46 @@
+package comparison
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// alignedLinePattern matches the attribute lines of a resource block: changed, added and removed attributes
+// ("  ~ name: value") and context attributes ("    name: value"). Lines of nested and multi-line value diffs,
+// which are indented further or carry their marker after four spaces, do not match.
+var alignedLinePattern = regexp.MustCompile(`^(  [~+-] |    )([^\s~+-][^\n]*?): `)
+
+// displayWidth measures text in terminal cells. East Asian ambiguous characters are always narrow, so the
+// alignment does not depend on the locale of the machine producing the diff.
+var displayWidth = &runewidth.Condition{EastAsianWidth: false, StrictEmojiNeutral: true}
+
+// alignAttributeLines pads the attribute names of a resource block's lines to the display width of the
+// longest one, so the values line up in a column.
+func alignAttributeLines(block string) string {
+	lines := strings.SplitAfter(block, "\n")
+
+	width := 0
+	for _, line := range lines {
+		if match := alignedLinePattern.FindStringSubmatch(line); match != nil {
+			width = max(width, displayWidth.StringWidth(match[2]))
+		}
+	}
+	if width == 0 {
+		return block
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		match := alignedLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			sb.WriteString(line)
+			continue
+		}
+
+		padding := strings.Repeat(" ", width-displayWidth.StringWidth(match[2]))
+		sb.WriteString(match[1] + match[2] + ": " + padding + line[len(match[0]):])
+	}
+	return sb.String()
+}
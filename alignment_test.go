@@ -0,0 +1,71 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlignAttributeLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{
+			name:     "changed, added and removed attributes",
+			block:    "aws_instance.web\n  ~ ami: ami-1 => ami-2\n  + instance_type: t3.micro\n  - id: i-1\n",
+			expected: "aws_instance.web\n  ~ ami:           ami-1 => ami-2\n  + instance_type: t3.micro\n  - id:            i-1\n",
+		},
+		{
+			name:     "context attributes",
+			block:    "aws_iam_role.app\n    name: app-role\n  ~ max_session_duration: 3600 => 7200\n",
+			expected: "aws_iam_role.app\n    name:                 app-role\n  ~ max_session_duration: 3600 => 7200\n",
+		},
+		{
+			name:     "multi-byte names",
+			block:    "null_resource.x\n  ~ 名前: a => b\n  ~ größe: 1 => 2\n  ~ id: 1 => 2\n",
+			expected: "null_resource.x\n  ~ 名前:  a => b\n  ~ größe: 1 => 2\n  ~ id:    1 => 2\n",
+		},
+		{
+			name:     "multi-line and nested values are left as they are",
+			block:    "aws_instance.web\n  ~ user_data:\n      line: 1\n    - key: a\n    + key: b\n  ~ instance_type: t3.micro => t3.large\n",
+			expected: "aws_instance.web\n  ~ user_data:\n      line: 1\n    - key: a\n    + key: b\n  ~ instance_type: t3.micro => t3.large\n",
+		},
+		{
+			name:     "values holding colons are split at the name",
+			block:    "aws_instance.web\n  ~ arn: arn:aws:a => arn:aws:b\n  ~ description: a: b => c\n",
+			expected: "aws_instance.web\n  ~ arn:         arn:aws:a => arn:aws:b\n  ~ description: a: b => c\n",
+		},
+		{
+			name:     "no attribute lines",
+			block:    "aws_instance.web\n",
+			expected: "aws_instance.web\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, alignAttributeLines(tc.block))
+		})
+	}
+}
+
+func TestCompareResources_AlignAttributeValues(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.api": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-1", "instance_type": "t3.micro"}},
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-1", "subnet": "a"}},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.api": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-2", "instance_type": "t3.large"}},
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-2"}},
+	}
+
+	// Names are padded per resource block
+	diff, _ := compareResources(origRes, newRes, &CompareOptions{AlignAttributeValues: true})
+	assert.Equal(t, "aws_instance.api\n  ~ ami:           ami-1 => ami-2\n  ~ instance_type: t3.micro => t3.large\n"+
+		"aws_instance.web\n  ~ ami:    ami-1 => ami-2\n  - subnet: a\n", diff)
+
+	diff, _ = compareResources(origRes, newRes, &CompareOptions{})
+	assert.Contains(t, diff, "  ~ ami: ami-1 => ami-2\n  ~ instance_type: t3.micro => t3.large\n")
+}
@@ -0,0 +1,101 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// CompareToBaseline returns the changes in newDiffMap that a previously approved baselineDiffMap does
+// not contain, so only net-new changes need review. The baseline may be read back from JSON. Entries
+// match like IntersectDiffs, on their section, category and name or address, and a matched entry is
+// still reported when its contents differ from the approved one. Changed resources are reduced to the
+// attributes that are new or differ. The returned entries are taken from newDiffMap, and sections
+// without new changes are omitted, so an empty result means every change was approved.
+func CompareToBaseline(newDiffMap, baselineDiffMap map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for section, newSection := range newDiffMap {
+		switch typed := newSection.(type) {
+		case map[string]interface{}:
+			baselineSection, _ := baselineDiffMap[section].(map[string]interface{})
+			if delta := subtractCategories(typed, baselineSection); len(delta) > 0 {
+				result[section] = delta
+			}
+		default:
+			if delta := subtractEntries(newSection, baselineDiffMap[section]); len(delta) > 0 {
+				result[section] = delta
+			}
+		}
+	}
+
+	return result
+}
+
+// subtractCategories returns the entries of each category of a that b does not contain. Normalization
+// and noise diagnostics are not changes and are left out.
+func subtractCategories(a, b map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	categories := make([]string, 0, len(a))
+	for category := range a {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		if category == "normalized_away" || category == "noise" {
+			continue
+		}
+		if delta := subtractEntries(a[category], b[category]); len(delta) > 0 {
+			result[category] = delta
+		}
+	}
+
+	return result
+}
+
+// subtractEntries returns the entries of a without an identical entry in b.
+func subtractEntries(a, b interface{}) []map[string]interface{} {
+	bEntries := make(map[string]map[string]interface{})
+	for _, entry := range entryList(b) {
+		bEntries[entryKey(entry)] = entry
+	}
+
+	delta := make([]map[string]interface{}, 0)
+	for _, entry := range entryList(a) {
+		other, exists := bEntries[entryKey(entry)]
+		if !exists {
+			delta = append(delta, entry)
+			continue
+		}
+
+		// Changed resources only differ in the attributes the baseline did not approve
+		if attrs, ok := entry["attributes"].(map[string]interface{}); ok {
+			otherAttrs, _ := other["attributes"].(map[string]interface{})
+			newAttrs := subtractCategories(attrs, otherAttrs)
+			if len(newAttrs) == 0 {
+				continue
+			}
+
+			entry = copyEntry(entry)
+			entry["attributes"] = newAttrs
+			delta = append(delta, entry)
+			continue
+		}
+
+		if !sameEntryContents(entry, other) {
+			delta = append(delta, entry)
+		}
+	}
+
+	return delta
+}
+
+// sameEntryContents reports whether two diff map entries hold the same values once encoded as JSON,
+// so entries decoded from a stored baseline compare equal to freshly computed ones.
+func sameEntryContents(a, b map[string]interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
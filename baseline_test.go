@@ -0,0 +1,93 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareToBaseline(t *testing.T) {
+	origJSON := `{
+		"variables": {"env": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		]
+	}`
+	approvedJSON := `{
+		"variables": {"env": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "instance_type": "t3.micro"}}}
+		]
+	}`
+
+	approved, err := ComparePlans(origJSON, approvedJSON, &CompareOptions{})
+	require.NoError(t, err)
+
+	// The baseline is stored as JSON and read back
+	encoded, err := json.Marshal(approved.Changes)
+	require.NoError(t, err)
+	var baseline map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &baseline))
+
+	tests := []struct {
+		name     string
+		newJSON  string
+		expected []ExpectedChange
+	}{
+		{
+			name:     "only approved changes",
+			newJSON:  approvedJSON,
+			expected: []ExpectedChange{},
+		},
+		{
+			name: "new attribute change and addition",
+			newJSON: `{
+				"variables": {"env": {"value": "prod"}},
+				"resource_changes": [
+					{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "instance_type": "t3.large"}}},
+					{"address": "aws_sqs_queue.jobs", "change": {"after": {"name": "jobs"}}}
+				]
+			}`,
+			expected: []ExpectedChange{
+				{Section: "resources", Kind: "added", Address: "aws_sqs_queue.jobs"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "instance_type"},
+			},
+		},
+		{
+			name: "approved change with a different value",
+			newJSON: `{
+				"variables": {"env": {"value": "staging"}},
+				"resource_changes": [
+					{"address": "aws_instance.web", "change": {"after": {"ami": "ami-3", "instance_type": "t3.micro"}}}
+				]
+			}`,
+			expected: []ExpectedChange{
+				{Section: "variables", Kind: "changed", Address: "env"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+			},
+		},
+		{
+			name: "approved changes that did not happen are not reported",
+			newJSON: `{
+				"variables": {"env": {"value": "dev"}},
+				"resource_changes": [
+					{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "instance_type": "t3.micro"}}}
+				]
+			}`,
+			expected: []ExpectedChange{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(origJSON, tc.newJSON, &CompareOptions{})
+			require.NoError(t, err)
+
+			delta := CompareToBaseline(result.Changes, baseline)
+			assert.Equal(t, tc.expected, actualChanges(delta))
+		})
+	}
+}
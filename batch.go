@@ -0,0 +1,94 @@
+package comparison
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// PlanPair is an original and a new plan document to compare.
+type PlanPair struct {
+	Original string
+	New      string
+}
+
+// BatchResult is the outcome of comparing one plan pair. Exactly one of Diff and Err is set.
+type BatchResult struct {
+	Diff *PlanDiff
+	Err  error
+}
+
+// CompareBatch compares several plan pairs, keyed by name, with the same options. Up to
+// BatchConcurrency pairs are compared at once by a pool of workers sharing one Differ; by default pairs
+// are compared one at a time in name order. Without ContinueOnError the batch stops starting new pairs
+// once one fails and returns the error of the failing pair first in name order; with it, each failure is
+// recorded in that pair's result and every pair is compared.
+func CompareBatch(pairs map[string]PlanPair, opts *CompareOptions) (map[string]*BatchResult, error) {
+	opts = resolveOptions(opts)
+	differ, err := NewDiffer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := compareBatchPairs(differ, pairs, names, max(opts.BatchConcurrency, 1), opts.ContinueOnError)
+	if !opts.ContinueOnError {
+		for _, name := range names {
+			if result, ok := results[name]; ok && result.Err != nil {
+				return nil, errors.Wrapf(result.Err, "error comparing plan pair %q", name)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// compareBatchPairs compares the named pairs with the given number of workers. Unless continueOnError
+// is set, workers stop taking pairs after a failure, so later pairs may have no result.
+func compareBatchPairs(differ *Differ, pairs map[string]PlanPair, names []string, workers int, continueOnError bool) map[string]*BatchResult {
+	results := make(map[string]*BatchResult, len(names))
+	queue := make(chan string)
+
+	var mu sync.Mutex
+	failed := false
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(names)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				diff, err := differ.Compare(pairs[name].Original, pairs[name].New)
+
+				mu.Lock()
+				if err != nil {
+					results[name] = &BatchResult{Err: err}
+					failed = true
+				} else {
+					results[name] = &BatchResult{Diff: diff}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, name := range names {
+		mu.Lock()
+		stop := failed && !continueOnError
+		mu.Unlock()
+		if stop {
+			break
+		}
+		queue <- name
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
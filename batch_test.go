@@ -0,0 +1,88 @@
+package comparison
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareBatch(t *testing.T) {
+	plan := func(env string) string {
+		return `{"variables": {"env": {"value": "` + env + `"}}}`
+	}
+	pairs := map[string]PlanPair{
+		"a-changed":   {Original: plan("dev"), New: plan("prod")},
+		"b-corrupt":   {Original: plan("dev"), New: `{"variables": `},
+		"c-identical": {Original: plan("dev"), New: plan("dev")},
+	}
+
+	t.Run("continue on error", func(t *testing.T) {
+		results, err := CompareBatch(pairs, &CompareOptions{ContinueOnError: true})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		require.NotNil(t, results["a-changed"].Diff)
+		assert.True(t, results["a-changed"].Diff.HasDiff)
+		assert.NoError(t, results["a-changed"].Err)
+
+		assert.Nil(t, results["b-corrupt"].Diff)
+		assert.Error(t, results["b-corrupt"].Err)
+
+		require.NotNil(t, results["c-identical"].Diff)
+		assert.False(t, results["c-identical"].Diff.HasDiff)
+	})
+
+	t.Run("abort on first error", func(t *testing.T) {
+		results, err := CompareBatch(pairs, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"b-corrupt"`)
+		assert.Nil(t, results)
+	})
+}
+
+func TestCompareBatch_Concurrency(t *testing.T) {
+	pairs := make(map[string]PlanPair)
+	for i := 0; i < 50; i++ {
+		pairs[fmt.Sprintf("workspace-%02d", i)] = PlanPair{
+			Original: fmt.Sprintf(`{"variables": {"n": {"value": %d}}}`, i),
+			New:      fmt.Sprintf(`{"variables": {"n": {"value": %d}}}`, i+i%2),
+		}
+	}
+
+	serial, err := CompareBatch(pairs, nil)
+	require.NoError(t, err)
+
+	concurrent, err := CompareBatch(pairs, &CompareOptions{BatchConcurrency: 8})
+	require.NoError(t, err)
+	require.Len(t, concurrent, len(pairs))
+
+	for name, result := range serial {
+		require.NoError(t, concurrent[name].Err)
+		assert.Equal(t, result.Diff.Text, concurrent[name].Diff.Text, name)
+		assert.Equal(t, result.Diff.Changes, concurrent[name].Diff.Changes, name)
+	}
+	assert.True(t, concurrent["workspace-01"].Diff.HasDiff)
+	assert.False(t, concurrent["workspace-02"].Diff.HasDiff)
+}
+
+func TestCompareBatch_ConcurrentErrors(t *testing.T) {
+	pairs := map[string]PlanPair{
+		"a": {Original: `{}`, New: `{}`},
+		"b": {Original: `{}`, New: `{"variables": `},
+		"c": {Original: `{`, New: `{}`},
+		"d": {Original: `{}`, New: `{}`},
+	}
+
+	_, err := CompareBatch(pairs, &CompareOptions{BatchConcurrency: 4})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"b"`)
+
+	results, err := CompareBatch(pairs, &CompareOptions{BatchConcurrency: 4, ContinueOnError: true})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Error(t, results["b"].Err)
+	assert.Error(t, results["c"].Err)
+	assert.NoError(t, results["d"].Err)
+}
@@ -0,0 +1,165 @@
+package comparison
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// CachingComparer compares plans and keeps the most recent results in an in-memory LRU cache,
+// so repeated comparisons of the same inputs with the same options are served from memory.
+// It is safe for concurrent use. Cached results are shared and must be treated as read-only.
+type CachingComparer struct {
+	mu      sync.Mutex
+	size    int
+	opts    []Option
+	entries map[string]*list.Element
+	order   *list.List
+	hits    uint64
+	misses  uint64
+}
+
+// cacheEntry is a cached comparison result.
+type cacheEntry struct {
+	key    string
+	result *PlanDiff
+}
+
+// NewCachingComparer creates a comparer that caches up to size results. The given options apply to
+// every comparison. A size below one disables caching. Comparisons with options holding functions or
+// caller state, such as ResourceKeyFunc, OnChange, CostEstimator, ValueFormatter or Ignore, are never cached.
+func NewCachingComparer(size int, opts ...Option) *CachingComparer {
+	return &CachingComparer{
+		size:    size,
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Compare compares two plan JSON documents. Per-call options are applied after the comparer's options.
+func (c *CachingComparer) Compare(origPlanJSON, newPlanJSON string, opts ...Option) (*PlanDiff, error) {
+	resolved := newOptions(append(append([]Option{}, c.opts...), opts...)...)
+	if !cacheable(resolved) {
+		return ComparePlans(origPlanJSON, newPlanJSON, resolved)
+	}
+	key := cacheKey(origPlanJSON, newPlanJSON, resolved)
+
+	if result, ok := c.lookup(key); ok {
+		return result, nil
+	}
+
+	result, err := ComparePlans(origPlanJSON, newPlanJSON, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, result)
+	return result, nil
+}
+
+// CacheStats returns the number of cache hits and misses so far.
+func (c *CachingComparer) CacheStats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// lookup returns a cached result and marks it as recently used.
+func (c *CachingComparer) lookup(key string) (*PlanDiff, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).result, true
+}
+
+// store adds a result to the cache, evicting the least recently used entry when full.
+func (c *CachingComparer) store(key string, result *PlanDiff) {
+	if c.size < 1 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheable reports whether a comparison with opts can be cached. Functions and caller state cannot be
+// told apart by the cache key, and OnChange must be called on every comparison.
+func cacheable(opts *CompareOptions) bool {
+	return opts.ResourceKeyFunc == nil && opts.OnChange == nil && opts.CostEstimator == nil &&
+		opts.ValueFormatter == nil && opts.Ignore == nil
+}
+
+// cacheKey hashes both inputs together with the options they are compared with.
+func cacheKey(origPlanJSON, newPlanJSON string, opts *CompareOptions) string {
+	hash := sha256.New()
+	hash.Write([]byte(origPlanJSON))
+	hash.Write([]byte{0})
+	hash.Write([]byte(newPlanJSON))
+	hash.Write([]byte{0})
+	hash.Write(cacheOptionsKey(opts))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cacheOptionsKey serializes the exported options that are compared by value, with patterns by their
+// source. Functions, interfaces and pointers are left out, as cacheable excludes the ones that affect
+// the result.
+func cacheOptionsKey(opts *CompareOptions) []byte {
+	fields := make(map[string]interface{})
+
+	v := reflect.ValueOf(*opts)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		switch value := v.Field(i).Interface().(type) {
+		case []*regexp.Regexp:
+			// Nil patterns match nothing, like maskString treats them
+			patterns := make([]string, 0, len(value))
+			for _, pattern := range value {
+				if pattern != nil {
+					patterns = append(patterns, pattern.String())
+				}
+			}
+			fields[field.Name] = patterns
+		default:
+			switch field.Type.Kind() {
+			case reflect.Func, reflect.Interface, reflect.Ptr:
+				continue
+			}
+			fields[field.Name] = value
+		}
+	}
+
+	// The remaining fields are plain values, slices and maps, which always encode
+	encoded, _ := json.Marshal(fields)
+	return encoded
+}
@@ -0,0 +1,111 @@
+package comparison
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cacheOrigPlan = `{"variables": {"stage": {"value": "dev"}}}`
+	cacheNewPlan  = `{"variables": {"stage": {"value": "prod"}}}`
+)
+
+func TestCachingComparer_HitsAndMisses(t *testing.T) {
+	comparer := NewCachingComparer(10)
+
+	first, err := comparer.Compare(cacheOrigPlan, cacheNewPlan)
+	require.NoError(t, err)
+	second, err := comparer.Compare(cacheOrigPlan, cacheNewPlan)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	hits, misses := comparer.CacheStats()
+	assert.Equal(t, uint64(1), hits)
+	assert.Equal(t, uint64(1), misses)
+
+	// Changing an option must not reuse the cached result
+	third, err := comparer.Compare(cacheOrigPlan, cacheNewPlan, func(o *CompareOptions) {
+		o.RenderStyle = RenderStyle{Change: ChangeStyleLabels}
+	})
+	require.NoError(t, err)
+
+	assert.NotSame(t, first, third)
+	assert.Contains(t, third.Text, "~ stage from dev to prod")
+	hits, misses = comparer.CacheStats()
+	assert.Equal(t, uint64(1), hits)
+	assert.Equal(t, uint64(2), misses)
+}
+
+func TestCachingComparer_Eviction(t *testing.T) {
+	comparer := NewCachingComparer(1)
+
+	_, err := comparer.Compare(cacheOrigPlan, cacheNewPlan)
+	require.NoError(t, err)
+	_, err = comparer.Compare(cacheNewPlan, cacheOrigPlan)
+	require.NoError(t, err)
+
+	// The first pair was evicted by the second
+	_, err = comparer.Compare(cacheOrigPlan, cacheNewPlan)
+	require.NoError(t, err)
+
+	hits, misses := comparer.CacheStats()
+	assert.Equal(t, uint64(0), hits)
+	assert.Equal(t, uint64(3), misses)
+}
+
+func TestCachingComparer_Error(t *testing.T) {
+	comparer := NewCachingComparer(1)
+
+	_, err := comparer.Compare("{", cacheNewPlan)
+	assert.Error(t, err)
+}
+
+func TestCachingComparer_Uncacheable(t *testing.T) {
+	comparer := NewCachingComparer(10)
+	origPlan := `{"resource_changes": [{"address": "aws_instance.a", "change": {"after": {"ami": "ami-1", "name": "x"}}}]}`
+	newPlan := `{"resource_changes": [{"address": "aws_instance.b", "change": {"after": {"ami": "ami-2", "name": "x"}}}]}`
+
+	// Different key functions must not share a result
+	byAddress, err := comparer.Compare(origPlan, newPlan, WithResourceKeyFunc(func(resMap map[string]interface{}) string {
+		return resMap["address"].(string)
+	}))
+	require.NoError(t, err)
+	byName, err := comparer.Compare(origPlan, newPlan, WithResourceKeyFunc(func(resMap map[string]interface{}) string {
+		return "name"
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, byAddress.Text, "+ aws_instance.b")
+	assert.Contains(t, byName.Text, "~ ami: ami-1 => ami-2")
+
+	// OnChange is called on every comparison
+	calls := 0
+	for range 2 {
+		_, err = comparer.Compare(cacheOrigPlan, cacheNewPlan, WithOnChange(func(section, changeType string, detail map[string]interface{}) {
+			calls++
+		}))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+
+	hits, misses := comparer.CacheStats()
+	assert.Equal(t, uint64(0), hits)
+	assert.Equal(t, uint64(0), misses)
+}
+
+func TestCacheKey(t *testing.T) {
+	masks := func(pattern string) *CompareOptions {
+		return &CompareOptions{ValueMasks: []*regexp.Regexp{regexp.MustCompile(pattern)}}
+	}
+
+	assert.Equal(t, cacheKey("a", "b", masks(`\d+`)), cacheKey("a", "b", masks(`\d+`)))
+	assert.NotEqual(t, cacheKey("a", "b", masks(`\d+`)), cacheKey("a", "b", masks(`[a-z]+`)))
+	assert.NotEqual(t, cacheKey("a", "b", &CompareOptions{}), cacheKey("a", "b", &CompareOptions{PlannedOnly: true}))
+
+	// Nil patterns are skipped
+	withNil := &CompareOptions{ValueMasks: []*regexp.Regexp{nil, regexp.MustCompile(`\d+`)}, NoisePatterns: []*regexp.Regexp{nil}}
+	assert.NotPanics(t, func() { cacheKey("a", "b", withNil) })
+	assert.Equal(t, cacheKey("a", "b", masks(`\d+`)), cacheKey("a", "b", withNil))
+}
@@ -0,0 +1,54 @@
+package comparison
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// changeIDLength is the number of hex characters kept from the change ID hash.
+const changeIDLength = 16
+
+// assignChangeIDs adds a change_id to every entry of the variables, resources, tag_changes, drift and
+// outputs sections, and to every attribute entry of changed resources. Noise and normalized-away entries
+// are not changes and get no ID.
+func assignChangeIDs(diffMap map[string]interface{}) {
+	for _, section := range []string{"variables", "resources", "tag_changes", "drift", "outputs"} {
+		categories, ok := diffMap[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for category, entries := range categories {
+			if setAsideCategories[category] {
+				continue
+			}
+			for _, entry := range entryList(entries) {
+				address := entryKey(entry)
+				entry["change_id"] = changeID(section, address, "")
+
+				attrs, ok := entry["attributes"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				for _, attrEntries := range attrs {
+					for _, attr := range entryList(attrEntries) {
+						name, _ := attr["name"].(string)
+						attr["change_id"] = changeID(section, address, name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// changeID derives a stable ID for a change from its section, address and attribute path, so the
+// same logical change has the same ID across runs regardless of the other changes.
+func changeID(section, address, attribute string) string {
+	hash := sha256.New()
+	hash.Write([]byte(section))
+	hash.Write([]byte{0})
+	hash.Write([]byte(address))
+	hash.Write([]byte{0})
+	hash.Write([]byte(attribute))
+	return hex.EncodeToString(hash.Sum(nil))[:changeIDLength]
+}
@@ -0,0 +1,77 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludeChangeIDs_StableAcrossRuns(t *testing.T) {
+	plan := func(instanceType, extra string) string {
+		return `{"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "` + instanceType + `"}}}` + extra + `
+		]}`
+	}
+	extraResource := `, {"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}`
+
+	opts := &CompareOptions{IncludeChangeIDs: true}
+	first, err := ComparePlans(plan("t3.micro", ""), plan("t3.large", ""), opts)
+	require.NoError(t, err)
+	second, err := ComparePlans(plan("t3.micro", ""), plan("t3.xlarge", extraResource), opts)
+	require.NoError(t, err)
+
+	attributeID := func(result *PlanDiff) interface{} {
+		resources := result.Changes["resources"].(map[string]interface{})
+		changed := entryList(resources["changed"])
+		require.Len(t, changed, 1)
+		assert.Equal(t, changeID("resources", "aws_instance.web", ""), changed[0]["change_id"])
+		attrs := changed[0]["attributes"].(map[string]interface{})
+		return entryList(attrs["changed"])[0]["change_id"]
+	}
+
+	firstID := attributeID(first)
+	assert.NotEmpty(t, firstID)
+	assert.Equal(t, firstID, attributeID(second))
+
+	added := entryList(second.Changes["resources"].(map[string]interface{})["added"])
+	require.Len(t, added, 1)
+	assert.Equal(t, changeID("resources", "aws_s3_bucket.logs", ""), added[0]["change_id"])
+}
+
+func TestChangeID(t *testing.T) {
+	assert.Len(t, changeID("resources", "aws_instance.web", "ami"), changeIDLength)
+	assert.Equal(t, changeID("resources", "aws_instance.web", "ami"), changeID("resources", "aws_instance.web", "ami"))
+	assert.NotEqual(t, changeID("resources", "aws_instance.web", "ami"), changeID("outputs", "aws_instance.web", "ami"))
+	assert.NotEqual(t, changeID("resources", "a", "bc"), changeID("resources", "ab", "c"))
+}
+
+func TestIncludeChangeIDs_TagChanges(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "a"}}}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "b"}}}}]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{IncludeChangeIDs: true, SeparateTagChanges: true})
+	require.NoError(t, err)
+
+	changed := entryList(result.Changes["tag_changes"].(map[string]interface{})["changed"])
+	require.Len(t, changed, 1)
+	assert.Equal(t, changeID("tag_changes", "aws_s3_bucket.logs", ""), changed[0]["change_id"])
+	attrs := changed[0]["attributes"].(map[string]interface{})
+	assert.Equal(t, changeID("tag_changes", "aws_s3_bucket.logs", "tags"), entryList(attrs["changed"])[0]["change_id"])
+}
+
+func TestIncludeChangeIDs_SetAsideChanges(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_s3_object.site", "change": {"after": {"key": "a", "etag": "3f2b8c1e-9d4a-4e6f-8a7b-1c2d3e4f5a6b", "body": "x  y"}}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_s3_object.site", "change": {"after": {"key": "b", "etag": "a1b2c3d4-e5f6-4789-8abc-def012345678", "body": "x y"}}}]}`
+
+	opts := &CompareOptions{IncludeChangeIDs: true, NoiseHeuristics: true, IgnoreWhitespace: true, ReportNormalized: true}
+	result, err := ComparePlans(origJSON, newJSON, opts)
+	require.NoError(t, err)
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	assert.Equal(t, changeID("resources", "aws_s3_object.site", ""), entryList(resources["changed"])[0]["change_id"])
+	require.Len(t, entryList(resources["noise"]), 1)
+	assert.NotContains(t, entryList(resources["noise"])[0], "change_id")
+	require.Len(t, entryList(resources["normalized_away"]), 1)
+	assert.NotContains(t, entryList(resources["normalized_away"])[0], "change_id")
+}
@@ -0,0 +1,26 @@
+package comparison
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangedResourceNamesYAML renders the addresses of every affected resource as a sorted YAML sequence, e.g.
+// for feeding a CI job matrix: added, removed, changed, moved, mode-changed and count-changed resources,
+// resources with tag-only changes and drifted resources. Moved resources are listed by their new address.
+func ChangedResourceNamesYAML(diffMap map[string]interface{}) string {
+	affected := affectedResourceNodes(diffMap)
+
+	addresses := make([]string, 0, len(affected))
+	for address := range affected {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	out, err := yaml.Marshal(addresses)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
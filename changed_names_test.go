@@ -0,0 +1,85 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestChangedResourceNamesYAML(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "env"}},
+		},
+		"resources": map[string]interface{}{
+			"added":   []map[string]interface{}{{"address": `aws_s3_bucket.logs["b"]`}},
+			"removed": []map[string]interface{}{{"address": "aws_instance.old"}},
+			"changed": []map[string]interface{}{{"address": "aws_instance.web"}},
+			"moved":   []map[string]interface{}{{"old_address": "aws_iam_role.a", "new_address": "module.iam.aws_iam_role.a"}},
+		},
+		"tag_changes": map[string]interface{}{
+			"changed": []map[string]interface{}{{"address": "aws_sqs_queue.jobs"}},
+		},
+	}
+
+	out := ChangedResourceNamesYAML(diffMap)
+
+	var addresses []string
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &addresses))
+	assert.Equal(t, []string{
+		"aws_instance.old",
+		"aws_instance.web",
+		`aws_s3_bucket.logs["b"]`,
+		"aws_sqs_queue.jobs",
+		"module.iam.aws_iam_role.a",
+	}, addresses)
+	assert.Equal(t, out, ChangedResourceNamesYAML(diffMap))
+}
+
+func TestChangedResourceNamesYAML_ChangeKinds(t *testing.T) {
+	tests := []struct {
+		name          string
+		orig, updated string
+		opts          *CompareOptions
+		expected      []string
+	}{
+		{
+			name:     "count change",
+			orig:     `{"resource_changes": [{"address": "aws_instance.web[0]", "change": {"after": {"ami": "a"}}}, {"address": "aws_instance.web[1]", "change": {"after": {"ami": "a"}}}]}`,
+			updated:  `{"resource_changes": [{"address": "aws_instance.web[0]", "change": {"after": {"ami": "a"}}}, {"address": "aws_instance.web[1]", "change": {"after": {"ami": "a"}}}, {"address": "aws_instance.web[2]", "change": {"after": {"ami": "a"}}}]}`,
+			opts:     &CompareOptions{CollapseCountChurn: true},
+			expected: []string{"aws_instance.web"},
+		},
+		{
+			name:     "mode change",
+			orig:     `{"resource_changes": [{"address": "aws_s3_bucket.logs", "mode": "managed", "change": {"after": {"bucket": "logs"}}}]}`,
+			updated:  `{"resource_changes": [{"address": "aws_s3_bucket.logs", "mode": "data", "change": {"after": {"bucket": "logs"}}}]}`,
+			expected: []string{"aws_s3_bucket.logs"},
+		},
+		{
+			name:     "drift",
+			orig:     `{}`,
+			updated:  `{"resource_drift": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}]}`,
+			opts:     &CompareOptions{DriftMode: DriftModeSection},
+			expected: []string{"aws_s3_bucket.logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ComparePlans(tt.orig, tt.updated, tt.opts)
+			require.NoError(t, err)
+			require.True(t, result.HasDiff)
+
+			var addresses []string
+			require.NoError(t, yaml.Unmarshal([]byte(ChangedResourceNamesYAML(result.Changes)), &addresses))
+			assert.Equal(t, tt.expected, addresses)
+		})
+	}
+}
+
+func TestChangedResourceNamesYAML_NoResources(t *testing.T) {
+	assert.Equal(t, "[]\n", ChangedResourceNamesYAML(map[string]interface{}{}))
+}
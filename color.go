@@ -0,0 +1,65 @@
+package comparison
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ANSI escape sequences used to color printed diffs.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// isTerminal reports whether w is attached to a terminal. It is a variable so tests can simulate a TTY.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// useColor reports whether the diff printed to w should be colored.
+func useColor(w io.Writer, opts *CompareOptions) bool {
+	return opts.ColorEnabled && !opts.Plain && isTerminal(w)
+}
+
+// colorizeDiff colors the added, removed and changed lines of a text diff with ANSI escape sequences.
+// Only the change marker and the value are colored, not the indentation.
+func colorizeDiff(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+
+	var sb strings.Builder
+	for _, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		color := lineColor(content)
+		if color == "" {
+			sb.WriteString(line)
+			continue
+		}
+
+		indent := line[:len(line)-len(content)]
+		body := strings.TrimSuffix(content, "\n")
+		sb.WriteString(indent + color + body + ansiReset + content[len(body):])
+	}
+	return sb.String()
+}
+
+// lineColor returns the color for a diff line by its change marker, or "" for other lines.
+func lineColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+ "):
+		return ansiGreen
+	case strings.HasPrefix(line, "- "), strings.HasPrefix(line, exposureWarning+" "):
+		return ansiRed
+	case strings.HasPrefix(line, "~ "), strings.HasPrefix(line, "! "):
+		return ansiYellow
+	case strings.HasPrefix(line, importMarker+" "):
+		return ansiCyan
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,159 @@
+package comparison
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorizeDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "change markers",
+			text:     "+ aws_s3_bucket.new\n- aws_s3_bucket.old\n",
+			expected: ansiGreen + "+ aws_s3_bucket.new" + ansiReset + "\n" + ansiRed + "- aws_s3_bucket.old" + ansiReset + "\n",
+		},
+		{
+			name:     "indented attribute change",
+			text:     "aws_instance.web\n  ~ ami: a => b\n",
+			expected: "aws_instance.web\n  " + ansiYellow + "~ ami: a => b" + ansiReset + "\n",
+		},
+		{
+			name:     "import",
+			text:     "± aws_s3_bucket.logs (import)\n",
+			expected: ansiCyan + "± aws_s3_bucket.logs (import)" + ansiReset + "\n",
+		},
+		{
+			name:     "section underline is not a removal",
+			text:     "Resources:\n-----------\n",
+			expected: "Resources:\n-----------\n",
+		},
+		{
+			name:     "last line without newline",
+			text:     "! aws_s3_bucket.logs (mode: managed => data)",
+			expected: ansiYellow + "! aws_s3_bucket.logs (mode: managed => data)" + ansiReset,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, colorizeDiff(tc.text))
+		})
+	}
+}
+
+func TestComparePlansAndGenerateDiffWithOptions_Output(t *testing.T) {
+	origJSON := `{"variables": {"stage": {"value": "dev"}}}`
+	newJSON := `{"variables": {"stage": {"value": "prod"}}}`
+
+	tests := []struct {
+		name        string
+		opts        CompareOptions
+		terminal    bool
+		newPlan     string
+		contains    []string
+		notContains []string
+		empty       bool
+	}{
+		{
+			name:        "writer receives the diff",
+			newPlan:     newJSON,
+			contains:    []string{"Diff Output", "~ stage: dev => prod"},
+			notContains: []string{ansiReset},
+		},
+		{
+			name:     "identical plans",
+			newPlan:  origJSON,
+			contains: []string{"The planfiles are identical"},
+		},
+		{
+			name:        "color enabled",
+			opts:        CompareOptions{ColorEnabled: true},
+			terminal:    true,
+			newPlan:     newJSON,
+			contains:    []string{ansiYellow + "~ stage: dev => prod" + ansiReset, "\nDiff Output\n===========\n"},
+			notContains: []string{ansiYellow + "Variables"},
+		},
+		{
+			name:        "color enabled without a terminal",
+			opts:        CompareOptions{ColorEnabled: true},
+			newPlan:     newJSON,
+			contains:    []string{"~ stage: dev => prod"},
+			notContains: []string{ansiReset},
+		},
+		{
+			name:        "plain turns color off",
+			opts:        CompareOptions{ColorEnabled: true, Plain: true},
+			terminal:    true,
+			newPlan:     newJSON,
+			contains:    []string{"~ stage: dev => prod"},
+			notContains: []string{ansiReset},
+		},
+		{
+			name:    "silent",
+			opts:    CompareOptions{Silent: true},
+			newPlan: newJSON,
+			empty:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stubTerminal(t, tc.terminal)
+
+			var out bytes.Buffer
+			opts := tc.opts
+			opts.Writer = &out
+
+			text, diffMap, _, err := ComparePlansAndGenerateDiffWithOptions(origJSON, tc.newPlan, &opts)
+			require.NoError(t, err)
+			assert.NotContains(t, text, ansiReset)
+
+			encoded, err := MarshalDiffJSON(diffMap)
+			require.NoError(t, err)
+			assert.NotContains(t, string(encoded), `\u001b`)
+
+			if tc.empty {
+				assert.Empty(t, out.String())
+			}
+			for _, expected := range tc.contains {
+				assert.Contains(t, out.String(), expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, out.String(), notExpected)
+			}
+		})
+	}
+}
+
+func TestComparePlansAndGenerateDiffWithOptions_PlainIgnoresTerminal(t *testing.T) {
+	origJSON := `{"variables": {"stage": {"value": "dev"}}, "resource_changes": [{"address": "aws_s3_bucket.a", "change": {"after": {"bucket": "a"}}}]}`
+	newJSON := `{"variables": {"stage": {"value": "prod"}}, "resource_changes": [{"address": "aws_s3_bucket.b", "change": {"after": {"bucket": "b"}}}]}`
+
+	render := func(terminal bool) string {
+		stubTerminal(t, terminal)
+
+		var out bytes.Buffer
+		_, _, _, err := ComparePlansAndGenerateDiffWithOptions(origJSON, newJSON, &CompareOptions{Plain: true, ColorEnabled: true, Writer: &out})
+		require.NoError(t, err)
+		return out.String()
+	}
+
+	assert.Equal(t, render(false), render(true))
+}
+
+// stubTerminal makes every writer look like a terminal, or not, for the duration of the test.
+func stubTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+
+	original := isTerminal
+	isTerminal = func(io.Writer) bool { return terminal }
+	t.Cleanup(func() { isTerminal = original })
+}
@@ -0,0 +1,28 @@
+// Package comparisontest provides test assertions for Terraform plan comparisons.
+package comparisontest
+
+import (
+	"testing"
+
+	comparison "github.com/brakf/tf-compare-plans"
+)
+
+// AssertNoDiff fails the test with the rendered diff when the two plan JSON documents differ.
+// The options apply as for any comparison, so normalization options can hide expected noise.
+func AssertNoDiff(t testing.TB, origPlanJSON, newPlanJSON string, opts ...comparison.Option) bool {
+	t.Helper()
+
+	// A comparer without cache capacity compares directly with the given options
+	result, err := comparison.NewCachingComparer(0, opts...).Compare(origPlanJSON, newPlanJSON)
+	if err != nil {
+		t.Fatalf("error comparing plans: %v", err)
+		return false
+	}
+
+	if result.HasDiff {
+		t.Errorf("plans differ:\n%s", result.Text)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,68 @@
+package comparisontest
+
+import (
+	"fmt"
+	"testing"
+
+	comparison "github.com/brakf/tf-compare-plans"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTB records failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func plan(instanceType, arn string) string {
+	return `{"resource_changes": [{
+		"address": "aws_instance.web",
+		"provider_name": "registry.terraform.io/hashicorp/aws",
+		"change": {"after": {"instance_type": "` + instanceType + `", "arn": "` + arn + `"}}
+	}]}`
+}
+
+func TestAssertNoDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		newPlan  string
+		opts     []comparison.Option
+		pass     bool
+		contains string
+	}{
+		{name: "identical plans", newPlan: plan("t3.micro", "arn:1"), pass: true},
+		{name: "differing plans", newPlan: plan("t3.large", "arn:1"), contains: "~ instance_type: t3.micro => t3.large"},
+		{
+			name:    "normalization options are honored",
+			newPlan: plan("t3.micro", "arn:2"),
+			opts:    []comparison.Option{comparison.WithCompareOptions(comparison.CompareOptions{SuppressKnownProviderNoise: true})},
+			pass:    true,
+		},
+		{name: "invalid plan", newPlan: "not json", contains: "error comparing plans"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &recordingTB{TB: t}
+
+			assert.Equal(t, tc.pass, AssertNoDiff(recorder, plan("t3.micro", "arn:1"), tc.newPlan, tc.opts...))
+			if tc.pass {
+				assert.Empty(t, recorder.failures)
+				return
+			}
+			if assert.Len(t, recorder.failures, 1) {
+				assert.Contains(t, recorder.failures[0], tc.contains)
+			}
+		})
+	}
+}
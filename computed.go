@@ -0,0 +1,142 @@
+package comparison
+
+// getDeclaredAttributes returns the attributes set in the configuration block of the given plans,
+// keyed by configuration address.
+func getDeclaredAttributes(plans ...map[string]interface{}) map[string]map[string]bool {
+	result := make(map[string]map[string]bool)
+
+	for _, plan := range plans {
+		configuration, ok := plan["configuration"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		rootModule, ok := configuration["root_module"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		collectDeclaredAttributes(rootModule, "", result)
+	}
+
+	return result
+}
+
+// collectDeclaredAttributes records the declared attributes of a configuration module and its module calls.
+func collectDeclaredAttributes(module map[string]interface{}, prefix string, result map[string]map[string]bool) {
+	if resources, ok := module["resources"].([]interface{}); ok {
+		for _, res := range resources {
+			resMap, ok := res.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			address, ok := resMap["address"].(string)
+			if !ok {
+				continue
+			}
+
+			expressions, ok := resMap["expressions"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			declared, exists := result[prefix+address]
+			if !exists {
+				declared = make(map[string]bool)
+				result[prefix+address] = declared
+			}
+			for attrK := range expressions {
+				declared[attrK] = true
+			}
+		}
+	}
+
+	if moduleCalls, ok := module["module_calls"].(map[string]interface{}); ok {
+		for name, call := range moduleCalls {
+			callMap, ok := call.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if child, ok := callMap["module"].(map[string]interface{}); ok {
+				collectDeclaredAttributes(child, prefix+"module."+name+".", result)
+			}
+		}
+	}
+}
+
+// dropComputedAttributes returns copies of the resources whose attribute fields only hold declared attributes.
+func dropComputedAttributes(resources map[string]interface{}, declared map[string]map[string]bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(resources))
+
+	for address, resource := range resources {
+		resMap, ok := resource.(map[string]interface{})
+		if !ok {
+			result[address] = resource
+			continue
+		}
+
+		keep := declared[configAddress(address)]
+		unknown := afterUnknownAttributes(resMap)
+
+		filtered := copyEntry(resMap)
+		if values, ok := resMap["values"].(map[string]interface{}); ok {
+			filtered["values"] = filterComputedAttributes(values, keep, unknown)
+		}
+
+		if change, ok := resMap["change"].(map[string]interface{}); ok {
+			// Unknown attributes are dropped, so they must not be marked as unknown either
+			filteredChange := copyEntry(change)
+			delete(filteredChange, "after_unknown")
+			for _, field := range []string{"before", "after"} {
+				if attrs, ok := change[field].(map[string]interface{}); ok {
+					filteredChange[field] = filterComputedAttributes(attrs, keep, unknown)
+				}
+			}
+			filtered["change"] = filteredChange
+		}
+
+		result[address] = filtered
+	}
+
+	return result
+}
+
+// afterUnknownAttributes returns the top-level attributes that are entirely unknown until apply.
+func afterUnknownAttributes(resMap map[string]interface{}) map[string]bool {
+	result := make(map[string]bool)
+
+	change, ok := resMap["change"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	afterUnknown, ok := change["after_unknown"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	for attrK, v := range afterUnknown {
+		if unknown, ok := v.(bool); ok && unknown {
+			result[attrK] = true
+		}
+	}
+
+	return result
+}
+
+// filterComputedAttributes keeps the declared attributes that are not unknown. A nil keep set keeps
+// every attribute that is not unknown.
+func filterComputedAttributes(attrs map[string]interface{}, keep, unknown map[string]bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(attrs))
+
+	for attrK, v := range attrs {
+		if unknown[attrK] || keep != nil && !keep[attrK] {
+			continue
+		}
+		result[attrK] = v
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func computedPlan(instanceType, privateIP string) map[string]interface{} {
+	return map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "aws_instance.web",
+				"change": map[string]interface{}{
+					"after": map[string]interface{}{
+						"instance_type": instanceType,
+						"private_ip":    privateIP,
+					},
+					"after_unknown": map[string]interface{}{"arn": true},
+				},
+			},
+		},
+		"configuration": map[string]interface{}{
+			"root_module": map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{
+						"address": "aws_instance.web",
+						"expressions": map[string]interface{}{
+							"instance_type": map[string]interface{}{"constant_value": instanceType},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestIgnoreComputed(t *testing.T) {
+	opts := &CompareOptions{IgnoreComputed: true}
+
+	// A computed attribute difference is ignored
+	diff, _, hasDiff := compareResourceSections(computedPlan("t3.micro", "10.0.0.1"), computedPlan("t3.micro", "10.0.0.2"), opts)
+	assert.False(t, hasDiff)
+	assert.Empty(t, diff)
+
+	// A declared attribute difference is reported without the computed one
+	diff, _, hasDiff = compareResourceSections(computedPlan("t3.micro", "10.0.0.1"), computedPlan("t3.large", "10.0.0.2"), opts)
+	assert.True(t, hasDiff)
+	assert.Contains(t, diff, "~ instance_type: t3.micro => t3.large")
+	assert.NotContains(t, diff, "private_ip")
+
+	// Without the option both are reported
+	diff, _, _ = compareResourceSections(computedPlan("t3.micro", "10.0.0.1"), computedPlan("t3.large", "10.0.0.2"), &CompareOptions{})
+	assert.Contains(t, diff, "private_ip")
+}
+
+func TestFilterComputedAttributes(t *testing.T) {
+	attrs := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+
+	assert.Equal(t, map[string]interface{}{"a": 1},
+		filterComputedAttributes(attrs, map[string]bool{"a": true, "b": true}, map[string]bool{"b": true}))
+	assert.Equal(t, map[string]interface{}{"a": 1, "c": 3},
+		filterComputedAttributes(attrs, nil, map[string]bool{"b": true}))
+}
@@ -8,6 +8,15 @@ const (
 	// HalfStringDisplayLength is half of the max string length used for truncation.
 	halfStringDisplayLength = 40
 
+	// SensitiveValueText replaces sensitive values in the text diff and the diff map.
+	sensitiveValueText = "(sensitive value)"
+
+	// SensitiveHashLength is the number of hex characters of the hash shown for sensitive values.
+	sensitiveHashLength = 12
+
+	// KnownAfterApplyText marks attribute values that are unknown until apply.
+	knownAfterApplyText = "(known after apply)"
+
 	// NoChangesText is the text used to represent that no changes were found in a diff.
 	noChangesText = "(no changes)"
 
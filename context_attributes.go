@@ -0,0 +1,27 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextAttributes returns the attributes shown as context for changed resources.
+func (o *CompareOptions) contextAttributes() []string {
+	if o.ContextAttributes == nil {
+		return priorityAttrs
+	}
+	return o.ContextAttributes
+}
+
+// writeContextAttributes writes the unchanged context attributes of a changed resource without a change
+// marker, so they identify the resource without reading as changes.
+func writeContextAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, opts *CompareOptions) {
+	for _, attrK := range opts.contextAttributes() {
+		origAttrV, origExists := origAttrs[attrK]
+		newAttrV, newExists := newAttrs[attrK]
+		if !origExists || !newExists || !valuesEqual(origAttrV, newAttrV, opts) {
+			continue
+		}
+		diff.WriteString(fmt.Sprintf("    %s: %v\n", attrK, formatValue(newAttrV, opts)))
+	}
+}
@@ -0,0 +1,57 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowContextAttributes(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_iam_role.app": map[string]interface{}{"values": map[string]interface{}{
+			"id": "app", "name": "app-role", "arn": "arn:aws:iam::1:role/app", "max_session_duration": 3600.0, "path": "/",
+		}},
+	}
+	newRes := map[string]interface{}{
+		"aws_iam_role.app": map[string]interface{}{"values": map[string]interface{}{
+			"id": "app", "name": "app-role", "arn": "arn:aws:iam::1:role/app", "max_session_duration": 7200.0, "path": "/",
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     *CompareOptions
+		expected string
+	}{
+		{
+			name:     "disabled",
+			opts:     &CompareOptions{},
+			expected: "aws_iam_role.app\n  ~ max_session_duration: 3600 => 7200\n",
+		},
+		{
+			name:     "default attributes",
+			opts:     &CompareOptions{ShowContextAttributes: true},
+			expected: "aws_iam_role.app\n    id: app\n  ~ max_session_duration: 3600 => 7200\n",
+		},
+		{
+			name:     "configured attributes",
+			opts:     &CompareOptions{ShowContextAttributes: true, ContextAttributes: []string{"name", "arn", "missing"}},
+			expected: "aws_iam_role.app\n    name: app-role\n    arn: arn:aws:iam::1:role/app\n  ~ max_session_duration: 3600 => 7200\n",
+		},
+		{
+			name:     "changed attributes are not context",
+			opts:     &CompareOptions{ShowContextAttributes: true, ContextAttributes: []string{"max_session_duration", "path"}},
+			expected: "aws_iam_role.app\n    path: /\n  ~ max_session_duration: 3600 => 7200\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, diffMap := compareResources(origRes, newRes, tc.opts)
+			assert.Equal(t, tc.expected, diff)
+			assert.Equal(t, []ExpectedChange{
+				{Section: "resources", Kind: "changed", Address: "aws_iam_role.app", Attribute: "max_session_duration"},
+			}, actualChanges(map[string]interface{}{"resources": diffMap}))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CostEstimator returns the estimated monthly cost delta of a resource change. before is nil for added
+// resources and after is nil for removed ones. It reports false when it cannot price the resource type.
+type CostEstimator func(resType string, before, after map[string]interface{}) (delta float64, ok bool)
+
+// annotateCostDeltas attaches a cost_delta to every added, removed, moved and changed resource entry the
+// estimator can price, and returns the total. Resource maps are keyed like the diff entries' addresses.
+func annotateCostDeltas(diffMap, origResources, newResources map[string]interface{}, opts *CompareOptions) float64 {
+	total := 0.0
+
+	estimate := func(entry map[string]interface{}, origAddress, newAddress string) {
+		var before, after map[string]interface{}
+		var resource interface{}
+		address := newAddress
+		if origAddress != "" {
+			address = origAddress
+		}
+		if origResource, ok := origResources[origAddress]; ok {
+			before = getResourceAttributes(origResource, opts)
+			resource = origResource
+		}
+		if newResource, ok := newResources[newAddress]; ok {
+			after = getResourceAttributes(newResource, opts)
+			resource, address = newResource, newAddress
+		}
+
+		delta, ok := opts.CostEstimator(resourceType(address, resource), before, after)
+		if !ok {
+			return
+		}
+		entry["cost_delta"] = delta
+		total += delta
+	}
+
+	for _, entry := range entryList(diffMap["added"]) {
+		estimate(entry, "", entry["address"].(string))
+	}
+	for _, entry := range entryList(diffMap["removed"]) {
+		estimate(entry, entry["address"].(string), "")
+	}
+	for _, entry := range entryList(diffMap["moved"]) {
+		estimate(entry, entry["old_address"].(string), entry["new_address"].(string))
+	}
+	for _, entry := range entryList(diffMap["changed"]) {
+		estimate(entry, entry["address"].(string), entry["address"].(string))
+	}
+
+	return total
+}
+
+// resourceType returns the type of a resource, falling back to parsing it from the address.
+func resourceType(address string, resource interface{}) string {
+	if resMap, ok := resource.(map[string]interface{}); ok {
+		if resType, ok := resMap["type"].(string); ok {
+			return resType
+		}
+	}
+
+	address = strings.TrimPrefix(stripModulePath(address), "data.")
+	if idx := strings.Index(address, "."); idx != -1 {
+		return address[:idx]
+	}
+	return address
+}
+
+// formatCostDelta formats a cost delta with an explicit sign.
+func formatCostDelta(delta float64) string {
+	return fmt.Sprintf("%+.2f", delta)
+}
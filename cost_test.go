@@ -0,0 +1,69 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEstimator prices aws_instance by instance type and cannot price anything else.
+func stubEstimator(resType string, before, after map[string]interface{}) (float64, bool) {
+	if resType != "aws_instance" {
+		return 0, false
+	}
+
+	prices := map[interface{}]float64{"t3.micro": 10, "t3.large": 60}
+	return prices[after["instance_type"]] - prices[before["instance_type"]], true
+}
+
+func TestCostEstimator(t *testing.T) {
+	orig := `{"resource_changes": [
+		{"address": "aws_instance.web", "type": "aws_instance", "change": {"after": {"instance_type": "t3.micro"}}},
+		{"address": "aws_instance.old", "type": "aws_instance", "change": {"after": {"instance_type": "t3.micro"}}},
+		{"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "change": {"after": {"bucket": "a"}}}
+	]}`
+	updated := `{"resource_changes": [
+		{"address": "aws_instance.web", "type": "aws_instance", "change": {"after": {"instance_type": "t3.large"}}},
+		{"address": "module.app.aws_instance.new", "change": {"after": {"instance_type": "t3.large"}}},
+		{"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "change": {"after": {"bucket": "b"}}}
+	]}`
+
+	result, err := ComparePlans(orig, updated, &CompareOptions{CostEstimator: stubEstimator})
+	require.NoError(t, err)
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	costs := make(map[string]interface{})
+	for _, kind := range []string{"added", "removed", "changed"} {
+		for _, entry := range entryList(resources[kind]) {
+			costs[entry["address"].(string)] = entry["cost_delta"]
+		}
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"aws_instance.web":            50.0,
+		"aws_instance.old":            -10.0,
+		"module.app.aws_instance.new": 60.0,
+		"aws_s3_bucket.logs":          nil,
+	}, costs)
+	assert.Equal(t, 100.0, resources["cost_delta"])
+	assert.Contains(t, result.Text, "Estimated monthly cost delta: +100.00")
+}
+
+func TestResourceType(t *testing.T) {
+	tests := []struct {
+		address  string
+		resource interface{}
+		expected string
+	}{
+		{address: "aws_instance.web", resource: map[string]interface{}{"type": "aws_spot_instance"}, expected: "aws_spot_instance"},
+		{address: `module.app["x"].aws_instance.web[0]`, expected: "aws_instance"},
+		{address: "data.aws_ami.ubuntu", expected: "aws_ami"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.address, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resourceType(tc.address, tc.resource))
+		})
+	}
+}
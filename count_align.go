@@ -0,0 +1,193 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// countIndexPattern matches an address ending in a count index, e.g. `aws_instance.web[3]`.
+var countIndexPattern = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+
+// countSimilarityThreshold is the share of equal attributes above which two unaligned count
+// instances are paired as the same instance with changes.
+const countSimilarityThreshold = 0.5
+
+// countAlignment is the result of aligning count instances by content instead of index.
+type countAlignment struct {
+	// origResources and newResources hold the remaining resources, with aligned original instances
+	// rekeyed to the address of their new counterpart. Unaligned instances are removed.
+	origResources map[string]interface{}
+	newResources  map[string]interface{}
+
+	// added and removed list the unaligned instance addresses, sorted.
+	added   []string
+	removed []string
+}
+
+// alignCountInstances aligns count instances of the same resource by content, so an element inserted
+// into or removed from the middle of a count list shows up as a single addition or removal instead of
+// every following index shifting. Exactly equal instances are aligned first in longest-common-subsequence
+// order, then the instances left between them are paired in order when they are similar enough.
+func alignCountInstances(origResources, newResources map[string]interface{}, opts *CompareOptions) countAlignment {
+	alignment := countAlignment{
+		origResources: copyEntry(origResources),
+		newResources:  copyEntry(newResources),
+		added:         make([]string, 0),
+		removed:       make([]string, 0),
+	}
+
+	origFamilies, newFamilies := countFamilies(origResources), countFamilies(newResources)
+	for base, origAddrs := range origFamilies {
+		newAddrs, ok := newFamilies[base]
+		if !ok {
+			continue
+		}
+
+		origAttrs := familyAttributes(origAddrs, origResources, opts)
+		newAttrs := familyAttributes(newAddrs, newResources, opts)
+
+		pairs := alignFamily(origAttrs, newAttrs)
+		pairedOrig := make(map[int]bool, len(pairs))
+		pairedNew := make(map[int]bool, len(pairs))
+		for _, pair := range pairs {
+			pairedOrig[pair[0]], pairedNew[pair[1]] = true, true
+		}
+
+		// Rekey every original instance of the family and drop the unaligned ones
+		for _, address := range origAddrs {
+			delete(alignment.origResources, address)
+		}
+		for _, pair := range pairs {
+			alignment.origResources[newAddrs[pair[1]]] = origResources[origAddrs[pair[0]]]
+		}
+
+		for i, address := range origAddrs {
+			if !pairedOrig[i] {
+				alignment.removed = append(alignment.removed, address)
+			}
+		}
+		for j, address := range newAddrs {
+			if !pairedNew[j] {
+				delete(alignment.newResources, address)
+				alignment.added = append(alignment.added, address)
+			}
+		}
+	}
+
+	sort.Strings(alignment.added)
+	sort.Strings(alignment.removed)
+	return alignment
+}
+
+// countFamilies groups count instance addresses by their base address, each family sorted by index.
+func countFamilies(resources map[string]interface{}) map[string][]string {
+	families := make(map[string][]string)
+	indexes := make(map[string]int, len(resources))
+
+	for address := range resources {
+		match := countIndexPattern.FindStringSubmatch(address)
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[2])
+		indexes[address] = index
+		families[match[1]] = append(families[match[1]], address)
+	}
+
+	for _, addresses := range families {
+		sort.Slice(addresses, func(i, j int) bool { return indexes[addresses[i]] < indexes[addresses[j]] })
+	}
+
+	return families
+}
+
+// familyAttributes returns the attributes of each address in order.
+func familyAttributes(addresses []string, resources map[string]interface{}, opts *CompareOptions) []map[string]interface{} {
+	attrs := make([]map[string]interface{}, len(addresses))
+	for i, address := range addresses {
+		attrs[i] = getResourceAttributes(resources[address], opts)
+	}
+	return attrs
+}
+
+// alignFamily returns the aligned (original, new) index pairs of two instance lists, in order.
+func alignFamily(origAttrs, newAttrs []map[string]interface{}) [][2]int {
+	anchors := equalSubsequence(origAttrs, newAttrs)
+
+	pairs := make([][2]int, 0, len(anchors))
+	prevOrig, prevNew := 0, 0
+	for _, anchor := range append(anchors, [2]int{len(origAttrs), len(newAttrs)}) {
+		// Pair similar instances in the gap before the anchor, keeping their order
+		next := prevNew
+		for i := prevOrig; i < anchor[0]; i++ {
+			for j := next; j < anchor[1]; j++ {
+				if attributeSimilarity(origAttrs[i], newAttrs[j]) >= countSimilarityThreshold {
+					pairs = append(pairs, [2]int{i, j})
+					next = j + 1
+					break
+				}
+			}
+		}
+
+		if anchor[0] < len(origAttrs) {
+			pairs = append(pairs, anchor)
+		}
+		prevOrig, prevNew = anchor[0]+1, anchor[1]+1
+	}
+
+	return pairs
+}
+
+// equalSubsequence returns the index pairs of the longest common subsequence of equal instances.
+func equalSubsequence(origAttrs, newAttrs []map[string]interface{}) [][2]int {
+	return longestCommonSubsequence(len(origAttrs), len(newAttrs), func(i, j int) bool {
+		return reflect.DeepEqual(origAttrs[i], newAttrs[j])
+	})
+}
+
+// attributeSimilarity returns the share of attributes, across both sets, that have equal values.
+func attributeSimilarity(origAttrs, newAttrs map[string]interface{}) float64 {
+	keys := getSortedKeys(origAttrs, newAttrs)
+	if len(keys) == 0 {
+		return 1
+	}
+
+	equal := 0
+	for _, k := range keys {
+		origV, origExists := origAttrs[k]
+		newV, newExists := newAttrs[k]
+		if origExists && newExists && reflect.DeepEqual(origV, newV) {
+			equal++
+		}
+	}
+
+	return float64(equal) / float64(len(keys))
+}
+
+// processAlignedCountChanges writes the count instances that could not be aligned as additions and removals.
+func processAlignedCountChanges(diff *strings.Builder, alignment countAlignment, origResources, newResources map[string]interface{}, opts *CompareOptions) ([]map[string]interface{}, []map[string]interface{}) {
+	added := make([]map[string]interface{}, 0, len(alignment.added))
+	removed := make([]map[string]interface{}, 0, len(alignment.removed))
+
+	for _, k := range alignment.added {
+		diff.WriteString(fmt.Sprintf("+ %s\n", k))
+		added = append(added, map[string]interface{}{
+			"address": k,
+			"value":   redactResource(newResources[k], opts),
+		})
+	}
+
+	for _, k := range alignment.removed {
+		diff.WriteString(fmt.Sprintf("- %s\n", k))
+		removed = append(removed, map[string]interface{}{
+			"address": k,
+			"value":   redactResource(origResources[k], opts),
+		})
+	}
+
+	return added, removed
+}
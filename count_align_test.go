@@ -0,0 +1,89 @@
+package comparison
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countResources builds count instances of aws_instance.web with the given names.
+func countResources(names ...string) map[string]interface{} {
+	resources := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		resources[fmt.Sprintf("aws_instance.web[%d]", i)] = map[string]interface{}{
+			"values": map[string]interface{}{"name": name, "ami": "ami-1", "instance_type": "t3.micro"},
+		}
+	}
+	return resources
+}
+
+func TestAlignCountIndexes(t *testing.T) {
+	tests := []struct {
+		name          string
+		origRes       map[string]interface{}
+		newRes        map[string]interface{}
+		align         bool
+		expectAdded   []string
+		expectRemoved []string
+		expectChanged int
+	}{
+		{
+			name:          "insert at index 0 without alignment shifts every index",
+			origRes:       countResources("a", "b", "c", "d", "e"),
+			newRes:        countResources("x", "a", "b", "c", "d", "e"),
+			expectAdded:   []string{"aws_instance.web[5]"},
+			expectRemoved: []string{},
+			expectChanged: 5,
+		},
+		{
+			name:          "insert at index 0 is a single addition",
+			origRes:       countResources("a", "b", "c", "d", "e"),
+			newRes:        countResources("x", "a", "b", "c", "d", "e"),
+			align:         true,
+			expectAdded:   []string{"aws_instance.web[0]"},
+			expectRemoved: []string{},
+		},
+		{
+			name:          "removal from the middle is a single removal",
+			origRes:       countResources("a", "b", "c"),
+			newRes:        countResources("a", "c"),
+			align:         true,
+			expectAdded:   []string{},
+			expectRemoved: []string{"aws_instance.web[1]"},
+		},
+		{
+			name:          "partially matching instance is paired as a change",
+			origRes:       countResources("a", "b", "c"),
+			newRes:        countResources("x", "a", "b2", "c"),
+			align:         true,
+			expectAdded:   []string{"aws_instance.web[0]"},
+			expectRemoved: []string{},
+			expectChanged: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, diffMap := compareResources(tc.origRes, tc.newRes, &CompareOptions{AlignCountIndexes: tc.align})
+
+			addresses := func(kind string) []string {
+				result := make([]string, 0)
+				for _, entry := range entryList(diffMap[kind]) {
+					result = append(result, entry["address"].(string))
+				}
+				return result
+			}
+			assert.Equal(t, tc.expectAdded, addresses("added"))
+			assert.Equal(t, tc.expectRemoved, addresses("removed"))
+			assert.Len(t, diffMap["changed"], tc.expectChanged)
+		})
+	}
+}
+
+func TestAttributeSimilarity(t *testing.T) {
+	a := map[string]interface{}{"x": 1, "y": 2, "z": 3}
+	b := map[string]interface{}{"x": 1, "y": 2, "z": 4}
+	assert.InDelta(t, 2.0/3.0, attributeSimilarity(a, b), 0.001)
+	assert.Equal(t, 1.0, attributeSimilarity(nil, nil))
+}
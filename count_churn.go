@@ -0,0 +1,133 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// countInstancePattern matches an address ending in a numeric count index, e.g. `aws_instance.web[3]`.
+// for_each keys are not renumbered, so their instances are always compared one by one.
+var countInstancePattern = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+
+// collapseCountChurn summarizes resources whose number of count instances changed while the instances
+// present in both plans are unchanged and every added or removed instance is a copy of one of them, so
+// scaling shows up as a single `~ foo: count 3 => 4 (+1 instance)` line instead of additions or removals.
+// It returns copies of the resources without the collapsed instances and an entry for every collapsed
+// resource. Families whose instances genuinely differ are left to be compared instance by instance.
+func collapseCountChurn(diff *strings.Builder, origResources, newResources map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}, []map[string]interface{}) {
+	collapsed := make([]map[string]interface{}, 0)
+	origFamilies, newFamilies := instanceFamilies(origResources), instanceFamilies(newResources)
+
+	bases := make([]string, 0, len(origFamilies))
+	for base := range origFamilies {
+		if newFamily, ok := newFamilies[base]; ok && len(newFamily) != len(origFamilies[base]) {
+			bases = append(bases, base)
+		}
+	}
+	sort.Strings(bases)
+
+	remainingOrig, remainingNew := origResources, newResources
+	for _, base := range bases {
+		origFamily, newFamily := origFamilies[base], newFamilies[base]
+		if !onlyCountChanged(origFamily, newFamily, origResources, newResources, opts) {
+			continue
+		}
+
+		if len(collapsed) == 0 {
+			remainingOrig, remainingNew = copyEntry(origResources), copyEntry(newResources)
+		}
+		for _, address := range origFamily {
+			delete(remainingOrig, address)
+		}
+		for _, address := range newFamily {
+			delete(remainingNew, address)
+		}
+
+		delta := len(newFamily) - len(origFamily)
+		noun := "instances"
+		if delta == 1 || delta == -1 {
+			noun = "instance"
+		}
+		diff.WriteString(fmt.Sprintf("~ %s: count %s (%+d %s)\n", base,
+			renderTransition(fmt.Sprint(len(origFamily)), fmt.Sprint(len(newFamily)), opts), delta, noun))
+		collapsed = append(collapsed, map[string]interface{}{
+			"address":   base,
+			"old_count": len(origFamily),
+			"new_count": len(newFamily),
+		})
+	}
+
+	return remainingOrig, remainingNew, collapsed
+}
+
+// instanceFamilies groups count instance addresses by their base address, each family keyed by index.
+func instanceFamilies(resources map[string]interface{}) map[string]map[int]string {
+	families := make(map[string]map[int]string)
+	for address := range resources {
+		match := countInstancePattern.FindStringSubmatch(address)
+		if match == nil {
+			continue
+		}
+		index, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		if families[match[1]] == nil {
+			families[match[1]] = make(map[int]string)
+		}
+		families[match[1]][index] = address
+	}
+	return families
+}
+
+// onlyCountChanged reports whether two instance families differ only in their number of instances: every
+// index present in both plans has the same attributes on both sides, and every instance present in only
+// one plan has the same attributes as one of those surviving instances.
+func onlyCountChanged(origFamily, newFamily map[int]string, origResources, newResources map[string]interface{}, opts *CompareOptions) bool {
+	surviving := make([]map[string]interface{}, 0)
+	for index, origAddress := range origFamily {
+		newAddress, ok := newFamily[index]
+		if !ok {
+			continue
+		}
+
+		attrs := getComparedAttributes(origResources[origAddress], opts)
+		if !reflect.DeepEqual(attrs, getComparedAttributes(newResources[newAddress], opts)) {
+			return false
+		}
+		surviving = append(surviving, attrs)
+	}
+	if len(surviving) == 0 {
+		return false
+	}
+
+	return extraInstancesRepeat(origFamily, newFamily, origResources, surviving, opts) &&
+		extraInstancesRepeat(newFamily, origFamily, newResources, surviving, opts)
+}
+
+// extraInstancesRepeat reports whether every instance of family missing from other has the same
+// attributes as one of the surviving instances.
+func extraInstancesRepeat(family, other map[int]string, resources map[string]interface{}, surviving []map[string]interface{}, opts *CompareOptions) bool {
+	for index, address := range family {
+		if _, ok := other[index]; ok {
+			continue
+		}
+
+		attrs := getComparedAttributes(resources[address], opts)
+		found := false
+		for _, s := range surviving {
+			if reflect.DeepEqual(attrs, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseCountChurn(t *testing.T) {
+	instance := func(ami string) map[string]interface{} {
+		return map[string]interface{}{"values": map[string]interface{}{"ami": ami, "instance_type": "t3.micro"}}
+	}
+
+	tests := []struct {
+		name     string
+		origRes  map[string]interface{}
+		newRes   map[string]interface{}
+		expected string
+		changes  []ExpectedChange
+	}{
+		{
+			name:     "count increased",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-1"), "aws_instance.web[2]": instance("ami-1")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-1"), "aws_instance.web[2]": instance("ami-1"), "aws_instance.web[3]": instance("ami-1")},
+			expected: "~ aws_instance.web: count 3 => 4 (+1 instance)\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "count_changed", Address: "aws_instance.web"}},
+		},
+		{
+			name:     "renumbered instances are compared by instance",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2"), "aws_instance.web[2]": instance("ami-1")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-2"), "aws_instance.web[1]": instance("ami-1")},
+			expected: "- aws_instance.web[2]\naws_instance.web[0]\n  ~ ami: ami-1 => ami-2\naws_instance.web[1]\n  ~ ami: ami-2 => ami-1\n",
+			changes: []ExpectedChange{
+				{Section: "resources", Kind: "removed", Address: "aws_instance.web[2]"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web[0]", Attribute: "ami"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web[1]", Attribute: "ami"},
+			},
+		},
+		{
+			name:     "copies of surviving instances",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2"), "aws_instance.web[2]": instance("ami-2"), "aws_instance.web[3]": instance("ami-1")},
+			expected: "~ aws_instance.web: count 2 => 4 (+2 instances)\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "count_changed", Address: "aws_instance.web"}},
+		},
+		{
+			name:     "changed multiplicity is compared by instance",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-1"), "aws_instance.web[2]": instance("ami-2")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2"), "aws_instance.web[2]": instance("ami-2"), "aws_instance.web[3]": instance("ami-2")},
+			expected: "+ aws_instance.web[3]\naws_instance.web[1]\n  ~ ami: ami-1 => ami-2\n",
+			changes: []ExpectedChange{
+				{Section: "resources", Kind: "added", Address: "aws_instance.web[3]"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web[1]", Attribute: "ami"},
+			},
+		},
+		{
+			name:     "several instances removed",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-1"), "aws_instance.web[2]": instance("ami-1")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1")},
+			expected: "~ aws_instance.web: count 3 => 1 (-2 instances)\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "count_changed", Address: "aws_instance.web"}},
+		},
+		{
+			name:     "for_each keys are compared by instance",
+			origRes:  map[string]interface{}{`aws_instance.web["a"]`: instance("ami-1")},
+			newRes:   map[string]interface{}{`aws_instance.web["a"]`: instance("ami-1"), `aws_instance.web["b"]`: instance("ami-1")},
+			expected: "+ aws_instance.web[\"b\"]\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "added", Address: `aws_instance.web["b"]`}},
+		},
+		{
+			name:     "different contents fall back to instances",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2")},
+			expected: "+ aws_instance.web[1]\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "added", Address: "aws_instance.web[1]"}},
+		},
+		{
+			name:     "unchanged count is compared by instance",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": instance("ami-1"), "aws_instance.web[1]": instance("ami-2")},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-2"), "aws_instance.web[1]": instance("ami-1")},
+			expected: "aws_instance.web[0]\n  ~ ami: ami-1 => ami-2\naws_instance.web[1]\n  ~ ami: ami-2 => ami-1\n",
+			changes: []ExpectedChange{
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web[0]", Attribute: "ami"},
+				{Section: "resources", Kind: "changed", Address: "aws_instance.web[1]", Attribute: "ami"},
+			},
+		},
+		{
+			name:     "new family is an addition",
+			origRes:  map[string]interface{}{},
+			newRes:   map[string]interface{}{"aws_instance.web[0]": instance("ami-1")},
+			expected: "+ aws_instance.web[0]\n",
+			changes:  []ExpectedChange{{Section: "resources", Kind: "added", Address: "aws_instance.web[0]"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, diffMap := compareResources(tc.origRes, tc.newRes, &CompareOptions{CollapseCountChurn: true})
+			assert.Equal(t, tc.expected, diff)
+			assert.Equal(t, tc.changes, actualChanges(map[string]interface{}{"resources": diffMap}))
+		})
+	}
+}
+
+func TestComparePlans_CollapseCountChurn(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web[0]", "change": {"after": {"ami": "ami-1"}}},
+		{"address": "aws_instance.web[1]", "change": {"after": {"ami": "ami-1"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web[0]", "change": {"after": {"ami": "ami-1"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{CollapseCountChurn: true})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "Resources: +0 -0 ~1")
+	assert.Contains(t, result.Text, "~ aws_instance.web: count 2 => 1 (-1 instance)\n")
+	assert.NotContains(t, result.Text, "- aws_instance.web[1]")
+
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, result.Text, "- aws_instance.web[1]\n")
+}
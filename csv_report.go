@@ -0,0 +1,158 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// csvHeader names the columns of the CSV export.
+var csvHeader = []string{"section", "change_type", "address", "attribute", "old_value", "new_value"}
+
+// csvPairedFields are the old and new fields of diff map entries that change a single property of a
+// resource or provider, with the name the CSV export lists them under.
+var csvPairedFields = []struct {
+	attribute, old, new string
+}{
+	{"address", "old_address", "new_address"},
+	{"count", "old_count", "new_count"},
+	{"mode", "old_mode", "new_mode"},
+	{"sensitive", "old_sensitive", "new_sensitive"},
+	{"version", "old_version", "new_version"},
+}
+
+// resourceSections are the diff map sections whose added and removed entries hold whole resources.
+var resourceSections = stringSet([]string{"resources", "tag_changes", "drift"})
+
+// RenderCSV renders the changes of a diff map as CSV for spreadsheets, with the columns section,
+// change_type, address, attribute, old_value and new_value, in the order of the text diff. A changed
+// resource has a row for every changed attribute, an added or removed resource a single row with its
+// attributes. Strings are written as they are and other values JSON-encoded; fields are quoted as RFC 4180
+// requires when they contain commas, quotes or line breaks.
+func RenderCSV(diffMap map[string]interface{}) ([]byte, error) {
+	opts := resolveOptions(nil)
+	rows := [][]string{csvHeader}
+
+	var err error
+	visitChanges(diffMap, func(section, changeType string, detail map[string]interface{}) {
+		if err != nil {
+			return
+		}
+		var entryRows [][]string
+		entryRows, err = csvEntryRows(section, changeType, detail, opts)
+		rows = append(rows, entryRows...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, errors.Wrap(err, "error writing CSV")
+	}
+	return buf.Bytes(), nil
+}
+
+// csvChange is a single row of the CSV export, before its values are formatted.
+type csvChange struct {
+	attribute string
+	old, new  interface{}
+}
+
+// csvEntryRows returns the CSV rows of a diff map entry.
+func csvEntryRows(section, changeType string, entry map[string]interface{}, opts *CompareOptions) ([][]string, error) {
+	changes := make([]csvChange, 0)
+	for _, field := range csvPairedFields {
+		if _, ok := entry[field.old]; ok {
+			changes = append(changes, csvChange{field.attribute, entry[field.old], entry[field.new]})
+		}
+	}
+
+	if attrs, ok := entry["attributes"].(map[string]interface{}); ok {
+		for _, attr := range entryList(attrs["added"]) {
+			changes = append(changes, csvChange{entryKey(attr), nil, attr["value"]})
+		}
+		for _, attr := range entryList(attrs["removed"]) {
+			changes = append(changes, csvChange{entryKey(attr), attr["value"], nil})
+		}
+		for _, attr := range entryList(attrs["changed"]) {
+			changes = append(changes, csvChange{entryKey(attr), attr["old"], attr["new"]})
+		}
+	}
+
+	if value, ok := entry["value"]; ok {
+		if resourceSections[section] {
+			value = getResourceAttributes(value, opts)
+		}
+		if changeType == "removed" {
+			changes = append(changes, csvChange{old: value})
+		} else {
+			changes = append(changes, csvChange{new: value})
+		}
+	}
+
+	// Variables and outputs keep their values on the entry, and full resources recorded next to the
+	// attribute changes are left out
+	if _, ok := entry["old"]; ok && len(changes) == 0 {
+		changes = append(changes, csvChange{old: entry["old"], new: entry["new"]})
+	}
+
+	// Outputs are recorded with their sensitivity, which has a change type of its own
+	if section == "outputs" {
+		for i := range changes {
+			changes[i].old, changes[i].new = csvOutputValue(changes[i].old), csvOutputValue(changes[i].new)
+		}
+	}
+
+	// Entries without any values are still a change
+	if len(changes) == 0 {
+		changes = append(changes, csvChange{})
+	}
+
+	address := entryKey(entry)
+	rows := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		oldCell, err := csvCell(change.old)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error encoding %s change of %s", section, address)
+		}
+		newCell, err := csvCell(change.new)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error encoding %s change of %s", section, address)
+		}
+		rows = append(rows, []string{section, changeType, address, change.attribute, oldCell, newCell})
+	}
+	return rows, nil
+}
+
+// csvCell formats a value for a CSV cell: strings as they are, nothing for nil and JSON for other values.
+func csvCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
+
+// csvOutputValue returns the value of an output recorded with its sensitivity, like the text diff hiding
+// the values of sensitive outputs. Nil is left as it is.
+func csvOutputValue(output interface{}) interface{} {
+	switch {
+	case output == nil:
+		return nil
+	case isSensitive(output):
+		return sensitiveValueText
+	default:
+		return outputValue(output)
+	}
+}
@@ -0,0 +1,106 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCSV(t *testing.T) {
+	origJSON := `{
+		"variables": {"region": {"value": "eu-west-1"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "tags": {"Name": "web"}}}},
+			{"address": "aws_instance.old", "change": {"after": {"ami": "ami-0"}}}
+		],
+		"planned_values": {"outputs": {"url": {"value": "https://a"}}}
+	}`
+	newJSON := `{
+		"variables": {"region": {"value": "eu-west-2"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "tags": {"Name": "web, \"main\""}}}},
+			{"address": "aws_instance.new", "change": {"after": {"ami": "ami-3"}}}
+		],
+		"planned_values": {"outputs": {"url": {"value": "https://b"}}}
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+
+	rendered, err := RenderCSV(result.Changes)
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), `"{""Name"":""web, \""main\""""}"`)
+
+	records, err := csv.NewReader(bytes.NewReader(rendered)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"section", "change_type", "address", "attribute", "old_value", "new_value"},
+		{"variables", "changed", "region", "", "eu-west-1", "eu-west-2"},
+		{"resources", "added", "aws_instance.new", "", "", `{"ami":"ami-3"}`},
+		{"resources", "removed", "aws_instance.old", "", `{"ami":"ami-0"}`, ""},
+		{"resources", "changed", "aws_instance.web", "ami", "ami-1", "ami-2"},
+		{"resources", "changed", "aws_instance.web", "tags", `{"Name":"web"}`, `{"Name":"web, \"main\""}`},
+		{"outputs", "changed", "url", "", "https://a", "https://b"},
+	}, records)
+}
+
+func TestCSVEntryRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		section    string
+		changeType string
+		entry      map[string]interface{}
+		expected   [][]string
+	}{
+		{
+			name:       "moved resource",
+			section:    "resources",
+			changeType: "moved",
+			entry:      map[string]interface{}{"old_address": "aws_instance.a", "new_address": "aws_instance.b"},
+			expected:   [][]string{{"resources", "moved", "aws_instance.a => aws_instance.b", "address", "aws_instance.a", "aws_instance.b"}},
+		},
+		{
+			name:       "count change",
+			section:    "resources",
+			changeType: "count_changed",
+			entry:      map[string]interface{}{"address": "aws_instance.web", "old_count": 2, "new_count": 3},
+			expected:   [][]string{{"resources", "count_changed", "aws_instance.web", "count", "2", "3"}},
+		},
+		{
+			name:       "provider upgrade",
+			section:    "provider_upgrades",
+			changeType: "upgraded",
+			entry:      map[string]interface{}{"provider": "aws", "old_version": "4.0.0", "new_version": "5.0.0"},
+			expected:   [][]string{{"provider_upgrades", "upgraded", "aws", "version", "4.0.0", "5.0.0"}},
+		},
+		{
+			name:       "sensitive output",
+			section:    "outputs",
+			changeType: "changed",
+			entry: map[string]interface{}{
+				"name": "token",
+				"old":  map[string]interface{}{"value": "t1", "sensitive": true},
+				"new":  map[string]interface{}{"value": "t2", "sensitive": true},
+			},
+			expected: [][]string{{"outputs", "changed", "token", "", "(sensitive value)", "(sensitive value)"}},
+		},
+		{
+			name:       "added variable with a nested value",
+			section:    "variables",
+			changeType: "added",
+			entry:      map[string]interface{}{"name": "zones", "value": []interface{}{"a", "b"}},
+			expected:   [][]string{{"variables", "added", "zones", "", "", `["a","b"]`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := csvEntryRows(tt.section, tt.changeType, tt.entry, &CompareOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rows)
+		})
+	}
+}
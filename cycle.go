@@ -0,0 +1,56 @@
+package comparison
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCyclicValue is returned when a value refers back to itself. Decoded JSON never does, but maps
+// built programmatically can.
+var ErrCyclicValue = errors.New("value contains a reference cycle")
+
+// cycleGuard tracks the maps and slices on the current recursion path, so revisiting one is
+// reported as a cycle while shared references in sibling positions are still allowed.
+type cycleGuard map[cycleKey]bool
+
+// cycleKey identifies a map or slice by its backing storage.
+type cycleKey struct {
+	ptr    uintptr
+	length int
+}
+
+// enter marks a container as being visited, failing if it already is.
+func (g cycleGuard) enter(v interface{}) error {
+	key, ok := containerKey(v)
+	if !ok {
+		return nil
+	}
+
+	if g[key] {
+		return ErrCyclicValue
+	}
+	g[key] = true
+	return nil
+}
+
+// leave unmarks a container once its recursion is done.
+func (g cycleGuard) leave(v interface{}) {
+	if key, ok := containerKey(v); ok {
+		delete(g, key)
+	}
+}
+
+// containerKey returns the key of a non-empty map or slice.
+func containerKey(v interface{}) (cycleKey, bool) {
+	switch v.(type) {
+	case map[string]interface{}:
+		rv := reflect.ValueOf(v)
+		return cycleKey{ptr: rv.Pointer()}, rv.Len() > 0
+	case []interface{}:
+		rv := reflect.ValueOf(v)
+		return cycleKey{ptr: rv.Pointer(), length: rv.Len()}, rv.Len() > 0
+	default:
+		return cycleKey{}, false
+	}
+}
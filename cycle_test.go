@@ -0,0 +1,47 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cyclicMap returns a map that contains itself through a nested list.
+func cyclicMap() map[string]interface{} {
+	m := map[string]interface{}{"name": "loop"}
+	m["children"] = []interface{}{m}
+	return m
+}
+
+func TestSortMapKeys_Cycle(t *testing.T) {
+	_, err := sortMapKeys(cyclicMap())
+	require.ErrorIs(t, err, ErrCyclicValue)
+
+	// The same value in sibling positions is not a cycle
+	shared := map[string]interface{}{"a": 1}
+	sorted, err := sortMapKeys(map[string]interface{}{"x": shared, "y": []interface{}{shared, shared}})
+	require.NoError(t, err)
+	assert.Equal(t, shared, sorted["x"])
+}
+
+func TestComparePlanMaps_Cycle(t *testing.T) {
+	plan := map[string]interface{}{"variables": cyclicMap()}
+
+	_, err := comparePlanMaps(map[string]interface{}{}, plan, &CompareOptions{})
+	require.ErrorIs(t, err, ErrCyclicValue)
+	assert.Contains(t, err.Error(), "new plan")
+}
+
+func TestDeepDiff_Cycle(t *testing.T) {
+	origLoop, newLoop := cyclicMap(), cyclicMap()
+	newLoop["name"] = "other"
+
+	_, err := DeepDiff(origLoop, newLoop)
+	require.ErrorIs(t, err, ErrCyclicValue)
+
+	// A cycle that is never walked into is not an error
+	changes, err := DeepDiff(map[string]interface{}{"children": []interface{}{}}, cyclicMap())
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+}
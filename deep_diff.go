@@ -0,0 +1,151 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeKind describes how a value differs between two documents.
+type ChangeKind string
+
+const (
+	// ChangeAdded marks a value that only exists in the new document.
+	ChangeAdded ChangeKind = "added"
+
+	// ChangeRemoved marks a value that only exists in the old document.
+	ChangeRemoved ChangeKind = "removed"
+
+	// ChangeModified marks a value that exists in both documents with different contents.
+	ChangeModified ChangeKind = "changed"
+)
+
+// Change is a single difference found by DeepDiff.
+type Change struct {
+	// Path locates the value, e.g. "tags.Name" or "ingress[0].from_port". It is empty for the root value.
+	Path string
+	Kind ChangeKind
+	Old  interface{}
+	New  interface{}
+}
+
+// DeepDiff recursively compares two JSON-like values and returns the leaf-level changes between them.
+// Maps are walked in sorted key order and slices by index, so the result is deterministic.
+// It returns ErrCyclicValue when either value refers back to itself.
+func DeepDiff(oldValue, newValue interface{}) ([]Change, error) {
+	changes := make([]Change, 0)
+	if err := deepDiff("", oldValue, newValue, -1, &changes, cycleGuard{}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diffTopLevel compares two maps one level deep, reporting each differing key as a single change.
+func diffTopLevel(origMap, newMap map[string]interface{}) []Change {
+	changes := make([]Change, 0)
+
+	// Values below the first level are compared as a whole, so there is no recursion to guard
+	_ = deepDiff("", origMap, newMap, 1, &changes, cycleGuard{})
+	return changes
+}
+
+// deepDiff appends the changes between two values to changes. A negative depth recurses without limit;
+// once depth reaches zero the remaining values are compared as a whole.
+func deepDiff(path string, oldValue, newValue interface{}, depth int, changes *[]Change, guard cycleGuard) error {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	if depth != 0 {
+		switch oldTyped := oldValue.(type) {
+		case map[string]interface{}:
+			if newTyped, ok := newValue.(map[string]interface{}); ok {
+				return guardedDiff(guard, oldValue, newValue, func() error {
+					return diffMaps(path, oldTyped, newTyped, depth-1, changes, guard)
+				})
+			}
+		case []interface{}:
+			if newTyped, ok := newValue.([]interface{}); ok {
+				return guardedDiff(guard, oldValue, newValue, func() error {
+					return diffSlices(path, oldTyped, newTyped, depth-1, changes, guard)
+				})
+			}
+		}
+	}
+
+	*changes = append(*changes, Change{Path: path, Kind: ChangeModified, Old: oldValue, New: newValue})
+	return nil
+}
+
+// guardedDiff runs diff with both containers marked as visited.
+func guardedDiff(guard cycleGuard, oldValue, newValue interface{}, diff func() error) error {
+	if err := guard.enter(oldValue); err != nil {
+		return err
+	}
+	defer guard.leave(oldValue)
+
+	if err := guard.enter(newValue); err != nil {
+		return err
+	}
+	defer guard.leave(newValue)
+
+	return diff()
+}
+
+// diffMaps appends the changes between two maps, visiting keys in sorted order.
+func diffMaps(path string, oldMap, newMap map[string]interface{}, depth int, changes *[]Change, guard cycleGuard) error {
+	for _, k := range getSortedKeys(oldMap, newMap) {
+		childPath := joinPath(path, k)
+		oldV, oldExists := oldMap[k]
+		newV, newExists := newMap[k]
+
+		switch {
+		case !oldExists:
+			*changes = append(*changes, Change{Path: childPath, Kind: ChangeAdded, New: newV})
+		case !newExists:
+			*changes = append(*changes, Change{Path: childPath, Kind: ChangeRemoved, Old: oldV})
+		default:
+			if err := deepDiff(childPath, oldV, newV, depth, changes, guard); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffSlices appends the changes between two slices, comparing elements by index.
+func diffSlices(path string, oldSlice, newSlice []interface{}, depth int, changes *[]Change, guard cycleGuard) error {
+	for i := 0; i < len(oldSlice) || i < len(newSlice); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+
+		switch {
+		case i >= len(oldSlice):
+			*changes = append(*changes, Change{Path: childPath, Kind: ChangeAdded, New: newSlice[i]})
+		case i >= len(newSlice):
+			*changes = append(*changes, Change{Path: childPath, Kind: ChangeRemoved, Old: oldSlice[i]})
+		default:
+			if err := deepDiff(childPath, oldSlice[i], newSlice[i], depth, changes, guard); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinPath appends a map key to a change path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// changesOfKind returns the changes of the given kind, preserving their order.
+func changesOfKind(changes []Change, kind ChangeKind) []Change {
+	result := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		if c.Kind == kind {
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldValue interface{}
+		newValue interface{}
+		expected []Change
+	}{
+		{
+			name:     "identical values",
+			oldValue: map[string]interface{}{"a": 1, "b": []interface{}{"x"}},
+			newValue: map[string]interface{}{"a": 1, "b": []interface{}{"x"}},
+			expected: []Change{},
+		},
+		{
+			name: "nested map changes",
+			oldValue: map[string]interface{}{
+				"name": "web",
+				"tags": map[string]interface{}{
+					"Environment": "dev",
+					"Owner":       "team-a",
+				},
+			},
+			newValue: map[string]interface{}{
+				"name": "web",
+				"tags": map[string]interface{}{
+					"Environment": "prod",
+					"CostCenter":  "42",
+				},
+			},
+			expected: []Change{
+				{Path: "tags.CostCenter", Kind: ChangeAdded, New: "42"},
+				{Path: "tags.Environment", Kind: ChangeModified, Old: "dev", New: "prod"},
+				{Path: "tags.Owner", Kind: ChangeRemoved, Old: "team-a"},
+			},
+		},
+		{
+			name: "slice element changes",
+			oldValue: map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{"port": 80},
+					map[string]interface{}{"port": 443},
+				},
+			},
+			newValue: map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{"port": 8080},
+					map[string]interface{}{"port": 443},
+					map[string]interface{}{"port": 22},
+				},
+			},
+			expected: []Change{
+				{Path: "rules[0].port", Kind: ChangeModified, Old: 80, New: 8080},
+				{Path: "rules[2]", Kind: ChangeAdded, New: map[string]interface{}{"port": 22}},
+			},
+		},
+		{
+			name:     "type change at root",
+			oldValue: "a",
+			newValue: []interface{}{"a"},
+			expected: []Change{
+				{Path: "", Kind: ChangeModified, Old: "a", New: []interface{}{"a"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := DeepDiff(tc.oldValue, tc.newValue)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, changes)
+		})
+	}
+}
+
+func TestDiffTopLevel(t *testing.T) {
+	changes := diffTopLevel(
+		map[string]interface{}{"tags": map[string]interface{}{"a": 1}},
+		map[string]interface{}{"tags": map[string]interface{}{"a": 2}},
+	)
+
+	assert.Equal(t, []Change{{
+		Path: "tags",
+		Kind: ChangeModified,
+		Old:  map[string]interface{}{"a": 1},
+		New:  map[string]interface{}{"a": 2},
+	}}, changes)
+}
@@ -0,0 +1,74 @@
+package comparison
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// MarshalDiffJSON serializes a diff map as deterministic JSON: object keys are sorted, entry lists such
+// as added, removed and changed are sorted by their name or address, and empty lists and sections are
+// omitted. The same diff always produces the same bytes, so results can be diffed across runs.
+func MarshalDiffJSON(diffMap map[string]interface{}) ([]byte, error) {
+	return json.Marshal(canonicalDiffMap(diffMap))
+}
+
+// canonicalDiffMap returns a copy of a diff map section with its entry lists sorted and its empty
+// lists and sections dropped. Entry values are copied as they are.
+func canonicalDiffMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+
+	for k, v := range m {
+		if section, ok := v.(map[string]interface{}); ok {
+			if canonical := canonicalDiffMap(section); len(canonical) > 0 {
+				result[k] = canonical
+			}
+			continue
+		}
+
+		if isEmptyList(v) {
+			continue
+		}
+
+		if entries, ok := entryListOnly(v); ok {
+			result[k] = canonicalEntries(entries)
+			continue
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
+// canonicalEntries returns a copy of entries sorted by their key, with their attribute changes canonicalized.
+func canonicalEntries(entries []map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		entry = copyEntry(entry)
+		if attrs, ok := entry["attributes"].(map[string]interface{}); ok {
+			if canonical := canonicalDiffMap(attrs); len(canonical) > 0 {
+				entry["attributes"] = canonical
+			} else {
+				delete(entry, "attributes")
+			}
+		}
+		result = append(result, entry)
+	}
+
+	return sortEntries(result)
+}
+
+// entryListOnly returns v as a list of entries if every element of it is an entry.
+func entryListOnly(v interface{}) ([]map[string]interface{}, bool) {
+	entries := entryList(v)
+	if entries == nil || reflect.ValueOf(v).Len() != len(entries) {
+		return nil, false
+	}
+	return entries, true
+}
+
+// isEmptyList reports whether v is a slice with no elements.
+func isEmptyList(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Slice && value.Len() == 0
+}
@@ -0,0 +1,108 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		diffMap  map[string]interface{}
+		expected string
+	}{
+		{
+			name: "entries are sorted by address and name",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"added": []map[string]interface{}{{"address": "b", "value": 2}, {"address": "a", "value": 1}},
+					"changed": []map[string]interface{}{{
+						"address": "c",
+						"attributes": map[string]interface{}{
+							"changed": []map[string]interface{}{{"name": "z", "old": 1, "new": 2}, {"name": "y", "old": 1, "new": 2}},
+							"added":   []map[string]interface{}{},
+						},
+					}},
+				},
+				"variables": map[string]interface{}{
+					"removed": []interface{}{map[string]interface{}{"name": "q"}, map[string]interface{}{"name": "p"}},
+				},
+			},
+			expected: `{"resources":{"added":[{"address":"a","value":1},{"address":"b","value":2}],` +
+				`"changed":[{"address":"c","attributes":{"changed":[{"name":"y","new":2,"old":1},{"name":"z","new":2,"old":1}]}}]},` +
+				`"variables":{"removed":[{"name":"p"},{"name":"q"}]}}`,
+		},
+		{
+			name: "empty lists and sections are omitted",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"added":   []map[string]interface{}{},
+					"removed": []map[string]interface{}{},
+					"changed": []map[string]interface{}{{"address": "a", "attributes": map[string]interface{}{"added": []map[string]interface{}{}}}},
+				},
+				"outputs": map[string]interface{}{"added": []map[string]interface{}{}},
+			},
+			expected: `{"resources":{"changed":[{"address":"a"}]}}`,
+		},
+		{
+			name: "values and other lists are kept as they are",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"added":      []map[string]interface{}{{"address": "a", "value": map[string]interface{}{"tags": map[string]interface{}{}, "ports": []interface{}{}}}},
+					"cost_delta": 1.5,
+				},
+				"explanation": map[string]interface{}{"empty_sections": []string{"outputs"}},
+			},
+			expected: `{"explanation":{"empty_sections":["outputs"]},` +
+				`"resources":{"added":[{"address":"a","value":{"ports":[],"tags":{}}}],"cost_delta":1.5}}`,
+		},
+		{
+			name:     "no diff",
+			diffMap:  map[string]interface{}{},
+			expected: `{}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before, err := json.Marshal(tc.diffMap)
+			require.NoError(t, err)
+
+			actual, err := MarshalDiffJSON(tc.diffMap)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+
+			after, err := json.Marshal(tc.diffMap)
+			require.NoError(t, err)
+			assert.Equal(t, string(before), string(after), "the diff map must not be modified")
+		})
+	}
+}
+
+func TestMarshalDiffJSON_Deterministic(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.a", "change": {"after": {"ami": "1", "type": "t3.micro", "subnet": "x"}}},
+		{"address": "aws_instance.b", "change": {"after": {"ami": "1"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.a", "change": {"after": {"ami": "2", "type": "t3.large", "subnet": "y"}}},
+		{"address": "aws_instance.c", "change": {"after": {"ami": "1"}}},
+		{"address": "aws_instance.d", "change": {"after": {"ami": "1"}}}
+	]}`
+
+	var first []byte
+	for i := 0; i < 10; i++ {
+		result, err := ComparePlans(origJSON, newJSON, nil)
+		require.NoError(t, err)
+
+		actual, err := MarshalDiffJSON(result.Changes)
+		require.NoError(t, err)
+		if first == nil {
+			first = actual
+		}
+		assert.Equal(t, string(first), string(actual))
+	}
+}
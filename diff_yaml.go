@@ -0,0 +1,60 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalDiffYAML serializes a diff map as deterministic YAML with the same ordering as MarshalDiffJSON:
+// mapping keys are sorted, entry lists are sorted sequences and empty lists and sections are omitted.
+// Values of types YAML cannot represent, such as functions or channels, are written as their string form.
+func MarshalDiffYAML(diffMap map[string]interface{}) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("error marshaling diff as YAML: %v", r)
+		}
+	}()
+
+	return yaml.Marshal(yamlValue(reflect.ValueOf(canonicalDiffMap(diffMap))))
+}
+
+// yamlValue converts a value to plain maps with string keys, slices and scalars that YAML can encode.
+func yamlValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return yamlValue(v.Elem())
+	case reflect.Map:
+		result := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = yamlValue(iter.Value())
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = yamlValue(v.Index(i))
+		}
+		return result
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,118 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalDiffYAML(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{"address": "b", "value": 2}, {"address": "a", "value": 1}},
+			"changed": []map[string]interface{}{{
+				"address": "c",
+				"attributes": map[string]interface{}{
+					"changed": []map[string]interface{}{{"name": "z", "old": 1, "new": 2}, {"name": "y", "old": 1, "new": 2}},
+					"added":   []map[string]interface{}{},
+				},
+			}},
+			"removed": []map[string]interface{}{},
+		},
+		"variables": map[string]interface{}{
+			"removed": []interface{}{map[string]interface{}{"name": "q"}, map[string]interface{}{"name": "p"}},
+		},
+	}
+
+	out, err := MarshalDiffYAML(diffMap)
+	require.NoError(t, err)
+	assert.Equal(t, `resources:
+    added:
+        - address: a
+          value: 1
+        - address: b
+          value: 2
+    changed:
+        - address: c
+          attributes:
+            changed:
+                - name: "y"
+                  new: 2
+                  old: 1
+                - name: z
+                  new: 2
+                  old: 1
+variables:
+    removed:
+        - name: p
+        - name: q
+`, string(out))
+}
+
+func TestMarshalDiffYAML_RoundTrip(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{
+				"address": "aws_instance.web",
+				"value": map[string]interface{}{
+					"tags":    map[string]interface{}{"Name": "web"},
+					"ports":   []interface{}{80, 443},
+					"ratio":   0.5,
+					"enabled": true,
+					"none":    nil,
+				},
+			}},
+		},
+		"outputs": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "url", "old": "a", "new": "b"}},
+		},
+	}
+
+	out, err := MarshalDiffYAML(diffMap)
+	require.NoError(t, err)
+
+	var parsed map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out, &parsed))
+
+	expected, err := MarshalDiffJSON(diffMap)
+	require.NoError(t, err)
+	actual, err := json.Marshal(parsed)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestMarshalDiffYAML_UnusualTypes(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{
+				"address": "a",
+				"value": map[string]interface{}{
+					"keys":    map[interface{}]interface{}{1: "one", true: "yes"},
+					"bytes":   []byte("raw"),
+					"complex": complex(1, 2),
+					"func":    func() {},
+					"channel": make(chan int),
+					"array":   [2]int8{1, 2},
+				},
+			}},
+		},
+	}
+
+	var out []byte
+	require.NotPanics(t, func() {
+		var err error
+		out, err = MarshalDiffYAML(diffMap)
+		require.NoError(t, err)
+	})
+
+	var parsed map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out, &parsed))
+	value := parsed["resources"].(map[string]interface{})["added"].([]interface{})[0].(map[string]interface{})["value"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"1": "one", "true": "yes"}, value["keys"])
+	assert.Equal(t, "raw", value["bytes"])
+	assert.Equal(t, "(1+2i)", value["complex"])
+	assert.Equal(t, []interface{}{1, 2}, value["array"])
+}
@@ -0,0 +1,61 @@
+package comparison
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Differ compares plan pairs with a fixed configuration. The options are validated and compiled once
+// by NewDiffer, so a Differ can be reused across many comparisons. It is safe for concurrent use.
+type Differ struct {
+	opts *CompareOptions
+}
+
+// NewDiffer creates a Differ comparing plans with a copy of opts. It fails if the options are invalid,
+// e.g. when a SkipAttributes pattern does not compile. A nil opts uses the defaults.
+func NewDiffer(opts *CompareOptions) (*Differ, error) {
+	resolved := resolveOptions(opts)
+	if err := resolved.compile(); err != nil {
+		return nil, err
+	}
+	return &Differ{opts: resolved}, nil
+}
+
+// Compare compares two plan documents and returns the diff without printing it.
+func (d *Differ) Compare(origPlanFileJSON, newPlanFileJSON string) (*PlanDiff, error) {
+	return d.compareBytes([]byte(origPlanFileJSON), []byte(newPlanFileJSON))
+}
+
+// compareBytes compares two plan documents held as bytes, which are decoded as they are.
+func (d *Differ) compareBytes(origPlanFileJSON, newPlanFileJSON []byte) (*PlanDiff, error) {
+	// Work on a copy, since a comparison records state read from the plans in its options
+	opts := resolveOptions(d.opts)
+	parseStart := time.Now()
+
+	// Parse the plans
+	origPlan, err := decodePlan(origPlanFileJSON, opts.InputFormat)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing original plan")
+	}
+
+	newPlan, err := decodePlan(newPlanFileJSON, opts.InputFormat)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing new plan")
+	}
+
+	if opts.UseSchemaOrder {
+		opts.schemaOrder = loadSchemaOrder(origPlanFileJSON, newPlanFileJSON)
+	}
+	parseDuration := time.Since(parseStart)
+
+	result, err := comparePlanMaps(origPlan, newPlan, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Stats.OriginalBytes = len(origPlanFileJSON)
+	result.Stats.NewBytes = len(newPlanFileJSON)
+	result.Stats.ParseDuration = parseDuration
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package comparison
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffer_Compare(t *testing.T) {
+	opts := &CompareOptions{SkipAttributes: []string{"re:.*_hash"}, ExcludeAddresses: []string{"aws_s3_bucket.*"}}
+	differ, err := NewDiffer(opts)
+	require.NoError(t, err)
+
+	// Later changes to the options do not affect the differ
+	opts.ExcludeAddresses = nil
+
+	for i := 0; i < 3; i++ {
+		origJSON := fmt.Sprintf(`{"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-%d", "user_data_hash": "a"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "a"}}}
+		]}`, i)
+		newJSON := fmt.Sprintf(`{"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-%d", "user_data_hash": "b"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "b"}}}
+		]}`, i+1)
+
+		result, err := differ.Compare(origJSON, newJSON)
+		require.NoError(t, err)
+		assert.Contains(t, result.Text, fmt.Sprintf("~ ami: ami-%d => ami-%d", i, i+1))
+		assert.NotContains(t, result.Text, "user_data_hash")
+		assert.NotContains(t, result.Text, "aws_s3_bucket.logs")
+
+		expected, err := ComparePlans(origJSON, newJSON, &CompareOptions{SkipAttributes: []string{"re:.*_hash"}, ExcludeAddresses: []string{"aws_s3_bucket.*"}})
+		require.NoError(t, err)
+		assert.Equal(t, expected.Text, result.Text)
+		assert.Equal(t, expected.Changes, result.Changes)
+	}
+}
+
+func TestNewDiffer_InvalidOptions(t *testing.T) {
+	_, err := NewDiffer(&CompareOptions{SkipAttributes: []string{"re:("}})
+	assert.ErrorContains(t, err, "invalid SkipAttributes pattern")
+}
+
+func TestDiffer_ConcurrentUse(t *testing.T) {
+	differ, err := NewDiffer(&CompareOptions{UseSchemaOrder: true})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := differ.Compare(`{"variables": {"n": {"value": 0}}}`, fmt.Sprintf(`{"variables": {"n": {"value": %d}}}`, i+1))
+			assert.NoError(t, err)
+			assert.Contains(t, result.Text, fmt.Sprintf("~ n: 0 => %d", i+1))
+		}(i)
+	}
+	wg.Wait()
+}
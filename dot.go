@@ -0,0 +1,195 @@
+package comparison
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// dotColors maps a resource change category to its Graphviz fill color.
+var dotColors = map[string]string{
+	"added":         "palegreen",
+	"removed":       "lightcoral",
+	"changed":       "khaki",
+	"moved":         "lightblue",
+	"mode_changed":  "orange",
+	"count_changed": "wheat",
+	"drift":         "lightgray",
+}
+
+// FormatDOT renders the resources affected by a diff as a Graphviz graph, colored by change category.
+// Edges point from a resource to the affected resources it references in the plan's configuration block.
+func FormatDOT(diffMap map[string]interface{}, planJSON string) (string, error) {
+	var plan map[string]interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return "", errors.Wrap(err, "error parsing plan JSON")
+	}
+
+	nodes := affectedResourceNodes(diffMap)
+	addresses := make([]string, 0, len(nodes))
+	for address := range nodes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	references := getResourceReferences(plan)
+
+	var sb strings.Builder
+	sb.WriteString("digraph plan_diff {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=filled];\n")
+
+	for _, address := range addresses {
+		category := nodes[address]
+		sb.WriteString(fmt.Sprintf("  %s [fillcolor=%q, label=%s];\n",
+			dotQuote(address), dotColors[category], dotQuote(address+"\n("+category+")")))
+	}
+
+	for _, from := range addresses {
+		refs := references[configAddress(from)]
+		for _, to := range addresses {
+			if from != to && referencesAddress(refs, configAddress(to)) {
+				sb.WriteString(fmt.Sprintf("  %s -> %s;\n", dotQuote(from), dotQuote(to)))
+			}
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// resourceChangeKinds are the categories of resource changes in a resources, tag_changes or drift section.
+var resourceChangeKinds = []string{"count_changed", "added", "removed", "moved", "mode_changed", "changed"}
+
+// affectedResourceNodes returns the change category of every resource address in a diff map. Moved resources
+// are listed by their new address, and resources that only drifted are in the drift category.
+func affectedResourceNodes(diffMap map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+
+	// The planned changes of a resource take precedence over its drift
+	drift, _ := diffMap["drift"].(map[string]interface{})
+	for _, kind := range resourceChangeKinds {
+		for _, entry := range entryList(drift[kind]) {
+			result[entryAddress(entry)] = "drift"
+		}
+	}
+
+	// Tag-only changes are changed resources listed in a section of their own
+	tagChanges, _ := diffMap["tag_changes"].(map[string]interface{})
+	for _, entry := range entryList(tagChanges["changed"]) {
+		result[entryAddress(entry)] = "changed"
+	}
+
+	resources, _ := diffMap["resources"].(map[string]interface{})
+	for _, kind := range resourceChangeKinds {
+		for _, entry := range entryList(resources[kind]) {
+			result[entryAddress(entry)] = kind
+		}
+	}
+
+	return result
+}
+
+// getResourceReferences returns the references made by each resource in the configuration block,
+// keyed by configuration address. References inside modules are qualified with the module path.
+func getResourceReferences(plan map[string]interface{}) map[string][]string {
+	result := make(map[string][]string)
+
+	configuration, ok := plan["configuration"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	if rootModule, ok := configuration["root_module"].(map[string]interface{}); ok {
+		collectModuleReferences(rootModule, "", result)
+	}
+
+	return result
+}
+
+// collectModuleReferences records the references of a module's resources and recurses into its module calls.
+func collectModuleReferences(module map[string]interface{}, prefix string, result map[string][]string) {
+	for _, res := range entryList(module["resources"]) {
+		address, ok := res["address"].(string)
+		if !ok {
+			continue
+		}
+
+		refs := make([]string, 0)
+		collectReferences(res["expressions"], &refs)
+		refs = append(refs, stringList(res["depends_on"])...)
+
+		for i, ref := range refs {
+			refs[i] = prefix + ref
+		}
+		result[prefix+address] = append(result[prefix+address], refs...)
+	}
+
+	if moduleCalls, ok := module["module_calls"].(map[string]interface{}); ok {
+		for name, call := range moduleCalls {
+			callMap, ok := call.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if child, ok := callMap["module"].(map[string]interface{}); ok {
+				collectModuleReferences(child, prefix+"module."+name+".", result)
+			}
+		}
+	}
+}
+
+// collectReferences walks an expressions tree and collects every "references" entry.
+func collectReferences(value interface{}, refs *[]string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for k, v := range typed {
+			if k == "references" {
+				*refs = append(*refs, stringList(v)...)
+				continue
+			}
+			collectReferences(v, refs)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			collectReferences(item, refs)
+		}
+	}
+}
+
+// stringList returns the string items of a JSON list.
+func stringList(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// referencesAddress reports whether any reference points at the given configuration address or one of its attributes.
+func referencesAddress(refs []string, address string) bool {
+	for _, ref := range refs {
+		if ref == address || strings.HasPrefix(ref, address+".") || strings.HasPrefix(ref, address+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// dotQuote quotes a string as a Graphviz ID.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
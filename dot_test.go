@@ -0,0 +1,70 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDOT(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"added":   []map[string]interface{}{{"address": "aws_instance.web[0]"}},
+			"removed": []map[string]interface{}{{"address": "aws_eip.old"}},
+			"changed": []map[string]interface{}{{"address": "aws_security_group.sg"}},
+			"moved":   []map[string]interface{}{},
+		},
+		"tag_changes": map[string]interface{}{
+			"changed": []map[string]interface{}{{"address": "aws_subnet.tagged"}},
+		},
+	}
+
+	planJSON := `{"configuration": {"root_module": {"resources": [
+		{"address": "aws_instance.web", "expressions": {
+			"vpc_security_group_ids": {"references": ["aws_security_group.sg.id", "aws_security_group.sg"]},
+			"subnet_id": {"references": ["aws_subnet.unchanged.id"]}
+		}},
+		{"address": "aws_security_group.sg", "expressions": {"name": {"constant_value": "sg"}}}
+	]}}}`
+
+	dot, err := FormatDOT(diffMap, planJSON)
+	require.NoError(t, err)
+
+	assert.Contains(t, dot, "digraph plan_diff {")
+	assert.Contains(t, dot, `"aws_instance.web[0]" [fillcolor="palegreen", label="aws_instance.web[0]\n(added)"];`)
+	assert.Contains(t, dot, `"aws_eip.old" [fillcolor="lightcoral"`)
+	assert.Contains(t, dot, `"aws_security_group.sg" [fillcolor="khaki"`)
+	assert.Contains(t, dot, `"aws_subnet.tagged" [fillcolor="khaki"`)
+	assert.Contains(t, dot, `"aws_instance.web[0]" -> "aws_security_group.sg";`)
+	assert.NotContains(t, dot, "aws_subnet.unchanged")
+	assert.NotContains(t, dot, `"aws_security_group.sg" -> `)
+}
+
+func TestFormatDOT_ChangeKinds(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"count_changed": []map[string]interface{}{{"address": "aws_instance.web", "old_count": 2, "new_count": 3}},
+			"mode_changed":  []map[string]interface{}{{"address": "aws_s3_bucket.logs", "old_mode": "managed", "new_mode": "data"}},
+			"moved":         []map[string]interface{}{{"old_address": "aws_eip.a", "new_address": "aws_eip.b"}},
+		},
+		"drift": map[string]interface{}{
+			"added":   []map[string]interface{}{{"address": "aws_sqs_queue.jobs"}},
+			"changed": []map[string]interface{}{{"address": "aws_instance.web"}},
+		},
+	}
+
+	dot, err := FormatDOT(diffMap, `{}`)
+	require.NoError(t, err)
+
+	assert.Contains(t, dot, `"aws_instance.web" [fillcolor="wheat", label="aws_instance.web\n(count_changed)"];`)
+	assert.Contains(t, dot, `"aws_s3_bucket.logs" [fillcolor="orange", label="aws_s3_bucket.logs\n(mode_changed)"];`)
+	assert.Contains(t, dot, `"aws_eip.b" [fillcolor="lightblue", label="aws_eip.b\n(moved)"];`)
+	assert.Contains(t, dot, `"aws_sqs_queue.jobs" [fillcolor="lightgray", label="aws_sqs_queue.jobs\n(drift)"];`)
+	assert.NotContains(t, dot, "aws_eip.a")
+}
+
+func TestFormatDOT_InvalidPlan(t *testing.T) {
+	_, err := FormatDOT(map[string]interface{}{}, "{")
+	assert.Error(t, err)
+}
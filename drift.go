@@ -0,0 +1,61 @@
+package comparison
+
+import "strings"
+
+// DriftMode controls how resources that appear only in a plan's resource_drift are reported.
+type DriftMode string
+
+const (
+	// DriftModeIgnore leaves drift-only resources out of the comparison. It is the default.
+	DriftModeIgnore DriftMode = "ignore"
+
+	// DriftModeSection compares drift-only resources in their own drift section.
+	DriftModeSection DriftMode = "section"
+
+	// DriftModeMerge compares drift-only resources together with the planned resource changes.
+	DriftModeMerge DriftMode = "merge"
+)
+
+// getDriftResources extracts the resources that appear in resource_drift but in none of the
+// sections getResources reads, keyed by address.
+func getDriftResources(plan map[string]interface{}) map[string]interface{} {
+	drift := make(map[string]interface{})
+	processResourceChanges(map[string]interface{}{"resource_changes": plan["resource_drift"]}, drift, true)
+
+	for address := range getResources(plan) {
+		delete(drift, address)
+	}
+
+	return drift
+}
+
+// mergeDriftResources adds the plan's drift-only resources to resources.
+func mergeDriftResources(plan map[string]interface{}, resources map[string]interface{}) map[string]interface{} {
+	for address, resource := range getDriftResources(plan) {
+		resources[address] = resource
+	}
+	return resources
+}
+
+// compareDriftSections compares the drift-only resources of two plans in a section of their own.
+func compareDriftSections(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
+	origDrift, newDrift := filterAddresses(getDriftResources(origPlan), opts), filterAddresses(getDriftResources(newPlan), opts)
+	origDrift, newDrift, ignored := ignoreResources(origDrift, newDrift, opts)
+
+	driftDiff, driftDiffMap := compareResources(origDrift, newDrift, opts)
+	if ignored > 0 {
+		driftDiffMap["ignored"] = ignored
+	}
+	if driftDiff == "" {
+		return "", driftDiffMap, false
+	}
+
+	var diff strings.Builder
+	diff.WriteString("Drift:\n")
+	diff.WriteString("------\n")
+	diff.WriteString("\n")
+	diff.WriteString(driftDiff)
+	diff.WriteString("\n")
+
+	return diff.String(), driftDiffMap, true
+}
@@ -0,0 +1,95 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans_DriftMode(t *testing.T) {
+	origJSON := `{
+		"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}}]
+	}`
+	newJSON := `{
+		"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}}],
+		"resource_drift": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.small"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}
+		]
+	}`
+
+	tests := []struct {
+		name        string
+		mode        DriftMode
+		hasDiff     bool
+		contains    []string
+		notContains []string
+		drift       []string
+		resources   []string
+	}{
+		{
+			name:        "default ignores drift",
+			hasDiff:     false,
+			notContains: []string{"aws_s3_bucket.logs"},
+		},
+		{
+			name:        "ignore",
+			mode:        DriftModeIgnore,
+			hasDiff:     false,
+			notContains: []string{"aws_s3_bucket.logs"},
+		},
+		{
+			name:        "section",
+			mode:        DriftModeSection,
+			hasDiff:     true,
+			contains:    []string{"Drift:\n------\n\n+ aws_s3_bucket.logs"},
+			notContains: []string{"Resources:\n", "aws_instance.web"},
+			drift:       []string{"aws_s3_bucket.logs"},
+		},
+		{
+			name:        "merge",
+			mode:        DriftModeMerge,
+			hasDiff:     true,
+			contains:    []string{"Resources:\n-----------\n\n+ aws_s3_bucket.logs"},
+			notContains: []string{"Drift:\n", "aws_instance.web"},
+			resources:   []string{"aws_s3_bucket.logs"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(origJSON, newJSON, &CompareOptions{DriftMode: tc.mode})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.hasDiff, result.HasDiff)
+			for _, expected := range tc.contains {
+				assert.Contains(t, result.Text, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, result.Text, notExpected)
+			}
+
+			if tc.drift == nil {
+				assert.NotContains(t, result.Changes, "drift")
+			} else {
+				assert.Equal(t, tc.drift, addedAddresses(result.Changes["drift"]))
+			}
+			if tc.resources == nil {
+				assert.NotContains(t, result.Changes, "resources")
+			} else {
+				assert.Equal(t, tc.resources, addedAddresses(result.Changes["resources"]))
+			}
+		})
+	}
+}
+
+func addedAddresses(section interface{}) []string {
+	categories, _ := section.(map[string]interface{})
+
+	addresses := make([]string, 0)
+	for _, entry := range entryList(categories["added"]) {
+		addresses = append(addresses, entryKey(entry))
+	}
+	return addresses
+}
@@ -0,0 +1,56 @@
+package comparison
+
+import "strings"
+
+// processAttributesByDriver writes the attribute differences of a resource in two groups: user-driven
+// attributes that are set in the configuration, then provider-driven attributes that are computed or
+// defaulted. It returns the merged attribute changes and the attribute names of each group.
+func processAttributesByDriver(diff *strings.Builder, address string, origAttrs, newAttrs map[string]interface{}, declared map[string]bool, opts *CompareOptions) (map[string]interface{}, map[string]interface{}) {
+	attrChanges := map[string]interface{}{
+		"added":   make([]map[string]interface{}, 0),
+		"removed": make([]map[string]interface{}, 0),
+		"changed": make([]map[string]interface{}, 0),
+	}
+	drivers := make(map[string]interface{})
+
+	for _, group := range []struct {
+		driver string
+		keep   func(attrK string) bool
+	}{
+		{"user", func(attrK string) bool { return declared[attrK] }},
+		{"provider", func(attrK string) bool { return !declared[attrK] }},
+	} {
+		origGroup, newGroup := filterAttributes(origAttrs, group.keep), filterAttributes(newAttrs, group.keep)
+
+		var groupDiff strings.Builder
+		groupChanges := processAttributeDifferences(&groupDiff, origGroup, newGroup, opts.attributeOrder(address), opts)
+
+		names := make([]string, 0)
+		for _, kind := range []string{"added", "removed", "changed"} {
+			entries := entryList(groupChanges[kind])
+			attrChanges[kind] = append(attrChanges[kind].([]map[string]interface{}), entries...)
+			for _, entry := range entries {
+				names = append(names, entry["name"].(string))
+			}
+		}
+		drivers[group.driver] = names
+
+		if groupDiff.Len() > 0 {
+			diff.WriteString("  # " + group.driver + "-driven\n")
+			diff.WriteString(groupDiff.String())
+		}
+	}
+
+	return attrChanges, drivers
+}
+
+// filterAttributes returns the attributes whose names satisfy keep.
+func filterAttributes(attrs map[string]interface{}, keep func(attrK string) bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(attrs))
+	for attrK, v := range attrs {
+		if keep(attrK) {
+			result[attrK] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func driverPlan(instanceType, arn string) string {
+	return `{
+		"resource_changes": [{
+			"address": "aws_instance.web",
+			"change": {"after": {"instance_type": "` + instanceType + `", "arn": "` + arn + `"}}
+		}],
+		"configuration": {"root_module": {"resources": [{
+			"address": "aws_instance.web",
+			"expressions": {"instance_type": {"constant_value": "x"}}
+		}]}}
+	}`
+}
+
+func TestGroupByDriver(t *testing.T) {
+	result, err := ComparePlans(driverPlan("t3.micro", "arn:1"), driverPlan("t3.large", "arn:2"), &CompareOptions{GroupByDriver: true})
+	require.NoError(t, err)
+
+	userIdx := strings.Index(result.Text, "  # user-driven\n  ~ instance_type: t3.micro => t3.large")
+	providerIdx := strings.Index(result.Text, "  # provider-driven\n  ~ arn: arn:1 => arn:2")
+	require.NotEqual(t, -1, userIdx)
+	require.NotEqual(t, -1, providerIdx)
+	assert.Less(t, userIdx, providerIdx)
+
+	changed := entryList(result.Changes["resources"].(map[string]interface{})["changed"])
+	require.Len(t, changed, 1)
+	assert.Equal(t, map[string]interface{}{
+		"user":     []string{"instance_type"},
+		"provider": []string{"arn"},
+	}, changed[0]["drivers"])
+	assert.Len(t, changed[0]["attributes"].(map[string]interface{})["changed"], 2)
+}
+
+func TestGroupByDriver_WithoutConfiguration(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"arn": "arn:1"}},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"arn": "arn:2"}},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{GroupByDriver: true})
+
+	assert.NotContains(t, diff, "-driven")
+	assert.NotContains(t, entryList(diffMap["changed"])[0], "drivers")
+}
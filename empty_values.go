@@ -0,0 +1,83 @@
+package comparison
+
+import "reflect"
+
+// EmptyValueKind is a kind of empty value that IgnoreEmptyStringNullEquivalence treats as equal to null.
+type EmptyValueKind string
+
+const (
+	// EmptyString is the empty string "".
+	EmptyString EmptyValueKind = "string"
+
+	// EmptyMap is a map without keys.
+	EmptyMap EmptyValueKind = "map"
+
+	// EmptyList is a list without elements.
+	EmptyList EmptyValueKind = "list"
+)
+
+// defaultEmptyValueKinds are the kinds used when EmptyValueKinds is not set.
+var defaultEmptyValueKinds = []EmptyValueKind{EmptyString, EmptyMap, EmptyList}
+
+// emptyValueKinds returns the set of empty value kinds equivalent to null.
+func (o *CompareOptions) emptyValueKinds() map[EmptyValueKind]bool {
+	kinds := o.EmptyValueKinds
+	if len(kinds) == 0 {
+		kinds = defaultEmptyValueKinds
+	}
+
+	set := make(map[EmptyValueKind]bool, len(kinds))
+	for _, kind := range kinds {
+		set[kind] = true
+	}
+	return set
+}
+
+// equateEmptyValues rewrites attribute values that are null or empty on both sides to null, recursing
+// into nested maps. Only the empty values of the given kinds count. It reports whether any value was rewritten.
+func equateEmptyValues(origAttrs, newAttrs map[string]interface{}, kinds map[EmptyValueKind]bool) bool {
+	rewritten := false
+
+	for k, origV := range origAttrs {
+		newV, exists := newAttrs[k]
+		if !exists || reflect.DeepEqual(origV, newV) {
+			continue
+		}
+
+		// Recurse into nested blocks
+		origMap, origIsMap := origV.(map[string]interface{})
+		newMap, newIsMap := newV.(map[string]interface{})
+		if origIsMap && newIsMap && len(origMap) > 0 && len(newMap) > 0 {
+			if equateEmptyValues(origMap, newMap, kinds) {
+				rewritten = true
+			}
+			continue
+		}
+
+		if !isEmptyValue(origV, kinds) || !isEmptyValue(newV, kinds) {
+			continue
+		}
+
+		origAttrs[k] = nil
+		newAttrs[k] = nil
+		rewritten = true
+	}
+
+	return rewritten
+}
+
+// isEmptyValue reports whether v is null or an empty value of one of the given kinds.
+func isEmptyValue(v interface{}, kinds map[EmptyValueKind]bool) bool {
+	switch typed := v.(type) {
+	case nil:
+		return true
+	case string:
+		return kinds[EmptyString] && typed == ""
+	case map[string]interface{}:
+		return kinds[EmptyMap] && len(typed) == 0
+	case []interface{}:
+		return kinds[EmptyList] && len(typed) == 0
+	default:
+		return false
+	}
+}
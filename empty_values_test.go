@@ -0,0 +1,93 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreEmptyStringNullEquivalence(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{
+				"description": nil,
+				"tags":        map[string]interface{}{},
+				"groups":      nil,
+				"metadata":    map[string]interface{}{"owner": "", "team": "a"},
+			},
+		},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{
+				"description": "",
+				"tags":        nil,
+				"groups":      []interface{}{},
+				"metadata":    map[string]interface{}{"owner": nil, "team": "a"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		opts        *CompareOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "disabled",
+			opts:     &CompareOptions{},
+			contains: []string{"description", "tags", "groups", "metadata.owner"},
+		},
+		{
+			name:        "every kind",
+			opts:        &CompareOptions{IgnoreEmptyStringNullEquivalence: true},
+			notContains: []string{"aws_instance.web"},
+		},
+		{
+			name:        "strings only",
+			opts:        &CompareOptions{IgnoreEmptyStringNullEquivalence: true, EmptyValueKinds: []EmptyValueKind{EmptyString}},
+			contains:    []string{"tags", "groups"},
+			notContains: []string{"description", "metadata"},
+		},
+		{
+			name:        "maps and lists",
+			opts:        &CompareOptions{IgnoreEmptyStringNullEquivalence: true, EmptyValueKinds: []EmptyValueKind{EmptyMap, EmptyList}},
+			contains:    []string{"description", "metadata.owner"},
+			notContains: []string{"tags", "groups"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _ := compareResources(copyResources(origRes), copyResources(newRes), tc.opts)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff, notExpected)
+			}
+		})
+	}
+}
+
+func TestIgnoreEmptyStringNullEquivalence_RealChanges(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"name": nil, "ports": []interface{}{}}},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"name": "web", "ports": []interface{}{80.0}}},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{IgnoreEmptyStringNullEquivalence: true, ReportNormalized: true})
+	assert.Contains(t, diff, "~ name: <nil> => web")
+	assert.Contains(t, diff, "+ ports[0]: 80")
+	assert.NotContains(t, diffMap, "normalized_away")
+}
+
+// copyResources deep copies resources, since normalization rewrites attribute values in place.
+func copyResources(resources map[string]interface{}) map[string]interface{} {
+	copied, _ := sortMapKeys(resources)
+	return copied
+}
@@ -0,0 +1,18 @@
+package comparison
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// EnumeratePlan compares a plan against an empty baseline, so every variable, resource and output
+// in the plan is reported as added. This gives a table of contents of the plan in the diff format.
+func EnumeratePlan(planJSON string) (*PlanDiff, error) {
+	var plan map[string]interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, errors.Wrap(err, "error parsing plan JSON")
+	}
+
+	return comparePlanMaps(map[string]interface{}{}, plan, resolveOptions(nil))
+}
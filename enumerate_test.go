@@ -0,0 +1,42 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumeratePlan(t *testing.T) {
+	planJSON := `{
+		"variables": {"region": {"value": "us-east-1"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro", "ami": "ami-123"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}
+		],
+		"planned_values": {"outputs": {"url": {"sensitive": false, "value": "https://example.com"}}}
+	}`
+
+	result, err := EnumeratePlan(planJSON)
+	require.NoError(t, err)
+	require.True(t, result.HasDiff)
+
+	assert.Contains(t, result.Text, "+ aws_instance.web")
+	assert.Contains(t, result.Text, "+ aws_s3_bucket.logs")
+	assert.Contains(t, result.Text, "+ region: us-east-1")
+	assert.Contains(t, result.Text, "+ url:")
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	added := resources["added"].([]map[string]interface{})
+	require.Len(t, added, 2)
+	assert.Equal(t, "aws_instance.web", added[0]["address"])
+	assert.Equal(t, map[string]interface{}{"instance_type": "t3.micro", "ami": "ami-123"},
+		getResourceAttributes(added[0]["value"], &CompareOptions{}))
+	assert.Empty(t, resources["removed"])
+	assert.Empty(t, resources["changed"])
+}
+
+func TestEnumeratePlan_InvalidJSON(t *testing.T) {
+	_, err := EnumeratePlan("not json")
+	assert.Error(t, err)
+}
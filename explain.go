@@ -0,0 +1,94 @@
+package comparison
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resourceSources are the plan sections resources are extracted from, in extraction order.
+var resourceSources = []struct {
+	name    string
+	extract func(plan map[string]interface{}, result map[string]interface{})
+}{
+	{"prior_state", processPriorStateResources},
+	{"planned_values", processPlannedValuesResources},
+	{"resource_changes", func(plan map[string]interface{}, result map[string]interface{}) {
+		processResourceChanges(plan, result, true)
+	}},
+}
+
+// explainIdentical describes what was extracted from each plan, so an identical result can be audited.
+// Resources are counted as compared, after the options select, filter and ignore them. Sections that are
+// empty in both plans are listed under empty_sections, as they usually point to an extraction problem
+// rather than to plans without changes.
+func explainIdentical(origPlan, newPlan map[string]interface{}, opts *CompareOptions) map[string]interface{} {
+	origResources, newResources := comparedResourceSets(origPlan, newPlan, opts)
+	origResources, newResources, _ = ignoreResources(origResources, newResources, opts)
+	origCounts, newCounts := extractionCounts(origPlan, origResources), extractionCounts(newPlan, newResources)
+
+	emptySections := make([]string, 0)
+	for _, section := range []string{"variables", "resources", "outputs"} {
+		if origCounts[section] == 0 && newCounts[section] == 0 {
+			emptySections = append(emptySections, section)
+		}
+	}
+
+	return map[string]interface{}{
+		"original":       origCounts,
+		"new":            newCounts,
+		"empty_sections": emptySections,
+	}
+}
+
+// extractionCounts counts the variables and outputs extracted from a plan and its compared resources, and
+// the resources found in each source section.
+func extractionCounts(plan, resources map[string]interface{}) map[string]interface{} {
+	sources := make(map[string]int, len(resourceSources))
+	for _, source := range resourceSources {
+		found := make(map[string]interface{})
+		source.extract(plan, found)
+		sources[source.name] = len(found)
+	}
+
+	return map[string]interface{}{
+		"variables": len(getVariables(plan)),
+		"resources": len(resources),
+		"outputs":   len(getOutputs(plan)),
+		"sources":   sources,
+	}
+}
+
+// formatExplanation renders an explanation of an identical result.
+func formatExplanation(explanation map[string]interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("Compared:\n")
+
+	for _, side := range []string{"original", "new"} {
+		counts, ok := explanation[side].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		sources, _ := counts["sources"].(map[string]int)
+		names := make([]string, 0, len(sources))
+		for name := range sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s: %d", name, sources[name]))
+		}
+
+		sb.WriteString(fmt.Sprintf("  %s: %v variables, %v resources (%s), %v outputs\n",
+			side, counts["variables"], counts["resources"], strings.Join(parts, ", "), counts["outputs"]))
+	}
+
+	if empty, ok := explanation["empty_sections"].([]string); ok && len(empty) > 0 {
+		sb.WriteString(fmt.Sprintf("  empty in both plans: %s\n", strings.Join(empty, ", ")))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainIdentical_NestedModuleResources(t *testing.T) {
+	// Resources that only exist in child modules of planned_values are not extracted
+	plan := func(instanceType string) string {
+		return `{
+			"variables": {"env": {"value": "dev"}},
+			"planned_values": {"root_module": {"child_modules": [{
+				"address": "module.app",
+				"resources": [{"address": "module.app.aws_instance.web", "values": {"instance_type": "` + instanceType + `"}}]
+			}]}}
+		}`
+	}
+
+	result, err := ComparePlans(plan("t3.micro"), plan("t3.large"), &CompareOptions{ExplainIdentical: true})
+	require.NoError(t, err)
+	require.False(t, result.HasDiff)
+
+	explanation := result.Changes["explanation"].(map[string]interface{})
+	expectedCounts := map[string]interface{}{
+		"variables": 1,
+		"resources": 0,
+		"outputs":   0,
+		"sources":   map[string]int{"prior_state": 0, "planned_values": 0, "resource_changes": 0},
+	}
+	assert.Equal(t, expectedCounts, explanation["original"])
+	assert.Equal(t, expectedCounts, explanation["new"])
+	assert.Equal(t, []string{"resources", "outputs"}, explanation["empty_sections"])
+
+	text := formatExplanation(explanation)
+	assert.Contains(t, text, "original: 1 variables, 0 resources (planned_values: 0, prior_state: 0, resource_changes: 0), 0 outputs")
+	assert.Contains(t, text, "empty in both plans: resources, outputs")
+}
+
+func TestExplainIdentical_ComparedResources(t *testing.T) {
+	plan := `{
+		"prior_state": {"values": {"root_module": {"resources": [
+			{"address": "aws_s3_bucket.logs", "values": {"bucket": "logs"}}
+		]}}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"actions": ["update"], "after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.db", "change": {"actions": ["no-op"], "after": {"ami": "ami-1"}}},
+			{"address": "aws_iam_role.ci", "change": {"actions": ["create"], "after": {"name": "ci"}}}
+		]
+	}`
+
+	tests := []struct {
+		name      string
+		opts      *CompareOptions
+		resources int
+	}{
+		{name: "defaults", opts: &CompareOptions{}, resources: 4},
+		{name: "planned only", opts: &CompareOptions{PlannedOnly: true}, resources: 2},
+		{name: "excluded addresses", opts: &CompareOptions{ExcludeAddresses: []string{"aws_instance.*"}}, resources: 2},
+		{name: "included types", opts: &CompareOptions{IncludeTypes: []string{"aws_s3_bucket"}}, resources: 1},
+		{name: "ignored addresses", opts: &CompareOptions{Ignore: &IgnoreSet{Addresses: []string{"aws_iam_role.*"}}}, resources: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ExplainIdentical = true
+			result, err := ComparePlans(plan, plan, tt.opts)
+			require.NoError(t, err)
+			require.False(t, result.HasDiff)
+
+			explanation := result.Changes["explanation"].(map[string]interface{})
+			assert.Equal(t, tt.resources, explanation["original"].(map[string]interface{})["resources"])
+			assert.Equal(t, tt.resources, explanation["new"].(map[string]interface{})["resources"])
+		})
+	}
+}
+
+func TestExplainIdentical_OnlyForIdenticalPlans(t *testing.T) {
+	plan := func(env string) string {
+		return `{"variables": {"env": {"value": "` + env + `"}}}`
+	}
+
+	tests := []struct {
+		name    string
+		updated string
+		opts    *CompareOptions
+		explain bool
+	}{
+		{name: "identical with option", updated: plan("dev"), opts: &CompareOptions{ExplainIdentical: true}, explain: true},
+		{name: "identical without option", updated: plan("dev"), opts: &CompareOptions{}, explain: false},
+		{name: "different plans", updated: plan("prod"), opts: &CompareOptions{ExplainIdentical: true}, explain: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(plan("dev"), tc.updated, tc.opts)
+			require.NoError(t, err)
+
+			_, ok := result.Changes["explanation"]
+			assert.Equal(t, tc.explain, ok)
+		})
+	}
+}
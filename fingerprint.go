@@ -0,0 +1,219 @@
+package comparison
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"hash"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// maxFingerprintDepth bounds the nesting walked when fingerprinting. Deeper or cyclic values are not
+// fingerprinted and fall back to reflect.DeepEqual.
+const maxFingerprintDepth = 1000
+
+// Type tags written before each value, so values of different types never hash alike.
+const (
+	tagNil byte = iota
+	tagBool
+	tagFloat
+	tagInt
+	tagString
+	tagNumber
+	tagMap
+	tagNilMap
+	tagList
+	tagNilList
+	tagEntryList
+	tagNilEntryList
+)
+
+// fingerprint is a SHA-256 hash of a JSON-like value. Values equal under reflect.DeepEqual have equal
+// fingerprints, and a collision-resistant hash keeps different values, even crafted ones, from sharing one.
+type fingerprint [sha256.Size]byte
+
+// fingerprintEntry is a memoized fingerprint. It holds on to the resource so its map cannot be freed and
+// its address reused for another resource while the cache lives.
+type fingerprintEntry struct {
+	resource map[string]interface{}
+	value    fingerprint
+	ok       bool
+}
+
+// fingerprintCache memoizes the fingerprints of resources by the identity of their map, so every
+// resource is walked once per comparison however often it is compared. Resources must not be modified
+// while the cache is in use. A nil cache computes fingerprints without memoizing them.
+type fingerprintCache map[uintptr]fingerprintEntry
+
+// of returns the fingerprint of a resource, or false when it cannot be fingerprinted.
+func (c fingerprintCache) of(resource interface{}) (fingerprint, bool) {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok || resMap == nil || c == nil {
+		return fingerprintOf(resource)
+	}
+
+	key := reflect.ValueOf(resMap).Pointer()
+	if entry, exists := c[key]; exists {
+		return entry.value, entry.ok
+	}
+
+	value, ok := fingerprintOf(resMap)
+	c[key] = fingerprintEntry{resource: resMap, value: value, ok: ok}
+	return value, ok
+}
+
+// sameResource reports whether two resources are deeply equal, comparing their fingerprints when both
+// can be taken.
+func sameResource(a, b interface{}, opts *CompareOptions) bool {
+	aFingerprint, aOk := opts.fingerprints.of(a)
+	bFingerprint, bOk := opts.fingerprints.of(b)
+	if aOk && bOk {
+		return aFingerprint == bFingerprint
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// sameResources reports whether two resource maps hold the same addresses with deeply equal resources.
+func sameResources(a, b map[string]interface{}, opts *CompareOptions) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+
+	for address, resource := range a {
+		other, exists := b[address]
+		if !exists || !sameResource(resource, other, opts) {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprintOf hashes a value, returning false for values it cannot fingerprint exactly.
+func fingerprintOf(value interface{}) (fingerprint, bool) {
+	h := fingerprintHasher{hash: sha256.New()}
+	if !h.write(value, 0) {
+		return fingerprint{}, false
+	}
+
+	var result fingerprint
+	h.hash.Sum(result[:0])
+	return result, true
+}
+
+// fingerprintHasher feeds a value into a hash in a canonical encoding. Strings are copied through a
+// reused buffer to avoid an allocation per string.
+type fingerprintHasher struct {
+	hash    hash.Hash
+	scratch [8]byte
+	buf     []byte
+}
+
+// write hashes a value and reports whether every part of it could be hashed.
+func (h *fingerprintHasher) write(value interface{}, depth int) bool {
+	if depth > maxFingerprintDepth {
+		return false
+	}
+
+	switch typed := value.(type) {
+	case nil:
+		h.writeTag(tagNil)
+	case bool:
+		h.writeTag(tagBool)
+		if typed {
+			h.writeUint(1)
+		} else {
+			h.writeUint(0)
+		}
+	case float64:
+		// NaN is never deeply equal to itself, so it cannot be fingerprinted
+		if math.IsNaN(typed) {
+			return false
+		}
+		// Negative zero equals zero
+		if typed == 0 {
+			typed = 0
+		}
+		h.writeTag(tagFloat)
+		h.writeUint(math.Float64bits(typed))
+	case int:
+		h.writeTag(tagInt)
+		h.writeUint(uint64(typed))
+	case string:
+		h.writeTag(tagString)
+		h.writeString(typed)
+	case json.Number:
+		h.writeTag(tagNumber)
+		h.writeString(string(typed))
+	case map[string]interface{}:
+		if typed == nil {
+			h.writeTag(tagNilMap)
+			return true
+		}
+		h.writeTag(tagMap)
+		h.writeUint(uint64(len(typed)))
+		for _, k := range sortedMapKeys(typed) {
+			h.writeString(k)
+			if !h.write(typed[k], depth+1) {
+				return false
+			}
+		}
+	case []interface{}:
+		if typed == nil {
+			h.writeTag(tagNilList)
+			return true
+		}
+		h.writeTag(tagList)
+		h.writeUint(uint64(len(typed)))
+		for _, element := range typed {
+			if !h.write(element, depth+1) {
+				return false
+			}
+		}
+	case []map[string]interface{}:
+		if typed == nil {
+			h.writeTag(tagNilEntryList)
+			return true
+		}
+		h.writeTag(tagEntryList)
+		h.writeUint(uint64(len(typed)))
+		for _, element := range typed {
+			if !h.write(element, depth+1) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// writeTag hashes a type tag.
+func (h *fingerprintHasher) writeTag(tag byte) {
+	h.scratch[0] = tag
+	h.hash.Write(h.scratch[:1])
+}
+
+// writeUint hashes a fixed-width integer.
+func (h *fingerprintHasher) writeUint(v uint64) {
+	binary.LittleEndian.PutUint64(h.scratch[:], v)
+	h.hash.Write(h.scratch[:])
+}
+
+// writeString hashes a length-prefixed string.
+func (h *fingerprintHasher) writeString(s string) {
+	h.writeUint(uint64(len(s)))
+	h.buf = append(h.buf[:0], s...)
+	h.hash.Write(h.buf)
+}
+
+// sortedMapKeys returns the keys of a map in sorted order.
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
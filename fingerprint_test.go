@@ -0,0 +1,156 @@
+package comparison
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// widePlan returns a decoded plan with n resources carrying nested tags and blocks. The resource at
+// index changed has the given instance type, the others t3.micro.
+func widePlan(tb testing.TB, n, changed int, instanceType string) map[string]interface{} {
+	var sb strings.Builder
+	sb.WriteString(`{"resource_changes": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		value := "t3.micro"
+		if i == changed {
+			value = instanceType
+		}
+		fmt.Fprintf(&sb, `{"address": "aws_instance.web[%d]", "type": "aws_instance", "change": {"actions": ["update"], "after": {
+			"instance_type": %q, "ami": "ami-1", "tags": {"Name": "web-%d", "Team": "platform", "Env": "prod"},
+			"ebs_block_device": [{"device_name": "/dev/sdb", "volume_size": 100, "tags": {"Backup": "daily"}}],
+			"network_interface": [{"device_index": 0, "subnet_id": "subnet-1", "security_groups": ["sg-1", "sg-2"]}]
+		}}}`, i, value, i)
+	}
+	sb.WriteString(`]}`)
+
+	var plan map[string]interface{}
+	require.NoError(tb, json.Unmarshal([]byte(sb.String()), &plan))
+	return plan
+}
+
+func BenchmarkGeneratePlanDiff_ThousandsOfResources(b *testing.B) {
+	origPlan := widePlan(b, 5000, 2500, "t3.micro")
+	newPlan := widePlan(b, 5000, 2500, "t3.large")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generatePlanDiff(origPlan, newPlan, &CompareOptions{})
+	}
+}
+
+func TestSameResource_MatchesDeepEqual(t *testing.T) {
+	values := []interface{}{
+		nil,
+		true,
+		false,
+		0.0,
+		math.Copysign(0, -1),
+		1.0,
+		1,
+		"1",
+		json.Number("1"),
+		"",
+		map[string]interface{}(nil),
+		map[string]interface{}{},
+		map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"a": "1"},
+		map[string]interface{}{"a": 1.0, "b": nil},
+		map[string]interface{}{"ab": 1.0},
+		[]interface{}(nil),
+		[]interface{}{},
+		[]interface{}{1.0, 2.0},
+		[]interface{}{2.0, 1.0},
+		[]interface{}{[]interface{}{1.0}, 2.0},
+		[]interface{}{[]interface{}{1.0, 2.0}},
+		[]map[string]interface{}{{"a": 1.0}},
+		[]interface{}{map[string]interface{}{"a": 1.0}},
+		int64(1),
+	}
+
+	for i, a := range values {
+		for j, b := range values {
+			t.Run(fmt.Sprintf("%d-%d", i, j), func(t *testing.T) {
+				assert.Equal(t, reflect.DeepEqual(a, b), sameResource(a, b, &CompareOptions{}), "%#v and %#v", a, b)
+				assert.Equal(t, reflect.DeepEqual(a, b), sameResource(a, b, &CompareOptions{fingerprints: make(fingerprintCache)}), "%#v and %#v", a, b)
+			})
+		}
+	}
+}
+
+func TestFingerprintOf_Unsupported(t *testing.T) {
+	_, ok := fingerprintOf(map[string]interface{}{"nan": math.NaN()})
+	assert.False(t, ok)
+
+	_, ok = fingerprintOf([]interface{}{int64(1)})
+	assert.False(t, ok)
+
+	// Values that cannot be fingerprinted are still compared exactly
+	assert.False(t, sameResource(map[string]interface{}{"nan": math.NaN()}, map[string]interface{}{"nan": math.NaN()}, &CompareOptions{}))
+}
+
+func TestFingerprintCache(t *testing.T) {
+	cache := make(fingerprintCache)
+	resource := map[string]interface{}{"values": map[string]interface{}{"ami": "ami-1"}}
+
+	first, ok := cache.of(resource)
+	require.True(t, ok)
+	second, ok := cache.of(resource)
+	require.True(t, ok)
+	assert.Equal(t, first, second)
+	assert.Len(t, cache, 1)
+
+	copied, ok := cache.of(map[string]interface{}{"values": map[string]interface{}{"ami": "ami-1"}})
+	require.True(t, ok)
+	assert.Equal(t, first, copied)
+	assert.Len(t, cache, 2)
+}
+
+func TestGeneratePlanDiff_ThousandsOfResources(t *testing.T) {
+	diff, diffMap, hasDiff := generatePlanDiff(widePlan(t, 2000, 1500, "t3.micro"), widePlan(t, 2000, 1500, "t3.large"), &CompareOptions{})
+	assert.True(t, hasDiff)
+	assert.Contains(t, diff, "aws_instance.web[1500]\n  ~ instance_type: t3.micro => t3.large\n")
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web[1500]", Attribute: "instance_type"},
+	}, actualChanges(diffMap))
+
+	_, _, hasDiff = generatePlanDiff(widePlan(t, 2000, -1, ""), widePlan(t, 2000, -1, ""), &CompareOptions{})
+	assert.False(t, hasDiff)
+}
+
+func BenchmarkSameResources(b *testing.B) {
+	origResources := getResources(widePlan(b, 5000, -1, ""))
+	newResources := getResources(widePlan(b, 5000, -1, ""))
+
+	// Resources are compared as a whole section and then one by one, as compareResourceSections and
+	// processChangedResources do
+	b.Run("DeepEqual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if reflect.DeepEqual(origResources, newResources) {
+				for address, resource := range origResources {
+					_ = reflect.DeepEqual(resource, newResources[address])
+				}
+			}
+		}
+	})
+
+	b.Run("Fingerprint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			opts := &CompareOptions{fingerprints: make(fingerprintCache)}
+			if sameResources(origResources, newResources, opts) {
+				for address, resource := range origResources {
+					_ = sameResource(resource, newResources[address], opts)
+				}
+			}
+		}
+	})
+}
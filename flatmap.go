@@ -0,0 +1,121 @@
+package comparison
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatmap markers used by legacy attribute encodings: "tags.%" holds a map's size and "ingress.#" a list's length.
+const (
+	flatmapMapMarker  = "%"
+	flatmapListMarker = "#"
+)
+
+// isFlatmap reports whether attrs use the legacy flatmap encoding, detected by its size markers.
+func isFlatmap(attrs map[string]interface{}) bool {
+	for k := range attrs {
+		if strings.HasSuffix(k, "."+flatmapMapMarker) || strings.HasSuffix(k, "."+flatmapListMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatmapEncoded reports whether a resource's raw attributes use the flatmap encoding.
+func flatmapEncoded(resource interface{}) bool {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	raw := make(map[string]interface{})
+	extractValuesField(resMap, raw)
+	extractChangeAfterField(resMap, raw)
+	return isFlatmap(raw)
+}
+
+// expandFlatmap rebuilds nested maps and lists from flatmap-encoded attributes, e.g. "tags.%", "tags.Name",
+// "ingress.#" and "ingress.0.from_port". Leaf values are kept as encoded.
+func expandFlatmap(attrs map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	roots := make(map[string]bool)
+	for k, v := range attrs {
+		if idx := strings.Index(k, "."); idx != -1 {
+			roots[k[:idx]] = true
+			continue
+		}
+		result[k] = v
+	}
+
+	for root := range roots {
+		result[root] = expandFlatmapValue(attrs, root)
+	}
+
+	return result
+}
+
+// expandFlatmapValue rebuilds the value stored under prefix.
+func expandFlatmapValue(attrs map[string]interface{}, prefix string) interface{} {
+	if _, ok := attrs[prefix+"."+flatmapListMarker]; ok {
+		// Lists are indexed 0..n-1, but legacy sets use element hashes such as "ingress.2541.from_port",
+		// so the elements are the indices actually present, in numeric order
+		indices := flatmapChildren(attrs, prefix, flatmapListMarker)
+		sort.Slice(indices, func(i, j int) bool {
+			a, _ := strconv.Atoi(indices[i])
+			b, _ := strconv.Atoi(indices[j])
+			return a < b
+		})
+
+		list := make([]interface{}, len(indices))
+		for i, index := range indices {
+			list[i] = expandFlatmapValue(attrs, prefix+"."+index)
+		}
+		return list
+	}
+
+	if v, ok := attrs[prefix]; ok {
+		return v
+	}
+
+	// Maps, with or without a size marker, collect the children under the prefix
+	children := flatmapChildren(attrs, prefix, flatmapMapMarker)
+	result := make(map[string]interface{}, len(children))
+	for _, k := range children {
+		result[k] = expandFlatmapValue(attrs, prefix+"."+k)
+	}
+	return result
+}
+
+// flatmapChildren returns the distinct keys directly under prefix, leaving out the size marker.
+func flatmapChildren(attrs map[string]interface{}, prefix, marker string) []string {
+	seen := make(map[string]bool)
+	children := make([]string, 0)
+	for k := range attrs {
+		rest, ok := strings.CutPrefix(k, prefix+".")
+		if !ok || rest == marker {
+			continue
+		}
+		if idx := strings.Index(rest, "."); idx != -1 {
+			rest = rest[:idx]
+		}
+		if !seen[rest] {
+			seen[rest] = true
+			children = append(children, rest)
+		}
+	}
+	return children
+}
+
+// flatmapString returns a flatmap marker value as a string.
+func flatmapString(v interface{}) string {
+	switch typed := v.(type) {
+	case string:
+		return typed
+	case float64:
+		return strconv.Itoa(int(typed))
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,118 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandFlatmap(t *testing.T) {
+	flat := map[string]interface{}{
+		"id":                  "sg-1",
+		"tags.%":              "2",
+		"tags.Name":           "web",
+		"tags.Env":            "prod",
+		"ingress.#":           "2",
+		"ingress.0.from_port": "80",
+		"ingress.0.cidr.#":    "1",
+		"ingress.0.cidr.0":    "10.0.0.0/8",
+		"ingress.1.from_port": "443",
+		"ingress.1.cidr.#":    "0",
+	}
+
+	assert.True(t, isFlatmap(flat))
+	assert.Equal(t, map[string]interface{}{
+		"id":   "sg-1",
+		"tags": map[string]interface{}{"Name": "web", "Env": "prod"},
+		"ingress": []interface{}{
+			map[string]interface{}{"from_port": "80", "cidr": []interface{}{"10.0.0.0/8"}},
+			map[string]interface{}{"from_port": "443", "cidr": []interface{}{}},
+		},
+	}, expandFlatmap(flat))
+}
+
+func TestExpandFlatmap_HashedSetIndices(t *testing.T) {
+	flat := map[string]interface{}{
+		"ingress.#":              "2",
+		"ingress.2541.from_port": "80",
+		"ingress.2541.protocol":  "tcp",
+		"ingress.917.from_port":  "22",
+		"ingress.917.protocol":   "tcp",
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"ingress": []interface{}{
+			map[string]interface{}{"from_port": "22", "protocol": "tcp"},
+			map[string]interface{}{"from_port": "80", "protocol": "tcp"},
+		},
+	}, expandFlatmap(flat))
+}
+
+func TestCompareResources_FlatmapHashedSetChange(t *testing.T) {
+	resources := func(hash, port string) map[string]interface{} {
+		return map[string]interface{}{
+			"aws_security_group.web": map[string]interface{}{
+				"values": map[string]interface{}{
+					"ingress.#":                      "1",
+					"ingress." + hash + ".from_port": port,
+				},
+			},
+		}
+	}
+
+	diff, diffMap := compareResources(resources("2541", "80"), resources("3117", "443"), &CompareOptions{})
+	assert.Contains(t, diff, "aws_security_group.web\n")
+	assert.Contains(t, diff, "443")
+	assert.Len(t, entryList(diffMap["changed"]), 1)
+}
+
+func TestCompareResources_FlatmapMatchesNative(t *testing.T) {
+	flatRes := map[string]interface{}{
+		"aws_security_group.web": map[string]interface{}{
+			"values": map[string]interface{}{
+				"name":                "web",
+				"tags.%":              "1",
+				"tags.Name":           "web",
+				"ingress.#":           "1",
+				"ingress.0.from_port": "80",
+			},
+		},
+	}
+	nativeRes := func(tagName string) map[string]interface{} {
+		return map[string]interface{}{
+			"aws_security_group.web": map[string]interface{}{
+				"values": map[string]interface{}{
+					"name":    "web",
+					"tags":    map[string]interface{}{"Name": tagName},
+					"ingress": []interface{}{map[string]interface{}{"from_port": "80"}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		newRes     map[string]interface{}
+		expectDiff bool
+	}{
+		{name: "same content in both encodings", newRes: nativeRes("web"), expectDiff: false},
+		{name: "real change across encodings", newRes: nativeRes("api"), expectDiff: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _ := compareResources(flatRes, tc.newRes, &CompareOptions{})
+			if tc.expectDiff {
+				assert.Contains(t, diff, "aws_security_group.web")
+			} else {
+				assert.Empty(t, diff)
+			}
+		})
+	}
+}
+
+func TestFlatmapEncoded(t *testing.T) {
+	assert.True(t, flatmapEncoded(map[string]interface{}{"values": map[string]interface{}{"tags.%": "0"}}))
+	assert.False(t, flatmapEncoded(map[string]interface{}{"values": map[string]interface{}{"tags": map[string]interface{}{}}}))
+	assert.False(t, flatmapEncoded("not a resource"))
+}
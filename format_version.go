@@ -0,0 +1,25 @@
+package comparison
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// supportedFormatVersions are the plan JSON format versions StrictVersion accepts.
+var supportedFormatVersions = []string{"0.1", "0.2", "1.0", "1.1", "1.2"}
+
+// supportedFormatVersionSet holds supportedFormatVersions for lookups.
+var supportedFormatVersionSet = stringSet(supportedFormatVersions)
+
+// validateFormatVersion checks that a plan declares a supported format_version.
+func validateFormatVersion(plan map[string]interface{}) error {
+	version, ok := plan["format_version"].(string)
+	if !ok {
+		return errors.Wrap(ErrUnsupportedFormatVersion, "format_version is missing")
+	}
+	if !supportedFormatVersionSet[version] {
+		return errors.Wrapf(ErrUnsupportedFormatVersion, "format_version %q is not one of %s", version, strings.Join(supportedFormatVersions, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans_StrictVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		origVersion string
+		newVersion  string
+		opts        *CompareOptions
+		errContains string
+	}{
+		{name: "supported versions", origVersion: `"1.1"`, newVersion: `"1.2"`, opts: &CompareOptions{StrictVersion: true}},
+		{
+			name:        "unknown new version",
+			origVersion: `"1.2"`,
+			newVersion:  `"2.0"`,
+			opts:        &CompareOptions{StrictVersion: true},
+			errContains: `error validating new plan: format_version "2.0" is not one of 0.1, 0.2, 1.0, 1.1, 1.2: unsupported plan format version`,
+		},
+		{
+			name:        "missing original version",
+			newVersion:  `"1.2"`,
+			opts:        &CompareOptions{StrictVersion: true},
+			errContains: "error validating original plan: format_version is missing",
+		},
+		{
+			name:        "non-string version",
+			origVersion: `1.2`,
+			newVersion:  `"1.2"`,
+			opts:        &CompareOptions{StrictVersion: true},
+			errContains: "error validating original plan",
+		},
+		{name: "not strict", origVersion: `"9.9"`, opts: &CompareOptions{}},
+	}
+
+	plan := func(version string) string {
+		if version == "" {
+			return `{"variables": {"a": {"value": 1}}}`
+		}
+		return `{"format_version": ` + version + `, "variables": {"a": {"value": 1}}}`
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ComparePlans(plan(tc.origVersion), plan(tc.newVersion), tc.opts)
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrUnsupportedFormatVersion)
+			assert.ErrorContains(t, err, tc.errContains)
+
+			_, err = CompareStreaming(plan(tc.origVersion), strings.NewReader(plan(tc.newVersion)), tc.opts, func(StreamedChange) {})
+			assert.ErrorIs(t, err, ErrUnsupportedFormatVersion)
+		})
+	}
+}
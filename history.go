@@ -0,0 +1,51 @@
+package comparison
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HistoryEntry is a value an attribute took in a series of plans, with the plan it first appeared in.
+type HistoryEntry struct {
+	// Value is the attribute value, or nil when the resource or attribute is absent.
+	Value interface{}
+
+	// Timestamp is the plan's timestamp field. It is empty when the plan has none.
+	Timestamp string
+
+	// PlanIndex is the position of the plan in the series.
+	PlanIndex int
+}
+
+// AttributeHistory returns the sequence of values an attribute took across a series of plans, oldest
+// first. A new entry starts whenever the value differs from the previous plan, so the last entry tells
+// when the attribute last changed. The attribute may be a dot path such as "tags.Name".
+func AttributeHistory(plans []string, address, attribute string) ([]HistoryEntry, error) {
+	history := make([]HistoryEntry, 0)
+	keys := strings.Split(attribute, ".")
+	opts := &CompareOptions{}
+
+	for i, planJSON := range plans {
+		var plan map[string]interface{}
+		if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+			return nil, errors.Wrapf(err, "error parsing plan JSON at index %d", i)
+		}
+
+		var value interface{}
+		if resource, ok := getResources(plan)[address]; ok {
+			value, _ = lookupPath(getResourceAttributes(resource, opts), keys)
+		}
+
+		if len(history) > 0 && reflect.DeepEqual(history[len(history)-1].Value, value) {
+			continue
+		}
+
+		timestamp, _ := plan["timestamp"].(string)
+		history = append(history, HistoryEntry{Value: value, Timestamp: timestamp, PlanIndex: i})
+	}
+
+	return history, nil
+}
@@ -0,0 +1,70 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeHistory(t *testing.T) {
+	plan := func(timestamp, instanceType string) string {
+		return `{
+			"timestamp": "` + timestamp + `",
+			"resource_changes": [{
+				"address": "aws_instance.web",
+				"change": {"after": {"instance_type": "` + instanceType + `", "tags": {"Name": "web"}}}
+			}]
+		}`
+	}
+
+	plans := []string{
+		plan("2024-01-01T00:00:00Z", "t3.micro"),
+		plan("2024-01-02T00:00:00Z", "t3.micro"),
+		plan("2024-01-03T00:00:00Z", "t3.large"),
+		plan("2024-01-04T00:00:00Z", "t3.large"),
+	}
+
+	tests := []struct {
+		name      string
+		address   string
+		attribute string
+		expected  []HistoryEntry
+	}{
+		{
+			name:      "attribute changes at a known plan",
+			address:   "aws_instance.web",
+			attribute: "instance_type",
+			expected: []HistoryEntry{
+				{Value: "t3.micro", Timestamp: "2024-01-01T00:00:00Z", PlanIndex: 0},
+				{Value: "t3.large", Timestamp: "2024-01-03T00:00:00Z", PlanIndex: 2},
+			},
+		},
+		{
+			name:      "nested attribute never changes",
+			address:   "aws_instance.web",
+			attribute: "tags.Name",
+			expected:  []HistoryEntry{{Value: "web", Timestamp: "2024-01-01T00:00:00Z", PlanIndex: 0}},
+		},
+		{
+			name:      "missing resource",
+			address:   "aws_instance.db",
+			attribute: "instance_type",
+			expected:  []HistoryEntry{{Value: nil, Timestamp: "2024-01-01T00:00:00Z", PlanIndex: 0}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			history, err := AttributeHistory(plans, tc.address, tc.attribute)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, history)
+		})
+	}
+}
+
+func TestAttributeHistory_InvalidJSON(t *testing.T) {
+	_, err := AttributeHistory([]string{"{}", "not json"}, "aws_instance.web", "ami")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1")
+}
@@ -0,0 +1,204 @@
+package comparison
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlStyle is the inline stylesheet of the HTML report, so the page has no external assets.
+const htmlStyle = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+td.name { width: 20%; font-family: monospace; }
+td pre { margin: 0; white-space: pre-wrap; word-break: break-all; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: 0.5em 0; padding: 0.25em 0.75em; }
+summary { cursor: pointer; font-family: monospace; }
+.added { background: #e6ffec; }
+.removed { background: #ffebe9; }
+.changed { background: #fff8c5; }
+`
+
+// htmlRow is a row of a two-column table: a name with its old and new values and the kind of change.
+type htmlRow struct {
+	kind, name string
+	old, new   interface{}
+	hasOld     bool
+	hasNew     bool
+}
+
+// RenderHTML renders a diff map as a self-contained HTML page for sharing with people who do not read
+// plan diffs: a header with the change counts of summary, then every change with its old and new values
+// side by side. Each resource is a collapsible section, and added, removed and changed values are color
+// coded with inline CSS. The page loads no external assets and uses no JavaScript.
+func RenderHTML(diffMap map[string]interface{}, summary DiffSummary) []byte {
+	opts := resolveOptions(nil)
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>Terraform plan diff</title>\n<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n")
+	sb.WriteString("<h1>Terraform plan diff</h1>\n")
+	sb.WriteString(htmlSummary(diffMap, summary))
+
+	for _, section := range []struct {
+		name, title string
+		resources   bool
+	}{
+		{"variables", "Variables", false},
+		{"resources", "Resources", true},
+		{"tag_changes", "Tag changes", true},
+		{"drift", "Drift", true},
+		{"outputs", "Outputs", false},
+	} {
+		categories, _ := diffMap[section.name].(map[string]interface{})
+
+		var body string
+		if section.resources {
+			body = htmlResourceChanges(categories, opts)
+		} else {
+			body = htmlTable(htmlValueRows(categories), opts)
+		}
+		if body != "" {
+			sb.WriteString(fmt.Sprintf("<h2>%s</h2>\n%s", section.title, body))
+		}
+	}
+
+	upgrades := make([]htmlRow, 0)
+	for _, entry := range entryList(diffMap["provider_upgrades"]) {
+		upgrades = append(upgrades, htmlRow{kind: "changed", name: entryKey(entry), old: entry["old_version"], new: entry["new_version"], hasOld: true, hasNew: true})
+	}
+	if len(upgrades) > 0 {
+		sb.WriteString("<h2>Provider upgrades</h2>\n" + htmlTable(upgrades, opts))
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return []byte(sb.String())
+}
+
+// htmlSummary renders the summary line and a table with the change counts of the non-empty sections.
+func htmlSummary(diffMap map[string]interface{}, summary DiffSummary) string {
+	rows := []struct {
+		title  string
+		counts SectionCounts
+	}{
+		{"Resources", summary.Resources},
+		{"Variables", summary.Variables},
+		{"Outputs", summary.Outputs},
+		{"Drift", summary.Drift},
+		{"Tag changes", summary.Tags},
+		{"Provider upgrades", SectionCounts{Changed: len(entryList(diffMap["provider_upgrades"]))}},
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		if row.counts == (SectionCounts{}) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td class=\"added\">%d</td><td class=\"removed\">%d</td><td class=\"changed\">%d</td></tr>\n",
+			row.title, row.counts.Added, row.counts.Removed, row.counts.Changed))
+	}
+
+	if sb.Len() == 0 {
+		return "<p>No changes.</p>\n"
+	}
+	return fmt.Sprintf("<p>%s</p>\n<table class=\"summary\">\n<tr><th>Section</th><th>Added</th><th>Removed</th><th>Changed</th></tr>\n%s</table>\n",
+		html.EscapeString(summary.String()), sb.String())
+}
+
+// htmlValueRows returns the rows of the entries of a variables or outputs section.
+func htmlValueRows(categories map[string]interface{}) []htmlRow {
+	rows := make([]htmlRow, 0)
+	for _, entry := range entryList(categories["added"]) {
+		rows = append(rows, htmlRow{kind: "added", name: entryKey(entry), new: entry["value"], hasNew: true})
+	}
+	for _, entry := range entryList(categories["removed"]) {
+		rows = append(rows, htmlRow{kind: "removed", name: entryKey(entry), old: entry["value"], hasOld: true})
+	}
+	for _, entry := range entryList(categories["changed"]) {
+		rows = append(rows, htmlRow{kind: "changed", name: entryKey(entry), old: entry["old"], new: entry["new"], hasOld: true, hasNew: true})
+	}
+	return rows
+}
+
+// htmlResourceChanges renders every entry of a resources section as a collapsible section.
+func htmlResourceChanges(categories map[string]interface{}, opts *CompareOptions) string {
+	var sb strings.Builder
+	for _, entry := range entryList(categories["count_changed"]) {
+		rows := []htmlRow{{kind: "changed", name: "count", old: entry["old_count"], new: entry["new_count"], hasOld: true, hasNew: true}}
+		sb.WriteString(htmlDetails("changed", "~ "+entryKey(entry), rows, opts))
+	}
+	for _, entry := range entryList(categories["added"]) {
+		sb.WriteString(htmlDetails("added", "+ "+entryKey(entry), htmlAttributeRows("added", entry["value"], opts), opts))
+	}
+	for _, entry := range entryList(categories["removed"]) {
+		sb.WriteString(htmlDetails("removed", "- "+entryKey(entry), htmlAttributeRows("removed", entry["value"], opts), opts))
+	}
+	for _, entry := range entryList(categories["moved"]) {
+		sb.WriteString(htmlDetails("changed", "~ "+entryKey(entry)+" (moved)", htmlAttributeChanges(entry), opts))
+	}
+	for _, entry := range entryList(categories["mode_changed"]) {
+		rows := []htmlRow{{kind: "changed", name: "mode", old: entry["old_mode"], new: entry["new_mode"], hasOld: true, hasNew: true}}
+		sb.WriteString(htmlDetails("changed", "! "+entryKey(entry), rows, opts))
+	}
+	for _, entry := range entryList(categories["changed"]) {
+		sb.WriteString(htmlDetails("changed", "~ "+entryKey(entry), htmlAttributeChanges(entry), opts))
+	}
+	return sb.String()
+}
+
+// htmlAttributeChanges returns the rows of the attribute changes of a changed or moved resource entry.
+func htmlAttributeChanges(entry map[string]interface{}) []htmlRow {
+	attrs, _ := entry["attributes"].(map[string]interface{})
+	return htmlValueRows(attrs)
+}
+
+// htmlAttributeRows returns a row for every attribute of an added or removed resource.
+func htmlAttributeRows(kind string, resource interface{}, opts *CompareOptions) []htmlRow {
+	attrs := getResourceAttributes(resource, opts)
+	rows := make([]htmlRow, 0, len(attrs))
+	for _, name := range getSortedKeys(attrs, nil) {
+		row := htmlRow{kind: kind, name: name}
+		if kind == "added" {
+			row.new, row.hasNew = attrs[name], true
+		} else {
+			row.old, row.hasOld = attrs[name], true
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// htmlDetails renders a collapsible section with a summary line and a table of its rows, if there are any.
+func htmlDetails(kind, summary string, rows []htmlRow, opts *CompareOptions) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<details>\n<summary class=\"%s\">%s</summary>\n", kind, html.EscapeString(summary)))
+	sb.WriteString(htmlTable(rows, opts))
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
+
+// htmlTable renders rows as a table with the old and new values in two columns. It renders nothing
+// without rows.
+func htmlTable(rows []htmlRow, opts *CompareOptions) string {
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n<tr><th>Name</th><th>Old</th><th>New</th></tr>\n")
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("<tr class=\"%s\"><td class=\"name\">%s</td><td>%s</td><td>%s</td></tr>\n",
+			row.kind, html.EscapeString(row.name), htmlValue(row.old, row.hasOld, opts), htmlValue(row.new, row.hasNew, opts)))
+	}
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
+
+// htmlValue renders a value as preformatted text, or nothing if there is no value.
+func htmlValue(value interface{}, ok bool, opts *CompareOptions) string {
+	if !ok {
+		return ""
+	}
+	return "<pre>" + html.EscapeString(formatValue(value, opts)) + "</pre>"
+}
@@ -0,0 +1,66 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTML(t *testing.T) {
+	origJSON := `{
+		"variables": {"stage": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		]
+	}`
+	newJSON := `{
+		"variables": {"stage": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large"}}},
+			{"address": "aws_s3_bucket.new[\"a\"]", "change": {"after": {"bucket": "<new>"}}}
+		]
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, nil)
+	require.NoError(t, err)
+	page := string(RenderHTML(result.Changes, SummarizeDiff(result.Changes)))
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>\n"))
+	assert.True(t, strings.HasSuffix(page, "</body>\n</html>\n"))
+	assert.Contains(t, page, "<p>Resources: +1 -1 ~1, Variables: +0 -0 ~1, Outputs: +0 -0 ~0</p>\n")
+	assert.Contains(t, page, `<tr><td>Resources</td><td class="added">1</td><td class="removed">1</td><td class="changed">1</td></tr>`)
+	assert.Contains(t, page, "<h2>Variables</h2>\n<table>\n<tr><th>Name</th><th>Old</th><th>New</th></tr>\n"+
+		`<tr class="changed"><td class="name">stage</td><td><pre>dev</pre></td><td><pre>prod</pre></td></tr>`)
+	assert.Contains(t, page, "<details>\n<summary class=\"added\">+ aws_s3_bucket.new[&#34;a&#34;]</summary>\n")
+	assert.Contains(t, page, `<tr class="added"><td class="name">bucket</td><td></td><td><pre>&lt;new&gt;</pre></td></tr>`)
+	assert.Contains(t, page, `<tr class="removed"><td class="name">bucket</td><td><pre>old</pre></td><td></td></tr>`)
+	assert.Contains(t, page, "<summary class=\"changed\">~ aws_instance.web</summary>\n")
+	assert.Contains(t, page, `<tr class="changed"><td class="name">instance_type</td><td><pre>t3.micro</pre></td><td><pre>t3.large</pre></td></tr>`)
+	assert.NotContains(t, page, "<h2>Outputs</h2>")
+
+	// The page is self-contained
+	assert.NotContains(t, page, "<script")
+	assert.NotContains(t, page, "<link")
+	assert.NotContains(t, page, "src=")
+}
+
+func TestRenderHTML_NoChanges(t *testing.T) {
+	page := string(RenderHTML(map[string]interface{}{}, DiffSummary{}))
+	assert.Contains(t, page, "<h1>Terraform plan diff</h1>\n<p>No changes.</p>\n</body>")
+}
+
+func TestRenderHTML_TagChanges(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "a"}}}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "b"}}}}]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{SeparateTagChanges: true})
+	require.NoError(t, err)
+	page := string(RenderHTML(result.Changes, SummarizeDiff(result.Changes)))
+
+	assert.Contains(t, page, "<h2>Tag changes</h2>\n")
+	assert.Contains(t, page, "<summary class=\"changed\">~ aws_s3_bucket.logs</summary>\n")
+	assert.Contains(t, page, `<tr class="changed"><td class="name">tags</td><td><pre>{Owner: a}</pre></td><td><pre>{Owner: b}</pre></td></tr>`)
+}
@@ -0,0 +1,158 @@
+package comparison
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ignoreAddressPrefix marks an ignore file rule matching resource addresses.
+	ignoreAddressPrefix = "address:"
+
+	// ignoreAttributePrefix marks an ignore file rule matching attribute names.
+	ignoreAttributePrefix = "attr:"
+
+	// ignoreCommentPrefix starts a comment line in an ignore file.
+	ignoreCommentPrefix = "#"
+)
+
+// ErrInvalidIgnoreRule is returned when a line of an ignore file is not a valid rule.
+var ErrInvalidIgnoreRule = errors.New("invalid ignore rule")
+
+// IgnoreSet lists resources and attributes to leave out of a comparison, e.g. loaded from a
+// .tfcompareignore file committed with the configuration. A `*` in a pattern matches any run of characters.
+type IgnoreSet struct {
+	// Addresses are patterns of resource addresses to ignore, e.g. `module.legacy.*`.
+	Addresses []string
+
+	// Attributes are patterns of top-level attribute names to ignore on every resource, e.g. `tags_all`
+	// or `*_md5`.
+	Attributes []string
+}
+
+// LoadIgnoreFile reads an ignore file. Every line is a rule: `address:<pattern>` ignores the resources
+// whose address matches and `attr:<pattern>` the attributes whose name matches. Blank lines and lines
+// starting with `#` are skipped.
+//
+//	# Resources managed by another team
+//	address:module.shared.*
+//	attr:tags_all
+func LoadIgnoreFile(path string) (*IgnoreSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading ignore file %q", path)
+	}
+
+	ignore, err := parseIgnoreRules(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing ignore file %q", path)
+	}
+	return ignore, nil
+}
+
+// parseIgnoreRules parses the rules of an ignore file.
+func parseIgnoreRules(data string) (*IgnoreSet, error) {
+	ignore := &IgnoreSet{Addresses: make([]string, 0), Attributes: make([]string, 0)}
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ignoreCommentPrefix) {
+			continue
+		}
+
+		var pattern string
+		var patterns *[]string
+		switch {
+		case strings.HasPrefix(line, ignoreAddressPrefix):
+			pattern, patterns = strings.TrimPrefix(line, ignoreAddressPrefix), &ignore.Addresses
+		case strings.HasPrefix(line, ignoreAttributePrefix):
+			pattern, patterns = strings.TrimPrefix(line, ignoreAttributePrefix), &ignore.Attributes
+		default:
+			return nil, errors.Wrapf(ErrInvalidIgnoreRule, "line %d: %q does not start with %q or %q", i+1, line, ignoreAddressPrefix, ignoreAttributePrefix)
+		}
+
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, errors.Wrapf(ErrInvalidIgnoreRule, "line %d: %q has no pattern", i+1, line)
+		}
+		*patterns = append(*patterns, pattern)
+	}
+
+	return ignore, nil
+}
+
+// ignoreResources leaves the resources matching an ignored address pattern out of both resource sets and
+// counts the changes the ignore set hides: every ignored resource that was added, removed or changed, and
+// every ignored attribute that changed on the remaining resources.
+func ignoreResources(origResources, newResources map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}, int) {
+	if len(opts.ignoredAddresses) == 0 && len(opts.ignoredAttributes) == 0 {
+		return origResources, newResources, 0
+	}
+
+	ignored := 0
+	remainingOrig, remainingNew := copyEntry(origResources), copyEntry(newResources)
+	for _, address := range getSortedKeys(origResources, newResources) {
+		origV, origExists := origResources[address]
+		newV, newExists := newResources[address]
+
+		if matchesAnyPattern(address, opts.ignoredAddresses) {
+			delete(remainingOrig, address)
+			delete(remainingNew, address)
+			if !origExists || !newExists || !sameResource(origV, newV, opts) {
+				ignored++
+			}
+			continue
+		}
+
+		if origExists && newExists && !sameResource(origV, newV, opts) {
+			_, _, changed := dropIgnoredAttributes(getComparedAttributes(origV, opts), getComparedAttributes(newV, opts), opts)
+			ignored += changed
+		}
+	}
+
+	return remainingOrig, remainingNew, ignored
+}
+
+// dropIgnoredAttributes returns copies of a resource's attribute sets without the attributes matching an
+// ignored attribute pattern, and the number of dropped attributes whose value changed.
+func dropIgnoredAttributes(origAttrs, newAttrs map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}, int) {
+	if len(opts.ignoredAttributes) == 0 {
+		return origAttrs, newAttrs, 0
+	}
+
+	changed := 0
+	remainingOrig, remainingNew := copyEntry(origAttrs), copyEntry(newAttrs)
+	for _, name := range getSortedKeys(origAttrs, newAttrs) {
+		if !matchesAnyPattern(name, opts.ignoredAttributes) {
+			continue
+		}
+
+		origV, origExists := origAttrs[name]
+		newV, newExists := newAttrs[name]
+		if origExists != newExists || !valuesEqual(origV, newV, opts) {
+			changed++
+		}
+		delete(remainingOrig, name)
+		delete(remainingNew, name)
+	}
+
+	return remainingOrig, remainingNew, changed
+}
+
+// takeIgnoredCount removes the count of ignored changes from a section's diff map and returns it.
+func takeIgnoredCount(sectionMap map[string]interface{}) int {
+	ignored, _ := sectionMap["ignored"].(int)
+	delete(sectionMap, "ignored")
+	return ignored
+}
+
+// compileIgnoreSet compiles the address and attribute patterns of an ignore set.
+func compileIgnoreSet(ignore *IgnoreSet) ([]*regexp.Regexp, []*regexp.Regexp) {
+	if ignore == nil {
+		return nil, nil
+	}
+	return compileAddressPatterns(ignore.Addresses), compileAddressPatterns(ignore.Attributes)
+}
@@ -0,0 +1,128 @@
+package comparison
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIgnoreRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expected    *IgnoreSet
+		expectedErr string
+	}{
+		{
+			name:     "empty file",
+			data:     "",
+			expected: &IgnoreSet{Addresses: []string{}, Attributes: []string{}},
+		},
+		{
+			name: "comments, blank lines and globs",
+			data: "# Managed by another team\n\naddress:module.shared.*\n  attr: tags_all \nattr:*_md5\n",
+			expected: &IgnoreSet{
+				Addresses:  []string{"module.shared.*"},
+				Attributes: []string{"tags_all", "*_md5"},
+			},
+		},
+		{
+			name:        "rule without prefix",
+			data:        "# comment\naws_instance.web\n",
+			expectedErr: `line 2: "aws_instance.web" does not start with "address:" or "attr:": invalid ignore rule`,
+		},
+		{
+			name:        "rule without pattern",
+			data:        "attr:\n",
+			expectedErr: `line 1: "attr:" has no pattern: invalid ignore rule`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignore, err := parseIgnoreRules(tt.data)
+			if tt.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrInvalidIgnoreRule)
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ignore)
+		})
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tfcompareignore")
+	require.NoError(t, os.WriteFile(path, []byte("address:aws_instance.legacy\nattr:tags_all\n"), 0o600))
+
+	ignore, err := LoadIgnoreFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, &IgnoreSet{Addresses: []string{"aws_instance.legacy"}, Attributes: []string{"tags_all"}}, ignore)
+
+	_, err = LoadIgnoreFile(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "error reading ignore file")
+
+	require.NoError(t, os.WriteFile(path, []byte("tags_all\n"), 0o600))
+	_, err = LoadIgnoreFile(path)
+	assert.ErrorIs(t, err, ErrInvalidIgnoreRule)
+	assert.ErrorContains(t, err, "error parsing ignore file")
+}
+
+func TestComparePlans_Ignore(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "tags_all": {"Owner": "a"}}}},
+		{"address": "aws_instance.legacy", "change": {"after": {"ami": "ami-1"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "tags_all": {"Owner": "b"}}}},
+		{"address": "aws_instance.legacy", "change": {"after": {"ami": "ami-2"}}}
+	]}`
+	ignore := &IgnoreSet{Addresses: []string{"aws_instance.leg*"}, Attributes: []string{"tags_*"}}
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{Ignore: ignore})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "Resources: +0 -0 ~1, Variables: +0 -0 ~0, Outputs: +0 -0 ~0, 2 ignored\n")
+	assert.NotContains(t, result.Text, "aws_instance.legacy")
+	assert.NotContains(t, result.Text, "tags_all")
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+	}, actualChanges(result.Changes))
+	assert.Equal(t, 2, result.Changes["ignored"])
+
+	encoded, err := json.Marshal(result.Changes)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, 2, SummarizeDiff(decoded).Ignored)
+
+	// The count is kept when tag-only changes are separated
+	result, err = ComparePlans(
+		`{"resource_changes": [
+			{"address": "aws_instance.legacy", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "a"}}}}
+		]}`,
+		`{"resource_changes": [
+			{"address": "aws_instance.legacy", "change": {"after": {"ami": "ami-2"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "b"}}}}
+		]}`,
+		&CompareOptions{Ignore: ignore, SeparateTagChanges: true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Changes, "tag_changes")
+	assert.Equal(t, 1, result.Changes["ignored"])
+
+	// Only ignored changes leave nothing to report
+	newJSON = `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "tags_all": {"Owner": "b"}}}},
+		{"address": "aws_instance.legacy", "change": {"after": {"ami": "ami-2"}}}
+	]}`
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{Ignore: ignore})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.NotContains(t, result.Changes, "resources")
+}
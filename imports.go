@@ -0,0 +1,35 @@
+package comparison
+
+// importMarker marks resources that the plan imports in the text diff.
+const importMarker = "±"
+
+// importID returns the import ID of a resource_changes entry whose change carries importing metadata.
+// The ID is empty for imports identified by something other than an ID.
+func importID(resource interface{}) (string, bool) {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	change, ok := resMap["change"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	importing, ok := change["importing"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	id, _ := importing["id"].(string)
+	return id, true
+}
+
+// tagImport marks a diff map entry as imported, with its import ID, when the resource is being imported.
+func tagImport(entry map[string]interface{}, resource interface{}) map[string]interface{} {
+	if id, ok := importID(resource); ok {
+		entry["imported"] = true
+		entry["import_id"] = id
+	}
+	return entry
+}
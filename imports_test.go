@@ -0,0 +1,63 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans_Imports(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"actions": ["update"], "after": {"ami": "ami-1"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"actions": ["update"], "after": {"ami": "ami-2"}, "importing": {"id": "i-0abc"}}},
+		{"address": "aws_s3_bucket.logs", "change": {"actions": ["no-op"], "after": {"bucket": "logs"}, "importing": {"id": "logs"}}},
+		{"address": "aws_s3_bucket.new", "change": {"actions": ["create"], "after": {"bucket": "new"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "± aws_s3_bucket.logs (import)\n")
+	assert.Contains(t, result.Text, "+ aws_s3_bucket.new\n")
+	assert.NotContains(t, result.Text, "+ aws_s3_bucket.logs")
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	added := resources["added"].([]map[string]interface{})
+	require.Len(t, added, 2)
+	assert.Equal(t, true, added[0]["imported"])
+	assert.Equal(t, "logs", added[0]["import_id"])
+	assert.NotContains(t, added[1], "imported")
+
+	changed := resources["changed"].([]map[string]interface{})
+	require.Len(t, changed, 1)
+	assert.Equal(t, "i-0abc", changed[0]["import_id"])
+
+	// Imports are planned changes even when their actions are a no-op
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{PlannedOnly: true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "± aws_s3_bucket.logs (import)\n")
+}
+
+func TestImportID(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource interface{}
+		id       string
+		ok       bool
+	}{
+		{name: "import", resource: map[string]interface{}{"change": map[string]interface{}{"importing": map[string]interface{}{"id": "i-1"}}}, id: "i-1", ok: true},
+		{name: "import without id", resource: map[string]interface{}{"change": map[string]interface{}{"importing": map[string]interface{}{}}}, ok: true},
+		{name: "create", resource: map[string]interface{}{"change": map[string]interface{}{"actions": []interface{}{"create"}}}},
+		{name: "state resource", resource: map[string]interface{}{"values": map[string]interface{}{}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id, ok := importID(tc.resource)
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// InputFormat is the encoding of a plan document.
+type InputFormat string
+
+const (
+	// InputFormatAuto detects the encoding from the document's first byte.
+	InputFormatAuto InputFormat = ""
+
+	// InputFormatJSON is the output of `terraform show -json`.
+	InputFormatJSON InputFormat = "json"
+
+	// InputFormatMsgpack is the same document encoded as MessagePack.
+	InputFormatMsgpack InputFormat = "msgpack"
+)
+
+// ErrUnsupportedInputFormat is returned when a plan is decoded with an unknown input format.
+var ErrUnsupportedInputFormat = errors.New("unsupported plan input format")
+
+// decodePlan decodes a plan document into the map shape produced by encoding/json.
+func decodePlan(input []byte, format InputFormat) (map[string]interface{}, error) {
+	if format == InputFormatAuto {
+		format = detectInputFormat(input)
+	}
+
+	switch format {
+	case InputFormatJSON:
+		var plan map[string]interface{}
+		if err := json.Unmarshal(input, &plan); err != nil {
+			return nil, describeJSONError(input, err)
+		}
+		return plan, nil
+	case InputFormatMsgpack:
+		var decoded interface{}
+		if err := msgpack.Unmarshal(input, &decoded); err != nil {
+			return nil, err
+		}
+
+		plan, ok := normalizeMsgpackValue(decoded).(map[string]interface{})
+		if !ok {
+			return nil, errors.New("msgpack plan is not a map")
+		}
+		return plan, nil
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedInputFormat, "%q", format)
+	}
+}
+
+// detectInputFormat guesses the encoding of a plan document. A MessagePack map starts with a
+// fixmap, map16 or map32 marker; anything else is treated as JSON.
+func detectInputFormat(input []byte) InputFormat {
+	trimmed := bytes.TrimLeft(input, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] == '{' {
+		return InputFormatJSON
+	}
+
+	if first := trimmed[0]; first&0xf0 == 0x80 || first == 0xde || first == 0xdf {
+		return InputFormatMsgpack
+	}
+
+	return InputFormatJSON
+}
+
+// normalizeMsgpackValue converts a decoded MessagePack value to the types encoding/json produces:
+// numbers become float64, binary becomes string and maps are keyed by string.
+func normalizeMsgpackValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeMsgpackValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[fmt.Sprint(k)] = normalizeMsgpackValue(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeMsgpackValue(item)
+		}
+		return v
+	case []byte:
+		return string(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,68 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func toMsgpack(t *testing.T, planJSON string) string {
+	t.Helper()
+
+	var plan map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(planJSON), &plan))
+
+	encoded, err := msgpack.Marshal(plan)
+	require.NoError(t, err)
+	return string(encoded)
+}
+
+func TestComparePlans_Msgpack(t *testing.T) {
+	origJSON := `{
+		"variables": {"count": {"value": 2}},
+		"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro", "cpu": 2}}}]
+	}`
+	newJSON := `{
+		"variables": {"count": {"value": 3}},
+		"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro", "cpu": 4.5}}}]
+	}`
+
+	expected, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	require.True(t, expected.HasDiff)
+
+	tests := []struct {
+		name   string
+		format InputFormat
+	}{
+		{name: "explicit format", format: InputFormatMsgpack},
+		{name: "auto-detected", format: InputFormatAuto},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ComparePlans(toMsgpack(t, origJSON), toMsgpack(t, newJSON), &CompareOptions{InputFormat: tc.format})
+			require.NoError(t, err)
+			assert.Equal(t, expected.Text, actual.Text)
+			assert.Equal(t, expected.Changes, actual.Changes)
+			assert.Equal(t, expected.HasDiff, actual.HasDiff)
+		})
+	}
+}
+
+func TestDecodePlan_Errors(t *testing.T) {
+	_, err := decodePlan([]byte("{}"), InputFormat("yaml"))
+	require.ErrorIs(t, err, ErrUnsupportedInputFormat)
+
+	_, err = decodePlan([]byte("not json"), InputFormatAuto)
+	require.Error(t, err)
+}
+
+func TestDetectInputFormat(t *testing.T) {
+	assert.Equal(t, InputFormatJSON, detectInputFormat([]byte("  {\"a\": 1}")))
+	assert.Equal(t, InputFormatMsgpack, detectInputFormat([]byte(toMsgpack(t, `{"a": 1}`))))
+	assert.Equal(t, InputFormatJSON, detectInputFormat(nil))
+}
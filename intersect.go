@@ -0,0 +1,140 @@
+package comparison
+
+import "sort"
+
+// IntersectDiffs returns the changes present in both diff maps, e.g. to find resources and attributes
+// touched by two concurrent changes. Entries match on their section, category (added/removed/changed)
+// and name or address; changed resources additionally need at least one attribute changed in both.
+// Noise and normalized-away entries are not changes and never intersect. The returned entries are taken
+// from a. Sections without common changes are omitted.
+func IntersectDiffs(a, b map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for section, aSection := range a {
+		bSection, exists := b[section]
+		if !exists {
+			continue
+		}
+
+		switch aTyped := aSection.(type) {
+		case map[string]interface{}:
+			bTyped, ok := bSection.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if common := intersectCategories(aTyped, bTyped); len(common) > 0 {
+				result[section] = common
+			}
+		default:
+			if common := intersectEntries(aSection, bSection); len(common) > 0 {
+				result[section] = common
+			}
+		}
+	}
+
+	return result
+}
+
+// intersectCategories intersects two section maps category by category.
+func intersectCategories(a, b map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	categories := make([]string, 0, len(a))
+	for category := range a {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		if setAsideCategories[category] {
+			continue
+		}
+		if common := intersectEntries(a[category], b[category]); len(common) > 0 {
+			result[category] = common
+		}
+	}
+
+	return result
+}
+
+// intersectEntries returns the entries of a that have a matching entry in b.
+func intersectEntries(a, b interface{}) []map[string]interface{} {
+	bEntries := make(map[string]map[string]interface{})
+	for _, entry := range entryList(b) {
+		bEntries[entryKey(entry)] = entry
+	}
+
+	common := make([]map[string]interface{}, 0)
+	for _, entry := range entryList(a) {
+		other, exists := bEntries[entryKey(entry)]
+		if !exists {
+			continue
+		}
+
+		// Changed resources only intersect on the attributes both sides touched
+		if attrs, ok := entry["attributes"].(map[string]interface{}); ok {
+			otherAttrs, _ := other["attributes"].(map[string]interface{})
+			sharedAttrs := intersectCategories(attrs, otherAttrs)
+			if len(sharedAttrs) == 0 {
+				continue
+			}
+
+			entry = copyEntry(entry)
+			entry["attributes"] = sharedAttrs
+		}
+
+		common = append(common, entry)
+	}
+
+	return common
+}
+
+// entryList converts a diff map category to a list of entries. It accepts both the in-memory
+// []map[string]interface{} form and the []interface{} form produced by decoding JSON.
+func entryList(v interface{}) []map[string]interface{} {
+	switch typed := v.(type) {
+	case []map[string]interface{}:
+		return typed
+	case []interface{}:
+		entries := make([]map[string]interface{}, 0, len(typed))
+		for _, item := range typed {
+			if entry, ok := item.(map[string]interface{}); ok {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	default:
+		return nil
+	}
+}
+
+// entryKey returns the identity of a diff map entry.
+func entryKey(entry map[string]interface{}) string {
+	for _, key := range []string{"address", "name", "provider"} {
+		if v, ok := entry[key].(string); ok {
+			return v
+		}
+	}
+
+	oldAddress, _ := entry["old_address"].(string)
+	newAddress, _ := entry["new_address"].(string)
+	return oldAddress + " => " + newAddress
+}
+
+// entryAddress returns the address of a resource entry in the new plan, which is the new address of a moved
+// resource.
+func entryAddress(entry map[string]interface{}) string {
+	if address, ok := entry["new_address"].(string); ok {
+		return address
+	}
+	return entryKey(entry)
+}
+
+// copyEntry returns a shallow copy of a diff map entry.
+func copyEntry(entry map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(entry))
+	for k, v := range entry {
+		result[k] = v
+	}
+	return result
+}
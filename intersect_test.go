@@ -0,0 +1,84 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntersectDiffs(t *testing.T) {
+	a := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"added":   []map[string]interface{}{{"name": "stage", "value": "dev"}},
+			"removed": []map[string]interface{}{},
+			"changed": []map[string]interface{}{{"name": "region", "old": "a", "new": "b"}},
+		},
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{"address": "aws_s3_bucket.logs"}},
+			"changed": []map[string]interface{}{
+				{
+					"address": "aws_instance.web",
+					"attributes": map[string]interface{}{
+						"changed": []map[string]interface{}{
+							{"name": "ami", "old": "ami-1", "new": "ami-2"},
+							{"name": "instance_type", "old": "t3.micro", "new": "t3.large"},
+						},
+					},
+				},
+				{
+					"address": "aws_instance.api",
+					"attributes": map[string]interface{}{
+						"changed": []map[string]interface{}{{"name": "ami", "old": "ami-1", "new": "ami-2"}},
+					},
+				},
+			},
+			"noise": []map[string]interface{}{{"address": "aws_s3_object.site", "name": "etag", "old": "a", "new": "b"}},
+		},
+	}
+	b := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "stage", "old": "dev", "new": "prod"}},
+		},
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{"address": "aws_s3_bucket.logs"}},
+			"changed": []map[string]interface{}{
+				{
+					"address": "aws_instance.web",
+					"attributes": map[string]interface{}{
+						"changed": []map[string]interface{}{{"name": "instance_type", "old": "t3.micro", "new": "t3.xlarge"}},
+					},
+				},
+				{
+					"address": "aws_instance.api",
+					"attributes": map[string]interface{}{
+						"changed": []map[string]interface{}{{"name": "tags", "old": "a", "new": "b"}},
+					},
+				},
+			},
+			"noise": []map[string]interface{}{{"address": "aws_s3_object.site", "name": "etag", "old": "a", "new": "c"}},
+		},
+	}
+
+	result := IntersectDiffs(a, b)
+
+	assert.NotContains(t, result, "variables")
+	assert.Equal(t, map[string]interface{}{
+		"added": []map[string]interface{}{{"address": "aws_s3_bucket.logs"}},
+		"changed": []map[string]interface{}{{
+			"address": "aws_instance.web",
+			"attributes": map[string]interface{}{
+				"changed": []map[string]interface{}{{"name": "instance_type", "old": "t3.micro", "new": "t3.large"}},
+			},
+		}},
+	}, result["resources"])
+}
+
+func TestIntersectDiffs_DecodedJSON(t *testing.T) {
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"outputs": {"changed": [{"name": "url", "old": "a", "new": "b"}]}}`), &decoded))
+
+	result := IntersectDiffs(decoded, decoded)
+	assert.Len(t, result["outputs"].(map[string]interface{})["changed"], 1)
+}
@@ -0,0 +1,70 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// describeJSONError adds to a JSON decoding error of a plan document the byte offset at which decoding
+// failed and the top-level keys that decode before it, so a truncated or corrupted plan can be located and
+// its usable sections seen. The cause is kept, so callers can still inspect the underlying json error.
+func describeJSONError(input []byte, err error) error {
+	offset, ok := jsonErrorOffset(err)
+	if !ok {
+		return err
+	}
+
+	keys := recoverableTopLevelKeys(input)
+	if len(keys) == 0 {
+		return errors.Wrapf(err, "invalid JSON at byte offset %d (no recoverable top-level keys)", offset)
+	}
+	return errors.Wrapf(err, "invalid JSON at byte offset %d (recoverable top-level keys: %s)", offset, strings.Join(keys, ", "))
+}
+
+// jsonErrorOffset returns the byte offset of a syntax or type error returned by encoding/json.
+func jsonErrorOffset(err error) (int64, bool) {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset, true
+	}
+
+	return 0, false
+}
+
+// recoverableTopLevelKeys returns, in document order, the keys of the top-level object of a JSON document
+// whose values decode completely before the document turns invalid.
+func recoverableTopLevelKeys(input []byte) []string {
+	keys := make([]string, 0)
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return keys
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, ok := token.(string)
+		if !ok {
+			return keys
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return keys
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
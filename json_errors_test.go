@@ -0,0 +1,55 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverableTopLevelKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "valid", input: `{"format_version": "1.2", "variables": {}}`, expected: []string{"format_version", "variables"}},
+		{name: "truncated value", input: `{"format_version": "1.2", "variables": {"env": {"value": "dev"}}, "resource_changes": [{"addr`, expected: []string{"format_version", "variables"}},
+		{name: "truncated key", input: `{"format_version": "1.2", "varia`, expected: []string{"format_version"}},
+		{name: "invalid value", input: `{"format_version": "1.2", "variables": {"env": nope}}`, expected: []string{"format_version"}},
+		{name: "not an object", input: `[1, 2]`, expected: []string{}},
+		{name: "empty", input: ``, expected: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, recoverableTopLevelKeys([]byte(tc.input)))
+		})
+	}
+}
+
+func TestComparePlans_MalformedJSON(t *testing.T) {
+	valid := `{"format_version": "1.2"}`
+	truncated := `{"format_version": "1.2", "variables": {"env": {"value": "dev"}}, "resource_changes": [{"addr`
+
+	_, err := ComparePlans(valid, truncated, nil)
+	require.Error(t, err)
+	assert.EqualError(t, err, "error parsing new plan: invalid JSON at byte offset 93 "+
+		"(recoverable top-level keys: format_version, variables): unexpected end of JSON input")
+
+	// The json error is kept as the cause
+	var syntaxErr *json.SyntaxError
+	require.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, int64(len(truncated)), syntaxErr.Offset)
+
+	_, err = ComparePlans(`{"format_version": 1.2 x}`, valid, nil)
+	require.Error(t, err)
+	assert.EqualError(t, err, "error parsing original plan: invalid JSON at byte offset 24 "+
+		"(recoverable top-level keys: format_version): invalid character 'x' after object key:value pair")
+
+	_, err = ComparePlans(valid, `[]`, nil)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "error parsing new plan: invalid JSON at byte offset 1 (no recoverable top-level keys)")
+}
@@ -0,0 +1,94 @@
+package comparison
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// largePlanSections are the top-level plan sections CompareLargePlans decodes.
+var largePlanSections = stringSet([]string{"variables", "resource_changes", "output_changes"})
+
+// CompareLargePlans compares two plan documents read from streams, decoding only their variables,
+// resource_changes and output_changes sections. Every other section, such as prior_state,
+// planned_values and configuration, is skipped token by token without being held in memory, which
+// keeps memory use low for very large plans. The diff of those sections matches ComparePlans with the
+// default options; resources that only appear in the skipped sections are not compared.
+func CompareLargePlans(origReader, newReader io.Reader) (*PlanDiff, error) {
+	opts := resolveOptions(nil)
+	parseStart := time.Now()
+
+	origPlan, err := decodePlanSections(origReader, largePlanSections)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing original plan JSON")
+	}
+
+	newPlan, err := decodePlanSections(newReader, largePlanSections)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing new plan JSON")
+	}
+	parseDuration := time.Since(parseStart)
+
+	result, err := comparePlanMaps(origPlan, newPlan, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Stats.ParseDuration = parseDuration
+	return result, nil
+}
+
+// decodePlanSections decodes the given top-level sections of a plan document and skips the others.
+func decodePlanSections(r io.Reader, sections map[string]bool) (map[string]interface{}, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	plan := make(map[string]interface{})
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		if !sections[key] {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		plan[key] = value
+	}
+
+	return plan, expectDelim(dec, '}')
+}
+
+// skipValue reads past the next value of a decoder, one token at a time.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
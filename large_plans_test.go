@@ -0,0 +1,71 @@
+package comparison
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareLargePlans(t *testing.T) {
+	orig := `{
+		"format_version": "1.2",
+		"variables": {"env": {"value": "dev"}, "count": {"value": 2}},
+		"planned_values": {"root_module": {"resources": [{"address": "ignored.only_in_planned_values", "values": {"a": [1, {"b": "}"}]}}]}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro", "tags": {"Name": "web"}}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		],
+		"output_changes": {"url": {"after": "a"}},
+		"configuration": {"root_module": {}}
+	}`
+	updated := `{
+		"variables": {"env": {"value": "prod"}, "count": {"value": 2}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large", "tags": {"Name": "web"}}}},
+			{"address": "aws_s3_bucket.new", "change": {"after": {"bucket": "new"}}}
+		],
+		"output_changes": {"url": {"after": "b"}},
+		"prior_state": {"values": {"root_module": {"resources": [{"address": "ignored.only_in_prior_state"}]}}}
+	}`
+
+	result, err := CompareLargePlans(strings.NewReader(orig), strings.NewReader(updated))
+	require.NoError(t, err)
+
+	sampled := func(plan string) string {
+		decoded, err := decodePlanSections(strings.NewReader(plan), largePlanSections)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		return string(encoded)
+	}
+	expected, err := ComparePlans(sampled(orig), sampled(updated), nil)
+	require.NoError(t, err)
+
+	assert.True(t, result.HasDiff)
+	assert.Equal(t, expected.Text, result.Text)
+	assert.Equal(t, expected.Changes, result.Changes)
+	assert.NotContains(t, result.Text, "ignored.")
+}
+
+func TestCompareLargePlans_InvalidJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		orig        string
+		updated     string
+		errContains string
+	}{
+		{name: "truncated original", orig: `{"variables": {`, updated: `{}`, errContains: "error parsing original plan JSON"},
+		{name: "truncated skipped section", orig: `{}`, updated: `{"prior_state": [1, 2`, errContains: "error parsing new plan JSON"},
+		{name: "not an object", orig: `{}`, updated: `[]`, errContains: "error parsing new plan JSON"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CompareLargePlans(strings.NewReader(tc.orig), strings.NewReader(tc.updated))
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
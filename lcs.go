@@ -0,0 +1,130 @@
+package comparison
+
+// lcsTableCells caps the size of the table used to align two sequences. Larger alignments are split in
+// halves first, which keeps the memory linear in the length of the sequences.
+var lcsTableCells = 1 << 20
+
+// longestCommonSubsequence returns the index pairs of the longest common subsequence of two sequences
+// of lengths m and n whose elements are compared by equal.
+func longestCommonSubsequence(m, n int, equal func(i, j int) bool) [][2]int {
+	pairs := make([][2]int, 0)
+
+	// Elements shared at the start are always aligned with each other
+	prefix := 0
+	for prefix < m && prefix < n && equal(prefix, prefix) {
+		pairs = append(pairs, [2]int{prefix, prefix})
+		prefix++
+	}
+
+	if (m-prefix)*(n-prefix) <= lcsTableCells {
+		return append(pairs, lcsTable(prefix, m, prefix, n, equal)...)
+	}
+
+	// Elements shared at the end are aligned too, leaving only the middle to search
+	suffix := 0
+	for suffix < m-prefix && suffix < n-prefix && equal(m-suffix-1, n-suffix-1) {
+		suffix++
+	}
+
+	pairs = lcsSplit(pairs, prefix, m-suffix, prefix, n-suffix, equal)
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{m - k, n - k})
+	}
+	return pairs
+}
+
+// lcsSplit appends the index pairs of the longest common subsequence of the ranges [i0, i1) and [j0, j1)
+// to pairs. Ranges too large for a table are split at the middle of the first range and at the point of the
+// second range that keeps the subsequence longest, as in Hirschberg's algorithm.
+func lcsSplit(pairs [][2]int, i0, i1, j0, j1 int, equal func(i, j int) bool) [][2]int {
+	if i1-i0 <= 1 || (i1-i0)*(j1-j0) <= lcsTableCells {
+		return append(pairs, lcsTable(i0, i1, j0, j1, equal)...)
+	}
+
+	mid := (i0 + i1) / 2
+	forward := lcsForwardLengths(i0, mid, j0, j1, equal)
+	backward := lcsBackwardLengths(mid, i1, j0, j1, equal)
+
+	split := 0
+	for k := range forward {
+		if forward[k]+backward[k] > forward[split]+backward[split] {
+			split = k
+		}
+	}
+
+	pairs = lcsSplit(pairs, i0, mid, j0, j0+split, equal)
+	return lcsSplit(pairs, mid, i1, j0+split, j1, equal)
+}
+
+// lcsForwardLengths returns, for every k, the length of the longest common subsequence of [i0, i1) and
+// [j0, j0+k).
+func lcsForwardLengths(i0, i1, j0, j1 int, equal func(i, j int) bool) []int {
+	prev, cur := make([]int, j1-j0+1), make([]int, j1-j0+1)
+	for i := i0; i < i1; i++ {
+		for k := 1; k <= j1-j0; k++ {
+			if equal(i, j0+k-1) {
+				cur[k] = prev[k-1] + 1
+			} else {
+				cur[k] = max(prev[k], cur[k-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsBackwardLengths returns, for every k, the length of the longest common subsequence of [i0, i1) and
+// [j0+k, j1).
+func lcsBackwardLengths(i0, i1, j0, j1 int, equal func(i, j int) bool) []int {
+	prev, cur := make([]int, j1-j0+1), make([]int, j1-j0+1)
+	for i := i1 - 1; i >= i0; i-- {
+		for k := j1 - j0 - 1; k >= 0; k-- {
+			if equal(i, j0+k) {
+				cur[k] = prev[k+1] + 1
+			} else {
+				cur[k] = max(prev[k], cur[k+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsTable returns the index pairs of the longest common subsequence of the ranges [i0, i1) and [j0, j1),
+// using a table of their lengths.
+func lcsTable(i0, i1, j0, j1 int, equal func(i, j int) bool) [][2]int {
+	m, n := i1-i0, j1-j0
+	lengths := make([][]int, m+1)
+	for i := range lengths {
+		lengths[i] = make([]int, n+1)
+	}
+
+	for i := m - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			switch {
+			case equal(i0+i, j0+j):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	pairs := make([][2]int, 0, lengths[0][0])
+	for i, j := 0, 0; i < m && j < n; {
+		switch {
+		case equal(i0+i, j0+j):
+			pairs = append(pairs, [2]int{i0 + i, j0 + j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pairs
+}
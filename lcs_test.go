@@ -0,0 +1,90 @@
+package comparison
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongestCommonSubsequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		orig     string
+		new      string
+		expected [][2]int
+	}{
+		{name: "empty", orig: "", new: "abc", expected: [][2]int{}},
+		{name: "equal", orig: "abc", new: "abc", expected: [][2]int{{0, 0}, {1, 1}, {2, 2}}},
+		{name: "insertion", orig: "ac", new: "abc", expected: [][2]int{{0, 0}, {1, 2}}},
+		{name: "nothing in common", orig: "ab", new: "cd", expected: [][2]int{}},
+		{name: "common prefix and suffix", orig: "axyb", new: "azb", expected: [][2]int{{0, 0}, {3, 2}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, longestCommonSubsequence(len(tc.orig), len(tc.new), func(i, j int) bool {
+				return tc.orig[i] == tc.new[j]
+			}))
+		})
+	}
+}
+
+func TestLongestCommonSubsequence_SplitsLargeAlignments(t *testing.T) {
+	defer func(cells int) { lcsTableCells = cells }(lcsTableCells)
+
+	random := rand.New(rand.NewSource(1))
+	sequence := func(n int) []byte {
+		s := make([]byte, n)
+		for i := range s {
+			s[i] = "abcd"[random.Intn(4)]
+		}
+		return s
+	}
+
+	for i := 0; i < 50; i++ {
+		orig, new := sequence(random.Intn(60)), sequence(random.Intn(60))
+		equal := func(i, j int) bool { return orig[i] == new[j] }
+
+		lcsTableCells = 1 << 20
+		expected := longestCommonSubsequence(len(orig), len(new), equal)
+		lcsTableCells = 4
+		pairs := longestCommonSubsequence(len(orig), len(new), equal)
+
+		require.Len(t, pairs, len(expected), "%s / %s", orig, new)
+		for k, pair := range pairs {
+			assert.Equal(t, orig[pair[0]], new[pair[1]])
+			if k > 0 {
+				assert.Less(t, pairs[k-1][0], pair[0])
+				assert.Less(t, pairs[k-1][1], pair[1])
+			}
+		}
+	}
+}
+
+func TestDiffLines_LargeValues(t *testing.T) {
+	// Too large for a single table of 1<<20 cells
+	origLines, newLines := make([]string, 0, 4000), make([]string, 0, 4000)
+	for i := 0; i < 4000; i++ {
+		origLines = append(origLines, fmt.Sprintf("line %d", i))
+		if i%200 == 100 {
+			newLines = append(newLines, fmt.Sprintf("changed %d", i))
+		} else {
+			newLines = append(newLines, fmt.Sprintf("line %d", i))
+		}
+	}
+
+	ops := diffLines(strings.Join(origLines, "\n"), strings.Join(newLines, "\n"))
+
+	changes := 0
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changes++
+		}
+	}
+	assert.Equal(t, 40, changes)
+	assert.Len(t, ops, 4020)
+}
@@ -0,0 +1,115 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unifiedContextLines is the number of unchanged lines shown around the changed lines of a multi-line value.
+const unifiedContextLines = 3
+
+// lineOp is one line of a line-by-line diff: ' ' for an unchanged line, '-' for a removed and '+' for
+// an added one.
+type lineOp struct {
+	kind byte
+	text string
+}
+
+// multiLineStrings returns both values as strings if they are strings and at least one spans several lines.
+func multiLineStrings(origV, newV interface{}) (string, string, bool) {
+	origStr, origOk := origV.(string)
+	newStr, newOk := newV.(string)
+	if !origOk || !newOk || !(strings.Contains(origStr, "\n") || strings.Contains(newStr, "\n")) {
+		return "", "", false
+	}
+	return origStr, newStr, true
+}
+
+// formatUnifiedDiff renders the change of a multi-line string attribute as a unified diff: removed
+// lines are prefixed with "-", added lines with "+", and up to unifiedContextLines unchanged lines
+// around them are kept for context. Longer runs of unchanged lines are elided. Lines are masked as they
+// are written, so masking cannot hide a change, and capped like other values. Once the written lines
+// reach MaxValueLength bytes, the rest of the diff is replaced by a note of how many lines it holds.
+func formatUnifiedDiff(attrK, origStr, newStr string, opts *CompareOptions) string {
+	ops := diffLines(origStr, newStr)
+
+	// Show the unchanged lines close to a change
+	show := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		for j := max(0, i-unifiedContextLines); j <= min(len(ops)-1, i+unifiedContextLines); j++ {
+			show[j] = true
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  ~ %s:\n", attrK))
+	elided := false
+	written := 0
+	for i, op := range ops {
+		if !show[i] {
+			if !elided {
+				sb.WriteString("      ...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+
+		if opts.MaxValueLength > 0 && written >= opts.MaxValueLength {
+			sb.WriteString(fmt.Sprintf("      %s\n", moreLinesNote(countShown(show[i:]), opts)))
+			break
+		}
+
+		text := capString(maskString(op.text, opts), opts)
+		written += len(text)
+		if op.kind == ' ' {
+			sb.WriteString(fmt.Sprintf("      %s\n", text))
+		} else {
+			sb.WriteString(fmt.Sprintf("    %c %s\n", op.kind, text))
+		}
+	}
+	return sb.String()
+}
+
+// countShown returns the number of lines of a unified diff that are shown.
+func countShown(show []bool) int {
+	n := 0
+	for _, shown := range show {
+		if shown {
+			n++
+		}
+	}
+	return n
+}
+
+// diffLines aligns the lines of two strings by their longest common subsequence.
+func diffLines(origStr, newStr string) []lineOp {
+	if strings.HasSuffix(origStr, "\n") && strings.HasSuffix(newStr, "\n") {
+		origStr, newStr = origStr[:len(origStr)-1], newStr[:len(newStr)-1]
+	}
+	origLines, newLines := strings.Split(origStr, "\n"), strings.Split(newStr, "\n")
+
+	pairs := longestCommonSubsequence(len(origLines), len(newLines), func(i, j int) bool {
+		return origLines[i] == newLines[j]
+	})
+	pairs = append(pairs, [2]int{len(origLines), len(newLines)})
+
+	ops := make([]lineOp, 0, len(origLines)+len(newLines))
+	i, j := 0, 0
+	for _, pair := range pairs {
+		for ; i < pair[0]; i++ {
+			ops = append(ops, lineOp{kind: '-', text: origLines[i]})
+		}
+		for ; j < pair[1]; j++ {
+			ops = append(ops, lineOp{kind: '+', text: newLines[j]})
+		}
+		if pair[0] < len(origLines) {
+			ops = append(ops, lineOp{kind: ' ', text: origLines[pair[0]]})
+		}
+		i, j = pair[0]+1, pair[1]+1
+	}
+	return ops
+}
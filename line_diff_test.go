@@ -0,0 +1,124 @@
+package comparison
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		origStr  string
+		newStr   string
+		expected string
+	}{
+		{
+			name:    "changed line keeps context",
+			origStr: "#!/bin/bash\nyum update -y\necho old\nsystemctl start app\n",
+			newStr:  "#!/bin/bash\nyum update -y\necho new\nsystemctl start app\n",
+			expected: "  ~ user_data:\n" +
+				"      #!/bin/bash\n" +
+				"      yum update -y\n" +
+				"    - echo old\n" +
+				"    + echo new\n" +
+				"      systemctl start app\n",
+		},
+		{
+			name:    "added and removed lines",
+			origStr: "a\nb\nc",
+			newStr:  "a\nc\nd",
+			expected: "  ~ user_data:\n" +
+				"      a\n" +
+				"    - b\n" +
+				"      c\n" +
+				"    + d\n",
+		},
+		{
+			name:    "single line becomes multi-line",
+			origStr: "a",
+			newStr:  "a\nb",
+			expected: "  ~ user_data:\n" +
+				"      a\n" +
+				"    + b\n",
+		},
+		{
+			name:    "distant unchanged lines are elided",
+			origStr: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			newStr:  "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10\n11\n12",
+			expected: "  ~ user_data:\n" +
+				"      ...\n" +
+				"      3\n      4\n      5\n" +
+				"    - 6\n" +
+				"    + six\n" +
+				"      7\n      8\n      9\n" +
+				"      ...\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatUnifiedDiff("user_data", tc.origStr, tc.newStr, &CompareOptions{}))
+		})
+	}
+}
+
+func TestComparePlans_MultiLineAttribute(t *testing.T) {
+	policy := func(action string) string {
+		return strings.ReplaceAll(`{"resource_changes": [{"address": "aws_iam_policy.app", "change": {"after": {"name": "app", "policy": "{\n  \"Statement\": [{\n    \"Action\": \"ACTION\",\n    \"Effect\": \"Allow\"\n  }]\n}"}}}]}`, "ACTION", action)
+	}
+
+	result, err := ComparePlans(policy("s3:GetObject"), policy("s3:*"), nil)
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "aws_iam_policy.app\n"+
+		"  ~ policy:\n"+
+		"      {\n"+
+		"        \"Statement\": [{\n"+
+		"    -     \"Action\": \"s3:GetObject\",\n"+
+		"    +     \"Action\": \"s3:*\",\n"+
+		"          \"Effect\": \"Allow\"\n"+
+		"        }]\n"+
+		"      }\n")
+	assert.NotContains(t, result.Text, "=>")
+}
+
+func TestFormatUnifiedDiff_MaxValueLength(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, "%s line %d\n", prefix, i)
+		}
+		return sb.String()
+	}
+
+	diff := formatUnifiedDiff("user_data", lines("old", 1000), lines("new", 1000), &CompareOptions{MaxValueLength: 30, Plain: true})
+	assert.Equal(t, "  ~ user_data:\n"+
+		"    - old line 0\n"+
+		"    - old line 1\n"+
+		"    - old line 2\n"+
+		"      ...(1997 more lines)\n", diff)
+
+	// Every line is capped as well
+	diff = formatUnifiedDiff("user_data", "a\n"+strings.Repeat("x", 100), "b\n"+strings.Repeat("x", 100), &CompareOptions{MaxValueLength: 10})
+	assert.Equal(t, "  ~ user_data:\n"+
+		"    - a\n"+
+		"    + b\n"+
+		"      xxxxxxxxxx…(100 bytes total)\n", diff)
+}
+
+func TestComparePlans_MultiLineAttributeFormatter(t *testing.T) {
+	plan := func(userData string) string {
+		encoded, _ := json.Marshal(userData)
+		return `{"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"user_data": ` + string(encoded) + `}}}]}`
+	}
+
+	result, err := ComparePlans(plan("echo a\necho b\n"), plan("echo a\necho c\n"), &CompareOptions{ValueFormatter: ValueFormatterFunc(func(v interface{}) string {
+		return fmt.Sprintf("<%d bytes>", len(fmt.Sprint(v)))
+	})})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "~ user_data: <14 bytes> => <14 bytes>\n")
+}
@@ -0,0 +1,276 @@
+package comparison
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// markdownTruncatedNote replaces the changes that did not fit in the rendered Markdown.
+const markdownTruncatedNote = "\n... %d more changes\n"
+
+// MarkdownOptions configures RenderMarkdownWithOptions.
+type MarkdownOptions struct {
+	// MaxLength caps the size of the rendered Markdown in bytes, e.g. to fit GitHub's comment limit of
+	// 65536. Changes that do not fit are replaced by a "... N more changes" footer. Zero renders every change.
+	MaxLength int
+}
+
+// markdownSection is a titled group of rendered changes. Resource sections render every change as a
+// collapsible block of its own; other sections share a single diff code block.
+type markdownSection struct {
+	title     string
+	resources bool
+	changes   []string
+}
+
+// RenderMarkdown renders a diff map as GitHub-flavored Markdown for a pull request comment.
+func RenderMarkdown(diffMap map[string]interface{}) string {
+	return RenderMarkdownWithOptions(diffMap, nil)
+}
+
+// RenderMarkdownWithOptions renders a diff map as GitHub-flavored Markdown: a table counting the changes
+// of each section, then the changes of each non-empty section in fenced diff code blocks. Every resource
+// gets a collapsible <details> block. A nil opts uses the defaults.
+func RenderMarkdownWithOptions(diffMap map[string]interface{}, opts *MarkdownOptions) string {
+	maxLength := 0
+	if opts != nil {
+		maxLength = opts.MaxLength
+	}
+
+	sections := markdownSections(diffMap)
+
+	var sb strings.Builder
+	sb.WriteString(markdownSummaryTable(diffMap))
+
+	total := 0
+	for _, section := range sections {
+		total += len(section.changes)
+	}
+
+	written := 0
+	for _, section := range sections {
+		open := fmt.Sprintf("\n#### %s\n\n", section.title)
+		closing := ""
+		if !section.resources {
+			fence := codeFence(strings.Join(section.changes, ""))
+			open += fence + "diff\n"
+			closing = fence + "\n"
+		}
+
+		sectionWritten := 0
+		for _, change := range section.changes {
+			prefix := ""
+			if sectionWritten == 0 {
+				prefix = open
+			}
+
+			footer := ""
+			if remaining := total - written - 1; remaining > 0 {
+				footer = fmt.Sprintf(markdownTruncatedNote, remaining)
+			}
+			if maxLength > 0 && sb.Len()+len(prefix)+len(change)+len(closing)+len(footer) > maxLength {
+				break
+			}
+
+			sb.WriteString(prefix + change)
+			sectionWritten++
+			written++
+		}
+
+		if sectionWritten > 0 {
+			sb.WriteString(closing)
+		}
+		if sectionWritten < len(section.changes) {
+			break
+		}
+	}
+
+	if written < total {
+		sb.WriteString(fmt.Sprintf(markdownTruncatedNote, total-written))
+	}
+
+	if maxLength > 0 {
+		return truncateString(sb.String(), maxLength)
+	}
+	return sb.String()
+}
+
+// markdownSummaryTable renders a table with the change counts of the non-empty sections.
+func markdownSummaryTable(diffMap map[string]interface{}) string {
+	summary := SummarizeDiff(diffMap)
+	rows := []struct {
+		title  string
+		counts SectionCounts
+	}{
+		{"Resources", summary.Resources},
+		{"Variables", summary.Variables},
+		{"Outputs", summary.Outputs},
+		{"Drift", summary.Drift},
+		{"Tag changes", summary.Tags},
+		{"Provider upgrades", SectionCounts{Changed: len(entryList(diffMap["provider_upgrades"]))}},
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		if row.counts == (SectionCounts{}) {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("| Section | Added | Removed | Changed |\n| --- | ---: | ---: | ---: |\n")
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n", row.title, row.counts.Added, row.counts.Removed, row.counts.Changed))
+	}
+
+	if sb.Len() == 0 {
+		return "No changes.\n"
+	}
+	return sb.String()
+}
+
+// markdownSections renders the changes of the non-empty sections of a diff map.
+func markdownSections(diffMap map[string]interface{}) []markdownSection {
+	opts := resolveOptions(nil)
+	sections := make([]markdownSection, 0)
+
+	for _, section := range []struct {
+		name, title string
+		resources   bool
+	}{
+		{"variables", "Variables", false},
+		{"resources", "Resources", true},
+		{"tag_changes", "Tag changes", true},
+		{"drift", "Drift", true},
+		{"outputs", "Outputs", false},
+	} {
+		categories, _ := diffMap[section.name].(map[string]interface{})
+
+		var changes []string
+		if section.resources {
+			changes = markdownResourceChanges(categories, opts)
+		} else {
+			changes = markdownValueChanges(categories, opts)
+		}
+		if len(changes) > 0 {
+			sections = append(sections, markdownSection{title: section.title, resources: section.resources, changes: changes})
+		}
+	}
+
+	upgrades := make([]string, 0)
+	for _, entry := range entryList(diffMap["provider_upgrades"]) {
+		upgrades = append(upgrades, markdownDiffLines("-", fmt.Sprintf("%s: %v", entryKey(entry), entry["old_version"]))+
+			markdownDiffLines("+", fmt.Sprintf("%s: %v", entryKey(entry), entry["new_version"])))
+	}
+	if len(upgrades) > 0 {
+		sections = append(sections, markdownSection{title: "Provider upgrades", changes: upgrades})
+	}
+
+	return sections
+}
+
+// markdownValueChanges renders the entries of a variables or outputs section as diff lines.
+func markdownValueChanges(categories map[string]interface{}, opts *CompareOptions) []string {
+	changes := make([]string, 0)
+	for _, entry := range entryList(categories["added"]) {
+		changes = append(changes, markdownDiffLines("+", entryKey(entry)+": "+formatValue(entry["value"], opts)))
+	}
+	for _, entry := range entryList(categories["removed"]) {
+		changes = append(changes, markdownDiffLines("-", entryKey(entry)+": "+formatValue(entry["value"], opts)))
+	}
+	for _, entry := range entryList(categories["changed"]) {
+		changes = append(changes, markdownDiffLines("-", entryKey(entry)+": "+formatValue(entry["old"], opts))+
+			markdownDiffLines("+", entryKey(entry)+": "+formatValue(entry["new"], opts)))
+	}
+	return changes
+}
+
+// markdownResourceChanges renders every entry of a resources section as a collapsible block.
+func markdownResourceChanges(categories map[string]interface{}, opts *CompareOptions) []string {
+	changes := make([]string, 0)
+	for _, entry := range entryList(categories["count_changed"]) {
+		body := markdownDiffLines("-", fmt.Sprintf("count: %v", entry["old_count"])) + markdownDiffLines("+", fmt.Sprintf("count: %v", entry["new_count"]))
+		changes = append(changes, markdownDetails("~ "+entryKey(entry), body))
+	}
+	for _, entry := range entryList(categories["added"]) {
+		attrs := getResourceAttributes(entry["value"], opts)
+		changes = append(changes, markdownDetails("+ "+entryKey(entry), markdownAttributeLines("+", attrs, opts)))
+	}
+	for _, entry := range entryList(categories["removed"]) {
+		attrs := getResourceAttributes(entry["value"], opts)
+		changes = append(changes, markdownDetails("- "+entryKey(entry), markdownAttributeLines("-", attrs, opts)))
+	}
+	for _, entry := range entryList(categories["moved"]) {
+		changes = append(changes, markdownDetails("~ "+entryKey(entry)+" (moved)", markdownAttributeChanges(entry, opts)))
+	}
+	for _, entry := range entryList(categories["mode_changed"]) {
+		body := markdownDiffLines("-", fmt.Sprintf("mode: %v", entry["old_mode"])) + markdownDiffLines("+", fmt.Sprintf("mode: %v", entry["new_mode"]))
+		changes = append(changes, markdownDetails("! "+entryKey(entry), body))
+	}
+	for _, entry := range entryList(categories["changed"]) {
+		changes = append(changes, markdownDetails("~ "+entryKey(entry), markdownAttributeChanges(entry, opts)))
+	}
+	return changes
+}
+
+// markdownAttributeChanges renders the attribute changes of a changed or moved resource entry as diff lines.
+func markdownAttributeChanges(entry map[string]interface{}, opts *CompareOptions) string {
+	attrs, _ := entry["attributes"].(map[string]interface{})
+
+	var sb strings.Builder
+	for _, attr := range entryList(attrs["added"]) {
+		sb.WriteString(markdownDiffLines("+", entryKey(attr)+": "+formatValue(attr["value"], opts)))
+	}
+	for _, attr := range entryList(attrs["removed"]) {
+		sb.WriteString(markdownDiffLines("-", entryKey(attr)+": "+formatValue(attr["value"], opts)))
+	}
+	for _, attr := range entryList(attrs["changed"]) {
+		sb.WriteString(markdownDiffLines("-", entryKey(attr)+": "+formatValue(attr["old"], opts)))
+		sb.WriteString(markdownDiffLines("+", entryKey(attr)+": "+formatValue(attr["new"], opts)))
+	}
+	return sb.String()
+}
+
+// markdownAttributeLines renders the attributes of an added or removed resource as diff lines with marker.
+func markdownAttributeLines(marker string, attrs map[string]interface{}, opts *CompareOptions) string {
+	var sb strings.Builder
+	for _, name := range getSortedKeys(attrs, nil) {
+		sb.WriteString(markdownDiffLines(marker, name+": "+formatValue(attrs[name], opts)))
+	}
+	return sb.String()
+}
+
+// markdownDetails renders a collapsible block with a summary line and a diff code block, if there is a body.
+func markdownDetails(summary, body string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code></summary>\n\n", html.EscapeString(summary)))
+	if body != "" {
+		fence := codeFence(body)
+		sb.WriteString(fence + "diff\n" + body + fence + "\n\n")
+	}
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in content, so values holding
+// backticks cannot close the code block early.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// markdownDiffLines prefixes every line of text with a diff marker so GitHub highlights it.
+func markdownDiffLines(marker, text string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		sb.WriteString(marker + " " + line + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	origJSON := `{
+		"variables": {"stage": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		]
+	}`
+	newJSON := `{
+		"variables": {"stage": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large"}}},
+			{"address": "aws_s3_bucket.new[\"a\"]", "change": {"after": {"bucket": "new"}}}
+		]
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, nil)
+	require.NoError(t, err)
+	markdown := RenderMarkdown(result.Changes)
+
+	assert.True(t, strings.HasPrefix(markdown, "| Section | Added | Removed | Changed |\n| --- | ---: | ---: | ---: |\n"+
+		"| Resources | 1 | 1 | 1 |\n| Variables | 0 | 0 | 1 |\n"))
+	assert.Contains(t, markdown, "\n#### Variables\n\n```diff\n- stage: dev\n+ stage: prod\n```\n")
+	assert.Contains(t, markdown, "<details>\n<summary><code>+ aws_s3_bucket.new[&#34;a&#34;]</code></summary>\n\n```diff\n+ bucket: new\n```\n\n</details>\n")
+	assert.Contains(t, markdown, "<summary><code>- aws_s3_bucket.old</code></summary>\n\n```diff\n- bucket: old\n```\n")
+	assert.Contains(t, markdown, "<summary><code>~ aws_instance.web</code></summary>\n\n```diff\n- instance_type: t3.micro\n+ instance_type: t3.large\n```\n")
+	assert.NotContains(t, markdown, "Outputs")
+	assert.NotContains(t, markdown, "more changes")
+}
+
+func TestRenderMarkdown_BackticksInValues(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "note", "old": "a", "new": "````"}},
+		},
+		"resources": map[string]interface{}{
+			"added": []map[string]interface{}{{
+				"address": "aws_instance.web",
+				"value":   map[string]interface{}{"values": map[string]interface{}{"user_data": "```\n# injected"}},
+			}},
+		},
+	}
+
+	markdown := RenderMarkdown(diffMap)
+	assert.Contains(t, markdown, "\n#### Variables\n\n`````diff\n- note: a\n+ note: ````\n`````\n")
+	assert.Contains(t, markdown, "\n\n````diff\n+ user_data: ```\n+ # injected\n````\n\n</details>\n")
+}
+
+func TestCodeFence(t *testing.T) {
+	assert.Equal(t, "```", codeFence("no backticks"))
+	assert.Equal(t, "```", codeFence("`inline` and ``double``"))
+	assert.Equal(t, "````", codeFence("a\n```\nb"))
+	assert.Equal(t, "``````", codeFence("`````"))
+}
+
+func TestRenderMarkdown_NoChanges(t *testing.T) {
+	assert.Equal(t, "No changes.\n", RenderMarkdown(map[string]interface{}{}))
+}
+
+func TestRenderMarkdownWithOptions_MaxLength(t *testing.T) {
+	added := make([]map[string]interface{}, 0, 200)
+	for i := 0; i < 200; i++ {
+		added = append(added, map[string]interface{}{"address": fmt.Sprintf("aws_instance.web[%d]", i)})
+	}
+	diffMap := map[string]interface{}{
+		"resources": map[string]interface{}{"added": added},
+		"outputs": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "url", "old": "a", "new": "b"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		maxLength int
+		footer    bool
+	}{
+		{name: "unlimited", maxLength: 0},
+		{name: "large enough", maxLength: 100000},
+		{name: "truncated", maxLength: 2000, footer: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			markdown := RenderMarkdownWithOptions(diffMap, &MarkdownOptions{MaxLength: tc.maxLength})
+
+			if tc.maxLength > 0 {
+				assert.LessOrEqual(t, len(markdown), tc.maxLength)
+			}
+			assert.Contains(t, markdown, "| Resources | 200 | 0 | 0 |\n")
+			if !tc.footer {
+				assert.NotContains(t, markdown, "more changes")
+				assert.Contains(t, markdown, "\n#### Outputs\n\n```diff\n- url: a\n+ url: b\n```\n")
+				return
+			}
+
+			shown := strings.Count(markdown, "<details>")
+			assert.Equal(t, strings.Count(markdown, "</details>"), shown)
+			assert.True(t, strings.HasSuffix(markdown, fmt.Sprintf("</details>\n\n... %d more changes\n", 201-shown)))
+		})
+	}
+}
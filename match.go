@@ -0,0 +1,103 @@
+package comparison
+
+import "sort"
+
+// ExpectedChange describes a single change in a diff map. Section is "variables", "resources",
+// "tag_changes", "drift", "outputs" or "provider_upgrades" and Kind is "added", "removed", "changed", "moved",
+// "count_changed" or "mode_changed".
+// Address holds the resource address, variable or output name, or provider; moved resources use
+// "old => new". Changed resources are described per attribute, with Attribute set to its name.
+type ExpectedChange struct {
+	Section   string
+	Kind      string
+	Address   string
+	Attribute string
+}
+
+// MatchChanges checks a diff map against the expected changes. It returns the expected changes
+// missing from the diff and the changes in the diff that were not expected, so a plan comparison
+// can be asserted like a contract. Both results are empty when the diff matches exactly.
+func MatchChanges(diffMap map[string]interface{}, expected []ExpectedChange) (missing, unexpected []ExpectedChange) {
+	remaining := make(map[ExpectedChange]int)
+	for _, change := range actualChanges(diffMap) {
+		remaining[change]++
+	}
+
+	missing = make([]ExpectedChange, 0)
+	for _, change := range expected {
+		if remaining[change] == 0 {
+			missing = append(missing, change)
+			continue
+		}
+		remaining[change]--
+	}
+
+	unexpected = make([]ExpectedChange, 0)
+	for change, count := range remaining {
+		for i := 0; i < count; i++ {
+			unexpected = append(unexpected, change)
+		}
+	}
+	sortExpectedChanges(unexpected)
+
+	return missing, unexpected
+}
+
+// actualChanges flattens a diff map into change descriptors.
+func actualChanges(diffMap map[string]interface{}) []ExpectedChange {
+	changes := make([]ExpectedChange, 0)
+
+	for _, section := range []string{"variables", "resources", "tag_changes", "drift", "outputs"} {
+		categories, ok := diffMap[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, kind := range []string{"count_changed", "added", "removed", "moved", "mode_changed", "changed"} {
+			for _, entry := range entryList(categories[kind]) {
+				changes = append(changes, entryChanges(section, kind, entry)...)
+			}
+		}
+	}
+
+	for _, entry := range entryList(diffMap["provider_upgrades"]) {
+		changes = append(changes, ExpectedChange{Section: "provider_upgrades", Kind: "changed", Address: entryKey(entry)})
+	}
+
+	return changes
+}
+
+// entryChanges returns the change descriptors for a single diff map entry.
+func entryChanges(section, kind string, entry map[string]interface{}) []ExpectedChange {
+	attrs, ok := entry["attributes"].(map[string]interface{})
+	if !ok || kind != "changed" {
+		return []ExpectedChange{{Section: section, Kind: kind, Address: entryKey(entry)}}
+	}
+
+	changes := make([]ExpectedChange, 0)
+	for _, attrKind := range []string{"added", "removed", "changed"} {
+		for _, attr := range entryList(attrs[attrKind]) {
+			name, _ := attr["name"].(string)
+			changes = append(changes, ExpectedChange{Section: section, Kind: kind, Address: entryKey(entry), Attribute: name})
+		}
+	}
+
+	return changes
+}
+
+// sortExpectedChanges orders change descriptors by section, kind, address and attribute.
+func sortExpectedChanges(changes []ExpectedChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Attribute < b.Attribute
+	})
+}
@@ -0,0 +1,64 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchChanges(t *testing.T) {
+	orig := `{
+		"variables": {"env": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		]
+	}`
+	updated := `{
+		"variables": {"env": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large"}}},
+			{"address": "aws_s3_bucket.new", "change": {"after": {"bucket": "new"}}}
+		]
+	}`
+
+	result, err := ComparePlans(orig, updated, &CompareOptions{})
+	require.NoError(t, err)
+
+	expected := []ExpectedChange{
+		{Section: "variables", Kind: "changed", Address: "env"},
+		{Section: "resources", Kind: "added", Address: "aws_s3_bucket.new"},
+		{Section: "resources", Kind: "removed", Address: "aws_s3_bucket.old"},
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "instance_type"},
+	}
+
+	tests := []struct {
+		name               string
+		expected           []ExpectedChange
+		expectedMissing    []ExpectedChange
+		expectedUnexpected []ExpectedChange
+	}{
+		{
+			name:               "matching set",
+			expected:           expected,
+			expectedMissing:    []ExpectedChange{},
+			expectedUnexpected: []ExpectedChange{},
+		},
+		{
+			name: "mismatch reports both sides",
+			expected: append(append([]ExpectedChange{}, expected[1:]...),
+				ExpectedChange{Section: "outputs", Kind: "added", Address: "url"}),
+			expectedMissing:    []ExpectedChange{{Section: "outputs", Kind: "added", Address: "url"}},
+			expectedUnexpected: []ExpectedChange{{Section: "variables", Kind: "changed", Address: "env"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			missing, unexpected := MatchChanges(result.Changes, tc.expected)
+			assert.Equal(t, tc.expectedMissing, missing)
+			assert.Equal(t, tc.expectedUnexpected, unexpected)
+		})
+	}
+}
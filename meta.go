@@ -0,0 +1,58 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compareProviderVersions compares the Terraform core version and the provider version constraints of
+// two plans, reporting e.g. `~ provider.aws: 5.31.0 => 5.40.0`. Version changes explain other changes
+// rather than being changes themselves, so they never make the plans differ on their own and are only
+// written to the text diff next to other changes.
+func compareProviderVersions(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
+	origVersions, newVersions := getMetaVersions(origPlan), getMetaVersions(newPlan)
+
+	var diff strings.Builder
+	added := make([]map[string]interface{}, 0)
+	removed := make([]map[string]interface{}, 0)
+	changed := make([]map[string]interface{}, 0)
+
+	for _, name := range getSortedKeys(origVersions, newVersions) {
+		origVersion, origExists := origVersions[name]
+		newVersion, newExists := newVersions[name]
+
+		switch {
+		case !origExists:
+			diff.WriteString(fmt.Sprintf("+ %s: %s\n", name, maskString(fmt.Sprint(newVersion), opts)))
+			added = append(added, map[string]interface{}{"name": name, "value": newVersion})
+		case !newExists:
+			diff.WriteString(fmt.Sprintf("- %s: %s\n", name, maskString(fmt.Sprint(origVersion), opts)))
+			removed = append(removed, map[string]interface{}{"name": name, "value": origVersion})
+		case origVersion != newVersion:
+			diff.WriteString(fmt.Sprintf("~ %s\n", formatChange(name, maskString(fmt.Sprint(origVersion), opts), maskString(fmt.Sprint(newVersion), opts), opts)))
+			changed = append(changed, map[string]interface{}{"name": name, "old": origVersion, "new": newVersion})
+		}
+	}
+
+	if diff.Len() == 0 {
+		return "", nil, false
+	}
+
+	diffMap := map[string]interface{}{"added": added, "removed": removed, "changed": changed}
+	return "Meta:\n-----\n" + diff.String() + "\n", diffMap, true
+}
+
+// getMetaVersions returns the Terraform core version of a plan as "terraform" and the version constraint
+// of each provider as "provider.<name>".
+func getMetaVersions(plan map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	if version, ok := plan["terraform_version"].(string); ok && version != "" {
+		result["terraform"] = version
+	}
+	for name, version := range getProviderVersions(plan) {
+		result["provider."+name] = version
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareProviderVersions(t *testing.T) {
+	plan := func(terraformVersion string, providers map[string]string) map[string]interface{} {
+		providerConfig := make(map[string]interface{})
+		for key, version := range providers {
+			providerConfig[key] = map[string]interface{}{"name": strings.Split(key, ".")[0], "version_constraint": version}
+		}
+		return map[string]interface{}{
+			"terraform_version": terraformVersion,
+			"configuration":     map[string]interface{}{"provider_config": providerConfig},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		orig, new map[string]interface{}
+		hasDiff   bool
+		expected  string
+	}{
+		{
+			name:    "identical",
+			orig:    plan("1.6.0", map[string]string{"aws": "5.31.0"}),
+			new:     plan("1.6.0", map[string]string{"aws": "5.31.0"}),
+			hasDiff: false,
+		},
+		{
+			name:     "provider version",
+			orig:     plan("1.6.0", map[string]string{"aws": "5.31.0"}),
+			new:      plan("1.6.0", map[string]string{"aws": "5.40.0"}),
+			hasDiff:  true,
+			expected: "Meta:\n-----\n~ provider.aws: 5.31.0 => 5.40.0\n\n",
+		},
+		{
+			name:     "terraform version and added provider",
+			orig:     plan("1.6.0", nil),
+			new:      plan("1.7.1", map[string]string{"random": "~> 3.0"}),
+			hasDiff:  true,
+			expected: "Meta:\n-----\n+ provider.random: ~> 3.0\n~ terraform: 1.6.0 => 1.7.1\n\n",
+		},
+		{
+			name:     "removed provider",
+			orig:     plan("1.6.0", map[string]string{"aws.east": "5.31.0"}),
+			new:      plan("1.6.0", nil),
+			hasDiff:  true,
+			expected: "Meta:\n-----\n- provider.aws: 5.31.0\n\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _, hasDiff := compareProviderVersions(tc.orig, tc.new, &CompareOptions{})
+			assert.Equal(t, tc.hasDiff, hasDiff)
+			assert.Equal(t, tc.expected, diff)
+		})
+	}
+}
+
+func TestComparePlans_Meta(t *testing.T) {
+	origJSON := `{"terraform_version": "1.6.0", "configuration": {"provider_config": {"aws": {"name": "aws", "version_constraint": "5.31.0"}}}}`
+	newJSON := `{"terraform_version": "1.7.0", "configuration": {"provider_config": {"aws": {"name": "aws", "version_constraint": "5.40.0"}}}}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.Empty(t, result.Text)
+	assert.Equal(t, map[string]interface{}{
+		"added":   []map[string]interface{}{},
+		"removed": []map[string]interface{}{},
+		"changed": []map[string]interface{}{
+			{"name": "provider.aws", "old": "5.31.0", "new": "5.40.0"},
+			{"name": "terraform", "old": "1.6.0", "new": "1.7.0"},
+		},
+	}, result.Changes["meta"])
+
+	// The versions are written next to other changes
+	result, err = ComparePlans(
+		`{"terraform_version": "1.6.0", "variables": {"env": {"value": "dev"}}}`,
+		`{"terraform_version": "1.7.0", "variables": {"env": {"value": "prod"}}}`,
+		&CompareOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "Meta:\n-----\n~ terraform: 1.6.0 => 1.7.0\n")
+}
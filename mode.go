@@ -0,0 +1,51 @@
+package comparison
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resourceMode returns the mode of a resource, either "managed" or "data".
+func resourceMode(resource interface{}) string {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	mode, _ := resMap["mode"].(string)
+	return mode
+}
+
+// modeChanged reports whether a resource switched between managed and data at the same address.
+// Resources without a mode on either side are not considered changed.
+func modeChanged(origResource, newResource interface{}) bool {
+	origMode, newMode := resourceMode(origResource), resourceMode(newResource)
+	return origMode != "" && newMode != "" && origMode != newMode
+}
+
+// processModeChanges reports resources whose mode differs between the plans. Their attributes
+// are not diffed, since a data source and a managed resource are not comparable.
+func processModeChanges(diff *strings.Builder, origResources, newResources map[string]interface{}, opts *CompareOptions) []map[string]interface{} {
+	modeChanges := make([]map[string]interface{}, 0)
+
+	addresses := make([]string, 0)
+	for address, origV := range origResources {
+		if newV, exists := newResources[address]; exists && modeChanged(origV, newV) {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		origMode, newMode := resourceMode(origResources[address]), resourceMode(newResources[address])
+		diff.WriteString(fmt.Sprintf("! %s (mode: %s)\n", address, renderTransition(origMode, newMode, opts)))
+		modeChanges = append(modeChanges, map[string]interface{}{
+			"address":  address,
+			"old_mode": origMode,
+			"new_mode": newMode,
+		})
+	}
+
+	return modeChanges
+}
@@ -0,0 +1,60 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareResources_ModeChanged(t *testing.T) {
+	makeResource := func(mode, bucket string) map[string]interface{} {
+		return map[string]interface{}{
+			"mode":   mode,
+			"values": map[string]interface{}{"bucket": bucket, "arn": "arn:aws:s3:::" + bucket},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		origRes     map[string]interface{}
+		newRes      map[string]interface{}
+		contains    []string
+		notContains []string
+		modeChanges []map[string]interface{}
+	}{
+		{
+			name:        "managed resource became a data source",
+			origRes:     map[string]interface{}{"aws_s3_bucket.logs": makeResource("managed", "logs")},
+			newRes:      map[string]interface{}{"aws_s3_bucket.logs": makeResource("data", "logs-v2")},
+			contains:    []string{"! aws_s3_bucket.logs (mode: managed => data)"},
+			notContains: []string{"~ bucket", "~ arn"},
+			modeChanges: []map[string]interface{}{{
+				"address":  "aws_s3_bucket.logs",
+				"old_mode": "managed",
+				"new_mode": "data",
+			}},
+		},
+		{
+			name:        "same mode is ordinary attribute churn",
+			origRes:     map[string]interface{}{"aws_s3_bucket.logs": makeResource("managed", "logs")},
+			newRes:      map[string]interface{}{"aws_s3_bucket.logs": makeResource("managed", "logs-v2")},
+			contains:    []string{"~ bucket: logs => logs-v2"},
+			notContains: []string{"(mode:"},
+			modeChanges: []map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, diffMap := compareResources(tc.origRes, tc.newRes, &CompareOptions{})
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff, notExpected)
+			}
+			assert.Equal(t, tc.modeChanges, diffMap["mode_changed"])
+		})
+	}
+}
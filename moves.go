@@ -0,0 +1,119 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// modulePathPattern matches the leading module path of a resource address, e.g. `module.app["x"].`.
+var modulePathPattern = regexp.MustCompile(`^(module\.[^.\[]+(\[[^\]]*\])?\.)+`)
+
+// resourceMove describes a resource that was removed at one address and added at another.
+type resourceMove struct {
+	from string
+	to   string
+}
+
+// moveMatchers decide whether a removed and an added address may refer to the same resource, from
+// the strictest to the loosest, so a removal pairs with its closest matching addition first.
+var moveMatchers = []func(from, to string) bool{
+	// Moved into or out of a module
+	func(from, to string) bool {
+		return stripModulePath(from) == stripModulePath(to)
+	},
+	// Instance key changed, e.g. from count to for_each
+	func(from, to string) bool {
+		return configAddress(stripModulePath(from)) == configAddress(stripModulePath(to))
+	},
+	// Renamed, e.g. by a moved block
+	func(from, to string) bool {
+		return addressTypePrefix(from) == addressTypePrefix(to)
+	},
+}
+
+// detectMoves pairs removed and added addresses that refer to the same resource.
+// A pair matches when both sides have the same identity and the addresses only differ in their
+// module path, their instance key or their name; the resource type and mode must stay the same.
+// Both address lists must be sorted. The moves are sorted by their old address.
+func detectMoves(removedAddrs, addedAddrs []string, origResources, newResources map[string]interface{}, opts *CompareOptions) []resourceMove {
+	moves := make([]resourceMove, 0)
+	claimed := make(map[string]bool)
+
+	for _, matches := range moveMatchers {
+		for _, from := range removedAddrs {
+			if claimed[from] {
+				continue
+			}
+
+			for _, to := range addedAddrs {
+				if claimed[to] || !matches(from, to) {
+					continue
+				}
+
+				if sameResourceIdentity(origResources[from], newResources[to], opts) {
+					moves = append(moves, resourceMove{from: from, to: to})
+					claimed[from] = true
+					claimed[to] = true
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(moves, func(i, j int) bool { return moves[i].from < moves[j].from })
+	return moves
+}
+
+// stripModulePath removes the module path from a resource address.
+func stripModulePath(address string) string {
+	return modulePathPattern.ReplaceAllString(address, "")
+}
+
+// addressTypePrefix returns the mode and type of a resource address, e.g. `aws_instance` or `data.aws_ami`.
+func addressTypePrefix(address string) string {
+	rest := configAddress(stripModulePath(address))
+	if idx := strings.LastIndex(rest, "."); idx != -1 {
+		return rest[:idx]
+	}
+	return rest
+}
+
+// sameResourceIdentity reports whether two resources have identical attributes or the same id.
+// Identity is decided on all attributes, since ProjectAttributes and AttributeAllowlist only restrict what
+// is reported as changed.
+func sameResourceIdentity(origResource, newResource interface{}, opts *CompareOptions) bool {
+	identityOpts := *opts
+	identityOpts.ProjectAttributes, identityOpts.AttributeAllowlist = nil, nil
+
+	origAttrs := getComparedAttributes(origResource, &identityOpts)
+	newAttrs := getComparedAttributes(newResource, &identityOpts)
+
+	if len(origAttrs) > 0 && reflect.DeepEqual(origAttrs, newAttrs) {
+		return true
+	}
+
+	origID, origOk := origAttrs["id"]
+	newID, newOk := newAttrs["id"]
+	return origOk && newOk && origID != nil && origID != "" && reflect.DeepEqual(origID, newID)
+}
+
+// processMovedResource writes a moved resource and any attribute changes that came with the move.
+func processMovedResource(diff *strings.Builder, move resourceMove, origResource, newResource interface{}, opts *CompareOptions) map[string]interface{} {
+	diff.WriteString(fmt.Sprintf("~ %s (moved)\n", renderTransition(move.from, move.to, opts)))
+
+	entry := map[string]interface{}{
+		"old_address": move.from,
+		"new_address": move.to,
+	}
+
+	origAttrs := getResourceAttributes(origResource, opts)
+	newAttrs := getResourceAttributes(newResource, opts)
+	if !reflect.DeepEqual(origAttrs, newAttrs) {
+		entry["attributes"] = processAttributeDifferences(diff, origAttrs, newAttrs, opts.attributeOrder(move.to), opts)
+	}
+
+	return entry
+}
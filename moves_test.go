@@ -0,0 +1,177 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareResources_MovedIntoModule(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-123", "instance_type": "t3.micro"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"module.app.aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-123", "instance_type": "t3.micro"},
+		},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{})
+
+	assert.Contains(t, diff, "~ aws_instance.web => module.app.aws_instance.web (moved)")
+	assert.NotContains(t, diff, "+ module.app.aws_instance.web")
+	assert.NotContains(t, diff, "- aws_instance.web")
+	assert.Empty(t, diffMap["added"])
+	assert.Empty(t, diffMap["removed"])
+	assert.Equal(t, []map[string]interface{}{{
+		"old_address": "aws_instance.web",
+		"new_address": "module.app.aws_instance.web",
+	}}, diffMap["moved"])
+}
+
+func TestCompareResources_MovedWithAttributeChanges(t *testing.T) {
+	origRes := map[string]interface{}{
+		`module.old["a"].aws_instance.web`: map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-123", "instance_type": "t3.micro"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"module.app.aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-123", "instance_type": "t3.large"},
+		},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{})
+
+	assert.Contains(t, diff, "(moved)")
+	assert.Contains(t, diff, "~ instance_type: t3.micro => t3.large")
+	assert.Len(t, diffMap["moved"], 1)
+}
+
+func TestCompareResources_DifferentResourceNotMoved(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-123"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"module.app.aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-456"},
+		},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{})
+
+	assert.Contains(t, diff, "+ module.app.aws_instance.web")
+	assert.Contains(t, diff, "- aws_instance.web")
+	assert.Empty(t, diffMap["moved"])
+}
+
+func TestCompareResources_ProjectedAttributesDoNotDecideIdentity(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.a": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-1", "ami": "ami-1"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.b": map[string]interface{}{
+			"values": map[string]interface{}{"id": "i-2", "ami": "ami-1"},
+		},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{ProjectAttributes: []string{"ami"}})
+
+	assert.Contains(t, diff, "+ aws_instance.b")
+	assert.Contains(t, diff, "- aws_instance.a")
+	assert.Empty(t, diffMap["moved"])
+
+	// The same id is still a move
+	newRes["aws_instance.b"] = map[string]interface{}{
+		"values": map[string]interface{}{"id": "i-1", "ami": "ami-2"},
+	}
+	diff, diffMap = compareResources(origRes, newRes, &CompareOptions{ProjectAttributes: []string{"ami"}})
+
+	assert.Contains(t, diff, "~ aws_instance.a => aws_instance.b (moved)")
+	assert.Contains(t, diff, "~ ami: ami-1 => ami-2")
+	assert.Len(t, diffMap["moved"], 1)
+}
+
+func TestStripModulePath(t *testing.T) {
+	assert.Equal(t, "aws_instance.web", stripModulePath("aws_instance.web"))
+	assert.Equal(t, "aws_instance.web[0]", stripModulePath(`module.a.module.b["x"].aws_instance.web[0]`))
+}
+
+func TestCompareResources_MovedAcrossAddresses(t *testing.T) {
+	makeResource := func(id, instanceType string) map[string]interface{} {
+		return map[string]interface{}{
+			"values": map[string]interface{}{"id": id, "instance_type": instanceType},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		origRes  map[string]interface{}
+		newRes   map[string]interface{}
+		contains []string
+		moved    []map[string]interface{}
+	}{
+		{
+			name:     "renamed by a moved block",
+			origRes:  map[string]interface{}{"aws_instance.foo": makeResource("i-123", "t3.micro")},
+			newRes:   map[string]interface{}{"aws_instance.bar": makeResource("i-123", "t3.micro")},
+			contains: []string{"~ aws_instance.foo => aws_instance.bar (moved)"},
+			moved:    []map[string]interface{}{{"old_address": "aws_instance.foo", "new_address": "aws_instance.bar"}},
+		},
+		{
+			name:     "count index became a for_each key",
+			origRes:  map[string]interface{}{"aws_instance.web[0]": makeResource("i-123", "t3.micro")},
+			newRes:   map[string]interface{}{`aws_instance.web["a"]`: makeResource("i-123", "t3.micro")},
+			contains: []string{`~ aws_instance.web[0] => aws_instance.web["a"] (moved)`},
+			moved:    []map[string]interface{}{{"old_address": "aws_instance.web[0]", "new_address": `aws_instance.web["a"]`}},
+		},
+		{
+			name: "instance key match wins over a rename",
+			origRes: map[string]interface{}{
+				`aws_instance.web["a"]`: makeResource("", "t3.micro"),
+			},
+			newRes: map[string]interface{}{
+				"aws_instance.other":    makeResource("", "t3.micro"),
+				`aws_instance.web["b"]`: makeResource("", "t3.micro"),
+			},
+			contains: []string{`~ aws_instance.web["a"] => aws_instance.web["b"] (moved)`, "+ aws_instance.other"},
+			moved:    []map[string]interface{}{{"old_address": `aws_instance.web["a"]`, "new_address": `aws_instance.web["b"]`}},
+		},
+		{
+			name:     "different type is not a move",
+			origRes:  map[string]interface{}{"aws_instance.foo": makeResource("i-123", "t3.micro")},
+			newRes:   map[string]interface{}{"aws_spot_instance_request.foo": makeResource("i-123", "t3.micro")},
+			contains: []string{"- aws_instance.foo", "+ aws_spot_instance_request.foo"},
+			moved:    []map[string]interface{}{},
+		},
+		{
+			name:     "data source is not a managed resource",
+			origRes:  map[string]interface{}{"data.aws_instance.foo": makeResource("i-123", "t3.micro")},
+			newRes:   map[string]interface{}{"aws_instance.foo": makeResource("i-123", "t3.micro")},
+			contains: []string{"- data.aws_instance.foo", "+ aws_instance.foo"},
+			moved:    []map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, diffMap := compareResources(tc.origRes, tc.newRes, &CompareOptions{})
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			assert.Equal(t, tc.moved, diffMap["moved"])
+		})
+	}
+}
+
+func TestAddressTypePrefix(t *testing.T) {
+	assert.Equal(t, "aws_instance", addressTypePrefix(`module.a["x"].aws_instance.web["k.v"]`))
+	assert.Equal(t, "data.aws_ami", addressTypePrefix("data.aws_ami.ubuntu"))
+}
@@ -0,0 +1,81 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultMaxAttributeDepth is the number of nested map levels diffed key by key when MaxAttributeDepth is zero.
+const defaultMaxAttributeDepth = 10
+
+// maxAttributeDepth returns the number of nested map levels to diff key by key.
+func (o *CompareOptions) maxAttributeDepth() int {
+	if o.MaxAttributeDepth == 0 {
+		return defaultMaxAttributeDepth
+	}
+	return o.MaxAttributeDepth
+}
+
+// processAttributeChange writes a changed attribute and returns its diff map entry. When both values are
+// maps within the depth limit, only their changed leaf keys are written, as `path.key`, and the entry
+// carries them in a nested attributes structure shaped like the resource attributes. Lists within the
+// depth limit are diffed element by element.
+func processAttributeChange(diff *strings.Builder, path, name string, origAttrV, newAttrV interface{}, depth int, opts *CompareOptions) map[string]interface{} {
+	return processValueChange(diff, path, map[string]interface{}{"name": name}, origAttrV, newAttrV, depth, opts)
+}
+
+// processValueChange writes a changed value at path and completes its diff map entry with the old and
+// new values and, for maps and lists within the depth limit, their nested changes.
+func processValueChange(diff *strings.Builder, path string, entry map[string]interface{}, origV, newV interface{}, depth int, opts *CompareOptions) map[string]interface{} {
+	entry["old"] = origV
+	entry["new"] = newV
+
+	if depth < opts.maxAttributeDepth() && !isSensitive(origV) && !isSensitive(newV) {
+		switch origTyped := origV.(type) {
+		case map[string]interface{}:
+			if newMap, ok := newV.(map[string]interface{}); ok {
+				entry["attributes"] = processNestedAttributeChanges(diff, path, origTyped, newMap, depth+1, opts)
+				return entry
+			}
+		case []interface{}:
+			if newSlice, ok := newV.([]interface{}); ok {
+				entry["elements"] = compareSlices(diff, path, origTyped, newSlice, depth+1, opts)
+				return entry
+			}
+		}
+	}
+
+	printAttributeDiff(diff, path, origV, newV, opts)
+	return entry
+}
+
+// processNestedAttributeChanges writes the changed keys of two nested map values in sorted order and
+// returns them as added, removed and changed entries.
+func processNestedAttributeChanges(diff *strings.Builder, path string, origMap, newMap map[string]interface{}, depth int, opts *CompareOptions) map[string]interface{} {
+	added := make([]map[string]interface{}, 0)
+	removed := make([]map[string]interface{}, 0)
+	changed := make([]map[string]interface{}, 0)
+
+	for _, k := range getSortedKeys(origMap, newMap) {
+		origV, origExists := origMap[k]
+		newV, newExists := newMap[k]
+		keyPath := joinPath(path, k)
+
+		switch {
+		case !origExists:
+			diff.WriteString(fmt.Sprintf("  + %s: %v\n", keyPath, formatValue(newV, opts)))
+			added = append(added, map[string]interface{}{"name": k, "value": newV})
+		case !newExists:
+			diff.WriteString(fmt.Sprintf("  - %s: %v\n", keyPath, formatValue(origV, opts)))
+			removed = append(removed, map[string]interface{}{"name": k, "value": origV})
+		case !valuesEqual(origV, newV, opts):
+			changed = append(changed, processAttributeChange(diff, keyPath, k, origV, newV, depth, opts))
+		}
+	}
+
+	return map[string]interface{}{
+		"added":   added,
+		"removed": removed,
+		"changed": changed,
+	}
+}
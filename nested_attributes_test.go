@@ -0,0 +1,92 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessAttributeDifferences_NestedMaps(t *testing.T) {
+	origAttrs := map[string]interface{}{
+		"tags": map[string]interface{}{"Environment": "dev", "Owner": "team-a", "Name": "web"},
+		"settings": map[string]interface{}{
+			"logging": map[string]interface{}{"level": "info", "retention": 7},
+		},
+	}
+	newAttrs := map[string]interface{}{
+		"tags": map[string]interface{}{"Environment": "prod", "CostCenter": "42", "Name": "web"},
+		"settings": map[string]interface{}{
+			"logging": map[string]interface{}{"level": "debug", "retention": 7},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		opts        *CompareOptions
+		contains    []string
+		notContains []string
+		tags        interface{}
+	}{
+		{
+			name: "default diffs leaf keys",
+			opts: &CompareOptions{},
+			contains: []string{
+				"  + tags.CostCenter: 42\n",
+				"  ~ tags.Environment: dev => prod\n",
+				"  - tags.Owner: team-a\n",
+				"  ~ settings.logging.level: info => debug\n",
+			},
+			notContains: []string{"tags.Name", "retention", "~ tags:"},
+			tags: map[string]interface{}{
+				"added":   []map[string]interface{}{{"name": "CostCenter", "value": "42"}},
+				"removed": []map[string]interface{}{{"name": "Owner", "value": "team-a"}},
+				"changed": []map[string]interface{}{{"name": "Environment", "old": "dev", "new": "prod"}},
+			},
+		},
+		{
+			name:        "depth limit reports deeper maps whole",
+			opts:        &CompareOptions{MaxAttributeDepth: 1},
+			contains:    []string{"  ~ tags.Environment: dev => prod\n", "  ~ settings.logging: "},
+			notContains: []string{"settings.logging.level"},
+		},
+		{
+			name:        "negative depth disables nested diffs",
+			opts:        &CompareOptions{MaxAttributeDepth: -1},
+			contains:    []string{"  ~ tags: ", "  ~ settings: "},
+			notContains: []string{"tags.Environment"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var diff strings.Builder
+			attrChanges := processAttributeDifferences(&diff, origAttrs, newAttrs, nil, tc.opts)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff.String(), expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff.String(), notExpected)
+			}
+
+			if tc.tags != nil {
+				for _, entry := range entryList(attrChanges["changed"]) {
+					if entry["name"] == "tags" {
+						assert.Equal(t, tc.tags, entry["attributes"])
+						assert.Equal(t, origAttrs["tags"], entry["old"])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProcessAttributeChange_SensitiveMap(t *testing.T) {
+	var diff strings.Builder
+	entry := processAttributeChange(&diff, "secret", "secret",
+		map[string]interface{}{"sensitive": true}, map[string]interface{}{"value": "x"}, 0, &CompareOptions{})
+
+	assert.Equal(t, "  ~ secret: (sensitive value) => x\n", diff.String())
+	assert.NotContains(t, entry, "attributes")
+}
@@ -0,0 +1,59 @@
+package comparison
+
+import "regexp"
+
+// defaultNoisePatterns recognize generated values when NoisePatterns is not set: RFC 3339 timestamps,
+// UUIDs, hex digests such as S3 etags, and unique names generated by Terraform like
+// `terraform-20240102030405060700000001`.
+var defaultNoisePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?$`),
+	regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+	regexp.MustCompile(`^"?(?i)[0-9a-f]{32,64}(-\d+)?"?$`),
+	regexp.MustCompile(`^[\w-]*\d{26}$`),
+}
+
+// noisePatterns returns the patterns of generated values used by NoiseHeuristics.
+func (o *CompareOptions) noisePatterns() []*regexp.Regexp {
+	if len(o.NoisePatterns) > 0 {
+		return o.NoisePatterns
+	}
+	return defaultNoisePatterns
+}
+
+// separateNoise removes the changed attributes whose old and new values both match the same noise pattern
+// from both attribute sets, and returns them with their old and new values, ordered by name.
+func separateNoise(origAttrs, newAttrs map[string]interface{}, opts *CompareOptions) []map[string]interface{} {
+	noise := make([]map[string]interface{}, 0)
+	if !opts.NoiseHeuristics {
+		return noise
+	}
+
+	patterns := opts.noisePatterns()
+	for _, k := range getSortedKeys(origAttrs, nil) {
+		origV, origOk := origAttrs[k].(string)
+		newV, newOk := newAttrs[k].(string)
+		if !origOk || !newOk || origV == newV || !matchBothValues(patterns, origV, newV) {
+			continue
+		}
+
+		delete(origAttrs, k)
+		delete(newAttrs, k)
+		noise = append(noise, map[string]interface{}{
+			"name": k,
+			"old":  origV,
+			"new":  newV,
+		})
+	}
+
+	return noise
+}
+
+// matchBothValues reports whether one of the patterns matches both values.
+func matchBothValues(patterns []*regexp.Regexp, a, b string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(a) && pattern.MatchString(b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package comparison
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeparateNoise(t *testing.T) {
+	tests := []struct {
+		name          string
+		orig, new     map[string]interface{}
+		patterns      []*regexp.Regexp
+		expectedNoise []string
+	}{
+		{
+			name:          "timestamps",
+			orig:          map[string]interface{}{"last_modified": "2024-01-02T03:04:05Z"},
+			new:           map[string]interface{}{"last_modified": "2024-03-04T05:06:07.123+02:00"},
+			expectedNoise: []string{"last_modified"},
+		},
+		{
+			name:          "uuids and etags",
+			orig:          map[string]interface{}{"request_id": "3f2b8c1e-9d4a-4e6f-8a7b-1c2d3e4f5a6b", "etag": `"9e107d9d372bb6826bd81d3542a419d6"`},
+			new:           map[string]interface{}{"request_id": "A1B2C3D4-E5F6-4789-8ABC-DEF012345678", "etag": `"e4d909c290d0fb1ca068ffaddf22cbd0"`},
+			expectedNoise: []string{"etag", "request_id"},
+		},
+		{
+			name:          "terraform-generated names",
+			orig:          map[string]interface{}{"name": "terraform-20240102030405060700000001"},
+			new:           map[string]interface{}{"name": "terraform-20240304050607080900000002"},
+			expectedNoise: []string{"name"},
+		},
+		{
+			name:          "only one side looks generated",
+			orig:          map[string]interface{}{"last_modified": "yesterday"},
+			new:           map[string]interface{}{"last_modified": "2024-03-04T05:06:07Z"},
+			expectedNoise: []string{},
+		},
+		{
+			name:          "values matching different patterns",
+			orig:          map[string]interface{}{"value": "2024-01-02T03:04:05Z"},
+			new:           map[string]interface{}{"value": "3f2b8c1e-9d4a-4e6f-8a7b-1c2d3e4f5a6b"},
+			expectedNoise: []string{},
+		},
+		{
+			name:          "overridden patterns",
+			orig:          map[string]interface{}{"build": "build-41", "last_modified": "2024-01-02T03:04:05Z"},
+			new:           map[string]interface{}{"build": "build-42", "last_modified": "2024-03-04T05:06:07Z"},
+			patterns:      []*regexp.Regexp{regexp.MustCompile(`^build-\d+$`)},
+			expectedNoise: []string{"build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noise := separateNoise(tt.orig, tt.new, &CompareOptions{NoiseHeuristics: true, NoisePatterns: tt.patterns})
+
+			names := make([]string, 0, len(noise))
+			for _, entry := range noise {
+				names = append(names, entryKey(entry))
+				assert.NotContains(t, tt.orig, entryKey(entry))
+				assert.NotContains(t, tt.new, entryKey(entry))
+			}
+			assert.Equal(t, tt.expectedNoise, names)
+		})
+	}
+}
+
+func TestComparePlans_NoiseHeuristics(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_s3_object.site", "change": {"after": {"key": "index.html", "last_modified": "2024-01-02T03:04:05Z"}}},
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "last_modified": "2024-01-02T03:04:05Z"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_s3_object.site", "change": {"after": {"key": "index.html", "last_modified": "2024-03-04T05:06:07Z"}}},
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "last_modified": "2024-03-04T05:06:07Z"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{NoiseHeuristics: true})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.NotContains(t, result.Text, "last_modified")
+	assert.NotContains(t, result.Text, "aws_s3_object.site")
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+	}, actualChanges(result.Changes))
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	assert.Equal(t, []map[string]interface{}{
+		{"address": "aws_instance.web", "name": "last_modified", "old": "2024-01-02T03:04:05Z", "new": "2024-03-04T05:06:07Z"},
+		{"address": "aws_s3_object.site", "name": "last_modified", "old": "2024-01-02T03:04:05Z", "new": "2024-03-04T05:06:07Z"},
+	}, resources["noise"])
+
+	// The noise is kept when it is the only difference
+	result, err = ComparePlans(origJSON, `{"resource_changes": [
+		{"address": "aws_s3_object.site", "change": {"after": {"key": "index.html", "last_modified": "2024-03-04T05:06:07Z"}}},
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "last_modified": "2024-01-02T03:04:05Z"}}}
+	]}`, &CompareOptions{NoiseHeuristics: true})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.Len(t, result.Changes["resources"].(map[string]interface{})["noise"], 1)
+
+	// Without the option the timestamps are changes
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "last_modified")
+	assert.NotContains(t, result.Changes["resources"], "noise")
+}
@@ -0,0 +1,249 @@
+package comparison
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Reasons recorded for attribute changes that a normalization rule suppressed.
+const (
+	reasonProviderNoise = "provider_noise"
+	reasonCase          = "case"
+	reasonJSON          = "json"
+	reasonWhitespace    = "whitespace"
+	reasonEmpty         = "empty"
+)
+
+// normalizationRule is a comparison-time normalization applied to both attribute sets of a resource.
+type normalizationRule struct {
+	reason string
+	apply  func(origAttrs, newAttrs map[string]interface{}) bool
+}
+
+// normalizationRules returns the normalization rules enabled by opts, in the order they are applied.
+func normalizationRules(resource interface{}, opts *CompareOptions) []normalizationRule {
+	rules := make([]normalizationRule, 0)
+
+	if opts.SuppressKnownProviderNoise {
+		provider := providerShortName(resource)
+		rules = append(rules, normalizationRule{reasonProviderNoise, func(origAttrs, newAttrs map[string]interface{}) bool {
+			return suppressProviderNoise(provider, origAttrs, newAttrs)
+		}})
+	}
+
+	if opts.CanonicalizeJSONStrings {
+		rules = append(rules, normalizationRule{reasonJSON, canonicalizeJSONStrings})
+	}
+
+	if opts.IgnoreWhitespace {
+		rules = append(rules, normalizationRule{reasonWhitespace, collapseWhitespace})
+	}
+
+	if opts.IgnoreEmptyStringNullEquivalence {
+		kinds := opts.emptyValueKinds()
+		rules = append(rules, normalizationRule{reasonEmpty, func(origAttrs, newAttrs map[string]interface{}) bool {
+			return equateEmptyValues(origAttrs, newAttrs, kinds)
+		}})
+	}
+
+	return rules
+}
+
+// normalizeAttributeSets applies the comparison-time normalizations to both attribute sets of a resource.
+// It reports whether any normalization is active, in which case the sets must be compared again.
+// With ReportNormalized set it also returns the attribute changes that a rule resolved to equal values,
+// each with the original values and the reason.
+func normalizeAttributeSets(resource interface{}, origAttrs, newAttrs map[string]interface{}, opts *CompareOptions) (bool, []map[string]interface{}) {
+	normalized := len(opts.ProjectAttributes) > 0 || len(opts.AttributeAllowlist) > 0
+	normalizedAway := make([]map[string]interface{}, 0)
+
+	// Rules rewrite nested blocks in place, and the attribute sets share them with the parsed plan
+	rules := normalizationRules(resource, opts)
+	if len(rules) > 0 {
+		detachNestedValues(origAttrs)
+		detachNestedValues(newAttrs)
+	}
+
+	for _, rule := range rules {
+		var origBefore, newBefore map[string]interface{}
+		if opts.ReportNormalized {
+			// Keep deep copies of the values before the rule.
+			// The attributes come from a plan already checked for cycles.
+			origBefore, _ = sortMapKeys(origAttrs)
+			newBefore, _ = sortMapKeys(newAttrs)
+		}
+
+		if !rule.apply(origAttrs, newAttrs) {
+			continue
+		}
+		normalized = true
+
+		if opts.ReportNormalized {
+			normalizedAway = append(normalizedAway, resolvedChanges(rule.reason, origBefore, newBefore, origAttrs, newAttrs)...)
+		}
+	}
+
+	return normalized, normalizedAway
+}
+
+// detachNestedValues replaces the nested maps and lists of an attribute set with deep copies, so rewriting
+// them leaves the plan they were read from untouched. The attributes come from a plan already checked for
+// cycles.
+func detachNestedValues(attrs map[string]interface{}) {
+	for k, v := range attrs {
+		attrs[k], _ = processValue(v, cycleGuard{})
+	}
+}
+
+// resolvedChanges lists the attributes that differed before a rule was applied and are equal afterwards.
+func resolvedChanges(reason string, origBefore, newBefore, origAttrs, newAttrs map[string]interface{}) []map[string]interface{} {
+	resolved := make([]map[string]interface{}, 0)
+
+	for _, k := range getSortedKeys(origBefore, newBefore) {
+		if reflect.DeepEqual(origBefore[k], newBefore[k]) || !reflect.DeepEqual(origAttrs[k], newAttrs[k]) {
+			continue
+		}
+
+		// Provider noise covers both ignored attributes and case-insensitive identifiers
+		ruleReason := reason
+		if reason == reasonProviderNoise && equalFoldValues(origBefore[k], newBefore[k]) {
+			ruleReason = reasonCase
+		}
+
+		resolved = append(resolved, map[string]interface{}{
+			"name":   k,
+			"reason": ruleReason,
+			"old":    origBefore[k],
+			"new":    newBefore[k],
+		})
+	}
+
+	return resolved
+}
+
+// sortNormalizedAway orders normalized-away and noise entries by resource address and attribute name.
+func sortNormalizedAway(entries []map[string]interface{}) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i]["address"] != entries[j]["address"] {
+			return entries[i]["address"].(string) < entries[j]["address"].(string)
+		}
+		return entries[i]["name"].(string) < entries[j]["name"].(string)
+	})
+}
+
+// collapseWhitespace rewrites string attribute values that are equal once leading, trailing and repeated
+// whitespace is collapsed, recursing into nested maps. It reports whether any value was rewritten.
+func collapseWhitespace(origAttrs, newAttrs map[string]interface{}) bool {
+	rewritten := false
+
+	for k, origV := range origAttrs {
+		newV, exists := newAttrs[k]
+		if !exists || reflect.DeepEqual(origV, newV) {
+			continue
+		}
+
+		// Recurse into nested blocks
+		origMap, origIsMap := origV.(map[string]interface{})
+		newMap, newIsMap := newV.(map[string]interface{})
+		if origIsMap && newIsMap {
+			if collapseWhitespace(origMap, newMap) {
+				rewritten = true
+			}
+			continue
+		}
+
+		origStr, origOk := origV.(string)
+		newStr, newOk := newV.(string)
+		if !origOk || !newOk {
+			continue
+		}
+
+		origAttrs[k] = strings.Join(strings.Fields(origStr), " ")
+		newAttrs[k] = strings.Join(strings.Fields(newStr), " ")
+		rewritten = true
+	}
+
+	return rewritten
+}
+
+// canonicalizeJSONStrings rewrites attribute values that hold JSON documents on both sides into a
+// canonical form, recursing into nested maps and lists. It reports whether any value was rewritten.
+func canonicalizeJSONStrings(origAttrs, newAttrs map[string]interface{}) bool {
+	rewritten := false
+
+	for k, origV := range origAttrs {
+		newV, exists := newAttrs[k]
+		if !exists {
+			continue
+		}
+
+		if origCanonical, newCanonical, ok := canonicalizeJSONPair(origV, newV); ok {
+			origAttrs[k], newAttrs[k] = origCanonical, newCanonical
+			rewritten = true
+		}
+	}
+
+	return rewritten
+}
+
+// canonicalizeJSONPair returns two differing values with the JSON documents they hold on both sides in
+// canonical form, recursing into nested maps and into the elements both lists have. Nested maps and
+// lists are rewritten in place. It reports whether any value was rewritten.
+func canonicalizeJSONPair(origV, newV interface{}) (interface{}, interface{}, bool) {
+	if reflect.DeepEqual(origV, newV) {
+		return origV, newV, false
+	}
+
+	switch origTyped := origV.(type) {
+	case map[string]interface{}:
+		if newMap, ok := newV.(map[string]interface{}); ok {
+			return origV, newV, canonicalizeJSONStrings(origTyped, newMap)
+		}
+	case []interface{}:
+		if newList, ok := newV.([]interface{}); ok {
+			rewritten := false
+			for i := 0; i < len(origTyped) && i < len(newList); i++ {
+				if origElem, newElem, ok := canonicalizeJSONPair(origTyped[i], newList[i]); ok {
+					origTyped[i], newList[i] = origElem, newElem
+					rewritten = true
+				}
+			}
+			return origV, newV, rewritten
+		}
+	}
+
+	origCanonical, origOk := canonicalJSON(origV)
+	newCanonical, newOk := canonicalJSON(newV)
+	if !origOk || !newOk {
+		return origV, newV, false
+	}
+	return origCanonical, newCanonical, true
+}
+
+// canonicalJSON returns the canonical encoding of a string holding a JSON object or array.
+func canonicalJSON(value interface{}) (string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return "", false
+	}
+
+	trimmed := strings.TrimSpace(str)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return "", false
+	}
+
+	// encoding/json writes map keys in sorted order without insignificant whitespace
+	canonical, err := json.Marshal(parsed)
+	if err != nil {
+		return "", false
+	}
+
+	return string(canonical), true
+}
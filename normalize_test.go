@@ -0,0 +1,205 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeJSONStrings(t *testing.T) {
+	makeResources := func(policy string) map[string]interface{} {
+		return map[string]interface{}{
+			"aws_iam_policy.p": map[string]interface{}{
+				"values": map[string]interface{}{"policy": policy},
+			},
+		}
+	}
+
+	origPolicy := `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:GetObject"}]}`
+	reordered := "{\n  \"Statement\": [\n    {\"Action\": \"s3:GetObject\", \"Effect\": \"Allow\"}\n  ],\n  \"Version\": \"2012-10-17\"\n}"
+	changed := `{"Version": "2012-10-17", "Statement": [{"Effect": "Deny", "Action": "s3:GetObject"}]}`
+
+	tests := []struct {
+		name       string
+		newPolicy  string
+		canonical  bool
+		expectDiff bool
+	}{
+		{name: "reordered policy differs without canonicalization", newPolicy: reordered, canonical: false, expectDiff: true},
+		{name: "reordered policy equal with canonicalization", newPolicy: reordered, canonical: true, expectDiff: false},
+		{name: "real policy change still reported", newPolicy: changed, canonical: true, expectDiff: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &CompareOptions{CanonicalizeJSONStrings: tc.canonical}
+			diff, _ := compareResources(makeResources(origPolicy), makeResources(tc.newPolicy), opts)
+
+			if tc.expectDiff {
+				assert.Contains(t, diff, "aws_iam_policy.p")
+			} else {
+				assert.Empty(t, diff)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeJSONStrings_NestedValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		origAttrs map[string]interface{}
+		newAttrs  map[string]interface{}
+		rewritten bool
+		equal     bool
+	}{
+		{
+			name:      "JSON strings in a list",
+			origAttrs: map[string]interface{}{"policies": []interface{}{`{"a": 1, "b": 2}`, `[1, 2]`}},
+			newAttrs:  map[string]interface{}{"policies": []interface{}{`{"b":2,"a":1}`, "[1,\n 2]"}},
+			rewritten: true,
+			equal:     true,
+		},
+		{
+			name: "JSON strings in blocks of a list",
+			origAttrs: map[string]interface{}{"statement": []interface{}{
+				map[string]interface{}{"policy": `{"a": 1, "b": 2}`, "sid": "one"},
+			}},
+			newAttrs: map[string]interface{}{"statement": []interface{}{
+				map[string]interface{}{"policy": `{"b": 2, "a": 1}`, "sid": "one"},
+			}},
+			rewritten: true,
+			equal:     true,
+		},
+		{
+			name:      "lists of different lengths compare their shared elements",
+			origAttrs: map[string]interface{}{"policies": []interface{}{`{"a": 1, "b": 2}`}},
+			newAttrs:  map[string]interface{}{"policies": []interface{}{`{"b": 2, "a": 1}`, `{}`}},
+			rewritten: true,
+		},
+		{
+			name:      "non-JSON strings are untouched",
+			origAttrs: map[string]interface{}{"name": "a  b", "tags": []interface{}{"{x}"}},
+			newAttrs:  map[string]interface{}{"name": "a b", "tags": []interface{}{"{ x }"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.rewritten, canonicalizeJSONStrings(tc.origAttrs, tc.newAttrs))
+			assert.Equal(t, tc.equal, assert.ObjectsAreEqual(tc.origAttrs, tc.newAttrs))
+		})
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	canonical, ok := canonicalJSON(`{ "b": 1, "a": [1, 2] }`)
+	assert.True(t, ok)
+	assert.Equal(t, `{"a":[1,2],"b":1}`, canonical)
+
+	_, ok = canonicalJSON("123")
+	assert.False(t, ok)
+
+	_, ok = canonicalJSON("{not json")
+	assert.False(t, ok)
+}
+
+func TestReportNormalized(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"user_data": "echo  hello\n", "instance_type": "t3.micro"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"user_data": "echo hello", "instance_type": "t3.large"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		opts           *CompareOptions
+		normalizedAway interface{}
+	}{
+		{
+			name: "whitespace change reported with reason",
+			opts: &CompareOptions{IgnoreWhitespace: true, ReportNormalized: true},
+			normalizedAway: []map[string]interface{}{{
+				"address": "aws_instance.web",
+				"name":    "user_data",
+				"reason":  "whitespace",
+				"old":     "echo  hello\n",
+				"new":     "echo hello",
+			}},
+		},
+		{
+			name: "diagnostics off by default",
+			opts: &CompareOptions{IgnoreWhitespace: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, diffMap := compareResources(origRes, newRes, tc.opts)
+
+			assert.Contains(t, diff, "~ instance_type: t3.micro => t3.large")
+			assert.NotContains(t, diff, "user_data")
+			assert.Equal(t, tc.normalizedAway, diffMap["normalized_away"])
+		})
+	}
+}
+
+func TestReportNormalized_OnlyNormalizedChanges(t *testing.T) {
+	plan := func(id string) string {
+		return `{"resource_changes": [{
+			"address": "azurerm_resource_group.rg",
+			"provider_name": "registry.terraform.io/hashicorp/azurerm",
+			"change": {"after": {"id": "` + id + `"}}
+		}]}`
+	}
+
+	opts := &CompareOptions{SuppressKnownProviderNoise: true, ReportNormalized: true}
+	result, err := ComparePlans(plan("/subscriptions/abc/resourceGroups/RG"), plan("/subscriptions/abc/resourcegroups/rg"), opts)
+	require.NoError(t, err)
+
+	assert.False(t, result.HasDiff)
+	assert.Empty(t, result.Text)
+	resources, ok := result.Changes["resources"].(map[string]interface{})
+	require.True(t, ok)
+	entries := entryList(resources["normalized_away"])
+	require.Len(t, entries, 1)
+	assert.Equal(t, "case", entries[0]["reason"])
+	assert.Equal(t, "id", entries[0]["name"])
+}
+
+func TestNormalizeAttributeSets_LeavesPlanUntouched(t *testing.T) {
+	plan := func(policy, command string) map[string]interface{} {
+		return map[string]interface{}{
+			"values": map[string]interface{}{
+				"policies": []interface{}{policy},
+				"settings": map[string]interface{}{"command": command, "empty": ""},
+			},
+		}
+	}
+	origResource := plan(`{"b": 1, "a": 2}`, "echo  hello")
+	newResource := plan(`{"a": 2, "b": 1}`, "echo hello")
+
+	opts := &CompareOptions{CanonicalizeJSONStrings: true, IgnoreWhitespace: true, IgnoreEmptyStringNullEquivalence: true}
+	origAttrs, newAttrs := getComparedAttributes(origResource, opts), getComparedAttributes(newResource, opts)
+	normalized, _ := normalizeAttributeSets(newResource, origAttrs, newAttrs, opts)
+
+	assert.True(t, normalized)
+	assert.Equal(t, origAttrs, newAttrs)
+	assert.Equal(t, plan(`{"b": 1, "a": 2}`, "echo  hello"), origResource)
+	assert.Equal(t, plan(`{"a": 2, "b": 1}`, "echo hello"), newResource)
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	origAttrs := map[string]interface{}{"a": " x  y ", "b": "one", "nested": map[string]interface{}{"c": "p\tq"}}
+	newAttrs := map[string]interface{}{"a": "x y", "b": "two", "nested": map[string]interface{}{"c": "p q"}}
+
+	assert.True(t, collapseWhitespace(origAttrs, newAttrs))
+	assert.Equal(t, origAttrs["a"], newAttrs["a"])
+	assert.NotEqual(t, origAttrs["b"], newAttrs["b"])
+	assert.Equal(t, origAttrs["nested"], newAttrs["nested"])
+}
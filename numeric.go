@@ -0,0 +1,83 @@
+package comparison
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// valuesEqual reports whether two attribute values are equal. Numbers are compared by value whatever
+// their encoding, so int64(80), float64(80) and json.Number("80") are equal; with LooseNumberComparison
+// a string holding a number also equals that number, e.g. "443" and 443. Maps and lists are compared
+// element by element.
+func valuesEqual(a, b interface{}, opts *CompareOptions) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+
+	switch aTyped := a.(type) {
+	case map[string]interface{}:
+		bTyped, ok := b.(map[string]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for k, aV := range aTyped {
+			bV, exists := bTyped[k]
+			if !exists || !valuesEqual(aV, bV, opts) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bTyped, ok := b.([]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for i := range aTyped {
+			if !valuesEqual(aTyped[i], bTyped[i], opts) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Two strings are only equal as written
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+	if aIsString && bIsString {
+		return false
+	}
+
+	aNum, aOk := numericValue(a, opts.LooseNumberComparison)
+	bNum, bOk := numericValue(b, opts.LooseNumberComparison)
+	return aOk && bOk && aNum == bNum
+}
+
+// numericValue returns the value of a number of any Go numeric type or json.Number. With loose set,
+// strings holding a number are converted as well.
+func numericValue(v interface{}, loose bool) (float64, bool) {
+	switch typed := v.(type) {
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	case string:
+		if !loose {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		return f, err == nil
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,71 @@
+package comparison
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  interface{}
+		loose bool
+		equal bool
+	}{
+		{name: "int and float", a: int64(80), b: float64(80), equal: true},
+		{name: "int8 and uint16", a: int8(3), b: uint16(3), equal: true},
+		{name: "json number and float", a: json.Number("80.0"), b: float64(80), equal: true},
+		{name: "different numbers", a: 80, b: 80.5, equal: false},
+		{name: "numeric string is strict by default", a: "443", b: float64(443), equal: false},
+		{name: "numeric string with loose comparison", a: "443", b: float64(443), loose: true, equal: true},
+		{name: "non-numeric string with loose comparison", a: "https", b: float64(443), loose: true, equal: false},
+		{name: "two strings are compared as written", a: "443", b: "443.0", loose: true, equal: false},
+		{name: "bool is not a number", a: true, b: 1, equal: false},
+		{
+			name:  "nested maps and lists",
+			a:     map[string]interface{}{"ports": []interface{}{int64(80), int64(443)}, "ttl": json.Number("60")},
+			b:     map[string]interface{}{"ports": []interface{}{80.0, 443.0}, "ttl": 60.0},
+			equal: true,
+		},
+		{
+			name:  "maps with different keys",
+			a:     map[string]interface{}{"a": 1},
+			b:     map[string]interface{}{"b": 1},
+			equal: false,
+		},
+		{name: "lists of different lengths", a: []interface{}{1}, b: []interface{}{1.0, 2.0}, equal: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &CompareOptions{LooseNumberComparison: tc.loose}
+			assert.Equal(t, tc.equal, valuesEqual(tc.a, tc.b, opts))
+			assert.Equal(t, tc.equal, valuesEqual(tc.b, tc.a, opts))
+		})
+	}
+}
+
+func TestCompareResources_NumericEncoding(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_security_group_rule.https": map[string]interface{}{
+			"values": map[string]interface{}{"from_port": int64(443), "to_port": "443", "tags": map[string]interface{}{"weight": int64(1)}},
+		},
+	}
+	newRes := map[string]interface{}{
+		"aws_security_group_rule.https": map[string]interface{}{
+			"values": map[string]interface{}{"from_port": float64(443), "to_port": float64(443), "tags": map[string]interface{}{"weight": 1.0}},
+		},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{})
+	assert.Contains(t, diff, "~ to_port: 443 => 443")
+	assert.NotContains(t, diff, "from_port")
+	assert.NotContains(t, diff, "weight")
+	assert.Len(t, diffMap["changed"], 1)
+
+	diff, diffMap = compareResources(origRes, newRes, &CompareOptions{LooseNumberComparison: true})
+	assert.Empty(t, diff)
+	assert.Empty(t, diffMap["changed"])
+}
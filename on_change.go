@@ -0,0 +1,81 @@
+package comparison
+
+import "sort"
+
+// ChangeFunc receives each change found by a comparison: the diff map section it belongs to (e.g.
+// "resources"), the kind of change (e.g. "added" or "changed") and its diff map entry.
+type ChangeFunc func(section, changeType string, detail map[string]interface{})
+
+// WithOnChange calls fn for every change a comparison finds, in the order of the text diff.
+func WithOnChange(fn ChangeFunc) Option {
+	return func(o *CompareOptions) {
+		o.OnChange = fn
+	}
+}
+
+// changeCategories lists the change kinds of each diff map section in the order the text diff writes them.
+var changeCategories = []struct {
+	section     string
+	changeTypes []string
+}{
+	{section: "variables", changeTypes: []string{"added", "removed", "changed"}},
+	{section: "resources", changeTypes: []string{"count_changed", "added", "removed", "moved", "mode_changed", "changed"}},
+	{section: "tag_changes", changeTypes: []string{"changed"}},
+	{section: "drift", changeTypes: []string{"count_changed", "added", "removed", "moved", "mode_changed", "changed"}},
+	{section: "provider_upgrades"},
+	{section: "outputs", changeTypes: []string{"added", "removed", "changed", "sensitivity_changed"}},
+	{section: "meta"},
+}
+
+// setAsideCategories are the categories of a resources section that record differences which were not
+// reported as changes.
+var setAsideCategories = stringSet([]string{"normalized_away", "noise"})
+
+// visitChanges calls fn for every entry of a diff map in the order of the text diff. Provider upgrades are
+// reported as "upgraded", and meta changes, which the text diff lists by name, are visited by name too.
+func visitChanges(diffMap map[string]interface{}, fn ChangeFunc) {
+	for _, category := range changeCategories {
+		switch category.section {
+		case "provider_upgrades":
+			for _, entry := range entryList(diffMap[category.section]) {
+				fn(category.section, "upgraded", entry)
+			}
+		case "meta":
+			visitMetaChanges(diffMap[category.section], fn)
+		default:
+			categories, ok := diffMap[category.section].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, changeType := range category.changeTypes {
+				for _, entry := range entryList(categories[changeType]) {
+					fn(category.section, changeType, entry)
+				}
+			}
+		}
+	}
+}
+
+// visitMetaChanges calls fn for the added, removed and changed versions of the meta section by name.
+func visitMetaChanges(meta interface{}, fn ChangeFunc) {
+	categories, ok := meta.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	type metaChange struct {
+		changeType string
+		entry      map[string]interface{}
+	}
+	changes := make([]metaChange, 0)
+	for _, changeType := range []string{"added", "removed", "changed"} {
+		for _, entry := range entryList(categories[changeType]) {
+			changes = append(changes, metaChange{changeType: changeType, entry: entry})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return entryKey(changes[i].entry) < entryKey(changes[j].entry) })
+
+	for _, change := range changes {
+		fn("meta", change.changeType, change.entry)
+	}
+}
@@ -0,0 +1,88 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnChange(t *testing.T) {
+	origJSON := `{
+		"terraform_version": "1.6.0",
+		"variables": {"env": {"value": "dev"}, "region": {"value": "eu-west-1"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		],
+		"planned_values": {"outputs": {"url": {"value": "a"}}}
+	}`
+	newJSON := `{
+		"terraform_version": "1.7.0",
+		"variables": {"env": {"value": "prod"}, "zone": {"value": "a"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2"}}},
+			{"address": "aws_s3_bucket.new", "change": {"after": {"bucket": "new"}}}
+		],
+		"planned_values": {"outputs": {"url": {"value": "b"}}}
+	}`
+
+	type call struct {
+		section, changeType, key string
+	}
+	var calls []call
+	opts := newOptions(WithOnChange(func(section, changeType string, detail map[string]interface{}) {
+		calls = append(calls, call{section: section, changeType: changeType, key: entryKey(detail)})
+	}))
+
+	result, err := ComparePlans(origJSON, newJSON, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []call{
+		{section: "variables", changeType: "added", key: "zone"},
+		{section: "variables", changeType: "removed", key: "region"},
+		{section: "variables", changeType: "changed", key: "env"},
+		{section: "resources", changeType: "added", key: "aws_s3_bucket.new"},
+		{section: "resources", changeType: "removed", key: "aws_s3_bucket.old"},
+		{section: "resources", changeType: "changed", key: "aws_instance.web"},
+		{section: "outputs", changeType: "changed", key: "url"},
+		{section: "meta", changeType: "changed", key: "terraform"},
+	}, calls)
+
+	// The details are the diff map entries themselves
+	resources := result.Changes["resources"].(map[string]interface{})
+	assert.Equal(t, "aws_instance.web", entryList(resources["changed"])[0]["address"])
+
+	// Identical plans report nothing
+	calls = nil
+	_, err = ComparePlans(origJSON, origJSON, opts)
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+}
+
+func TestVisitChanges(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"provider_upgrades": []map[string]interface{}{{"provider": "aws"}},
+		"meta": map[string]interface{}{
+			"added":   []map[string]interface{}{{"name": "provider.random"}},
+			"removed": []map[string]interface{}{{"name": "provider.null"}},
+			"changed": []map[string]interface{}{{"name": "provider.aws"}, {"name": "terraform"}},
+		},
+		"outputs": map[string]interface{}{
+			"sensitivity_changed": []map[string]interface{}{{"name": "password"}},
+		},
+	}
+
+	var visited []string
+	visitChanges(diffMap, func(section, changeType string, detail map[string]interface{}) {
+		visited = append(visited, section+" "+changeType+" "+entryKey(detail))
+	})
+
+	assert.Equal(t, []string{
+		"provider_upgrades upgraded aws",
+		"outputs sensitivity_changed password",
+		"meta changed provider.aws",
+		"meta removed provider.null",
+		"meta added provider.random",
+		"meta changed terraform",
+	}, visited)
+}
@@ -0,0 +1,291 @@
+package comparison
+
+import (
+	"io"
+	"regexp"
+)
+
+// CompareOptions controls how two plans are compared.
+type CompareOptions struct {
+	// SuppressKnownProviderNoise hides attribute churn that is known to be noise for the resource's provider.
+	SuppressKnownProviderNoise bool
+
+	// InputFormat is the encoding of the plan documents. The default detects JSON or MessagePack by content.
+	InputFormat InputFormat
+
+	// UseSchemaOrder orders attribute changes within a resource by their declaration order in the
+	// plan's configuration block. Resources without configuration fall back to alphabetical order.
+	// Declaration order is only read from JSON input.
+	UseSchemaOrder bool
+
+	// StrictVersion fails the comparison with ErrUnsupportedFormatVersion when either plan's format_version
+	// is missing or not a known plan JSON format (0.1, 0.2, 1.0, 1.1 or 1.2), instead of risking a subtly
+	// wrong diff of a future schema.
+	StrictVersion bool
+
+	// Plain guarantees byte-identical output regardless of environment: no color, no emoji,
+	// no ellipsis truncation and no behavior that depends on whether a TTY is attached.
+	Plain bool
+
+	// Writer receives the printed diff. Defaults to os.Stdout.
+	Writer io.Writer
+
+	// Silent suppresses printing; the diff is still returned.
+	Silent bool
+
+	// ColorEnabled colors added, removed and changed lines of the printed diff with ANSI escape
+	// sequences when Writer is a terminal. The returned text and diff map are never colored, and
+	// Plain turns color off.
+	ColorEnabled bool
+
+	// ProjectAttributes restricts the comparison to the listed resource attributes. Nested paths such as
+	// "tags.Name" are supported. Unlisted attributes do not count as changes at all.
+	ProjectAttributes []string
+
+	// AttributeAllowlist restricts the comparison to the listed top-level resource attribute names, e.g.
+	// "ami", "instance_type" and "tags", for a focused review of a few critical fields. Resources whose
+	// allowlisted attributes did not change drop out of the changed list, and the allowlisted attributes keep
+	// the usual priority ordering. It applies on top of ProjectAttributes when both are set.
+	AttributeAllowlist []string
+
+	// CanonicalizeJSONStrings compares attribute values that hold JSON documents (e.g. IAM policies),
+	// including those in nested blocks and lists, by their parsed content, so key order and whitespace
+	// differences are not reported. Strings that are not JSON objects or arrays are compared as they are.
+	CanonicalizeJSONStrings bool
+
+	// IgnoreWhitespace compares string attribute values with leading, trailing and repeated whitespace collapsed.
+	IgnoreWhitespace bool
+
+	// IgnoreEmptyStringNullEquivalence treats null and empty attribute values as equal, so spurious
+	// transitions such as `null => ""` or `{} => null` are not reported. EmptyValueKinds selects which
+	// empty values count: empty strings, maps and lists, all of them by default.
+	IgnoreEmptyStringNullEquivalence bool
+	EmptyValueKinds                  []EmptyValueKind
+
+	// ReportNormalized records attribute changes that a normalization option resolved to equal values
+	// in a normalized_away list on the resources section, with the original values and the reason
+	// (provider_noise, case, json, whitespace or empty). The suppressed changes are still not reported as diffs.
+	ReportNormalized bool
+
+	// NoiseHeuristics moves attribute changes whose old and new values both look generated, such as
+	// timestamps or UUIDs, out of the changed resources and into a noise list on the resources section,
+	// with the original values. Both values must match the same pattern. NoisePatterns replaces the
+	// default patterns, which recognize RFC 3339 timestamps, UUIDs, hex digests and Terraform-generated names.
+	NoiseHeuristics bool
+	NoisePatterns   []*regexp.Regexp
+
+	// AddressAliases maps resource addresses in the original plan to their addresses in the new plan,
+	// so renamed resources are compared with each other. Unaliased addresses keep their natural key.
+	AddressAliases map[string]string
+
+	// TypeAliases maps old resource type names to new ones, e.g. "aws_alb" to "aws_lb", so resources whose
+	// type was renamed by their provider are compared with each other. Unaliased type changes stay an
+	// addition and a removal.
+	TypeAliases map[string]string
+
+	// ResourceKeyFunc, when set, keys resources by the returned value instead of their address, so
+	// resources are paired across plans by any identity the caller chooses. AddressAliases apply to the keys.
+	ResourceKeyFunc ResourceKeyFunc
+
+	// IgnoreComputed drops attributes that are not set in the configuration block or are known only
+	// after apply, so only user-declared attributes are compared. Resources without configuration
+	// information only lose their unknown-after-apply attributes.
+	IgnoreComputed bool
+
+	// GroupByDriver splits the attribute changes of resources with configuration into user-driven
+	// changes (attributes set in the configuration block) and provider-driven changes (computed or
+	// defaulted attributes). Changed resource entries gain a drivers map listing the names of each group.
+	GroupByDriver bool
+
+	// AlignCountIndexes pairs count instances of the same resource by content instead of by index, so an
+	// element inserted into or removed from the middle of a count list is reported once instead of as a
+	// change to every following index.
+	AlignCountIndexes bool
+
+	// CollapseCountChurn reports a resource whose number of count instances changed as a single count change,
+	// e.g. `~ aws_instance.web: count 3 => 4 (+1 instance)`, when the instances present in both plans are
+	// unchanged and every added or removed instance is a copy of one of them, instead of their additions and
+	// removals. for_each instances are always compared one by one.
+	// Collapsed resources are listed under count_changed in the diff map.
+	CollapseCountChurn bool
+
+	// SeparateTagChanges moves resources whose only changed attributes are within tags or tags_all out of
+	// the resources section into a "Tag Changes" section, listed under tag_changes in the diff map, so
+	// low-risk tag churn can be reviewed apart from substantive changes.
+	SeparateTagChanges bool
+
+	// AlignAttributeValues pads the attribute names of each changed resource to the width of its longest
+	// one, so the values line up in a column. Widths are measured in terminal cells, so multi-byte and
+	// wide characters align as they are displayed, independently of the locale.
+	AlignAttributeValues bool
+
+	// IncludeAddresses limits the comparison to resources whose address matches one of the patterns, and
+	// ExcludeAddresses leaves out resources whose address matches one of its patterns. A `*` in a pattern
+	// matches any run of characters, e.g. `module.network.*` or `aws_instance.*`. Exclusion takes
+	// precedence: an address matching both lists is left out. Filtered resources appear nowhere in the diff.
+	IncludeAddresses []string
+	ExcludeAddresses []string
+
+	// IncludeTypes and ExcludeTypes filter resources like IncludeAddresses and ExcludeAddresses, but match
+	// the patterns against the resource type, e.g. `aws_iam_*`, taken from the resource's type field or
+	// parsed from its address with any module path and data prefix removed. Both kinds of filter apply.
+	IncludeTypes []string
+	ExcludeTypes []string
+
+	// PlannedOnly compares only the entries of resource_changes whose actions are not ["no-op"], so
+	// unchanged resources from prior_state and planned_values are left out and the diff reflects what
+	// Terraform intends to change, like the change summary of `terraform plan`.
+	PlannedOnly bool
+
+	// IncludeNoOpChanges compares the entries of resource_changes whose actions are ["no-op"] too. By
+	// default they are skipped in favor of the resource in prior_state or planned_values, since Terraform
+	// reports those resources as unchanged even when their before and after differ in computed fields.
+	IncludeNoOpChanges bool
+
+	// IntersectionOnly ignores added and removed resources and only compares resources present in both plans.
+	IntersectionOnly bool
+
+	// DriftMode controls how resources that appear only in resource_drift, as in refresh-only plans,
+	// are reported: in a drift section of their own, merged into the resources section, or not at all.
+	// The zero value ignores them.
+	DriftMode DriftMode
+
+	// IncludeChangeIDs adds a change_id to every entry in the diff map, derived from a hash of the
+	// section, address and attribute, so the same logical change has the same ID across runs.
+	IncludeChangeIDs bool
+
+	// IncludeFullState adds the full original and new resource, with sensitive values redacted, to every
+	// changed resource entry in the diff map as old and new. It is off by default to keep the map small.
+	IncludeFullState bool
+
+	// ExplainIdentical adds an explanation to identical results: how many variables and outputs were
+	// extracted from each plan, how many resources were compared once the options selected, filtered and
+	// ignored them, which sections the resources came from, and which sections were empty in both plans.
+	ExplainIdentical bool
+
+	// OnChange, when set, is called for every added, removed and changed item the comparison finds, in
+	// the order of the text diff, with the item's diff map entry. Changes are reported after values are
+	// capped and change IDs assigned.
+	OnChange ChangeFunc
+
+	// ContinueOnError makes batch comparisons record a pair that fails to parse as an error result
+	// and carry on with the remaining pairs, instead of aborting on the first error.
+	ContinueOnError bool
+
+	// BatchConcurrency is the number of plan pairs CompareBatch compares at once. Zero or one compares
+	// them one at a time.
+	BatchConcurrency int
+
+	// CostEstimator, when set, attaches an estimated monthly cost_delta to every resource entry it can
+	// price, and the total to the resources section.
+	CostEstimator CostEstimator
+
+	// SkipAttributes lists attribute names, matched exactly, that are left out of the diff in addition
+	// to the built-in checksum and base64 attributes such as content_md5. Entries prefixed with "re:"
+	// are regular expressions that must match the whole name, e.g. `re:.*_sha\d+`; an invalid pattern
+	// fails the comparison.
+	SkipAttributes []string
+
+	// DisableDefaultSkipAttributes stops the built-in checksum and base64 attributes from being skipped,
+	// so only SkipAttributes are.
+	DisableDefaultSkipAttributes bool
+
+	// Ignore leaves the resources and attributes it matches out of the text diff and the diff map, e.g. as
+	// loaded by LoadIgnoreFile. The number of changes it hides is recorded as ignored in the diff map and
+	// shown in the summary line as "N ignored".
+	Ignore *IgnoreSet
+
+	// HashSensitiveValues shows a short SHA-256 hash of each value marked sensitive by the plan's
+	// sensitive_values or after_sensitive metadata instead of "(sensitive value)", so readers can tell
+	// which sensitive values differ without seeing them. Changed sensitive values are reported either way.
+	HashSensitiveValues bool
+
+	// CompareVariableMetadata compares the declared type, sensitivity and nullability of variables, read
+	// from the variables section and the root module configuration, in addition to their values. Changed
+	// fields are reported as e.g. `~ db_password.sensitive: false => true`, and the values of variables
+	// declared sensitive in either plan are redacted like other sensitive values.
+	CompareVariableMetadata bool
+
+	// MaxAttributeDepth limits how many levels of nested map and list attributes, such as tags or
+	// ingress rules, are diffed key by key and element by element. Within the limit only the changed
+	// keys and elements are reported, e.g. `tags.Environment` or `ingress[1]`, and changed entries carry
+	// a nested attributes or elements structure; deeper values are reported whole. Zero uses a limit of
+	// 10 and a negative value reports every map and list as a whole value.
+	MaxAttributeDepth int
+
+	// LooseNumberComparison treats a string holding a number as equal to that number when comparing
+	// resource attributes, e.g. "443" and 443. Numbers of different encodings, such as 80 and 80.0, are
+	// always compared by value.
+	LooseNumberComparison bool
+
+	// ValueFormatter, when set, renders values in the text diff instead of DefaultValueFormatter, e.g.
+	// TruncateBase64Formatter to shorten base64 blobs. Multi-line strings are then rendered whole by the
+	// formatter instead of line by line.
+	ValueFormatter ValueFormatter
+
+	// MaxValueLength caps the length in bytes of every value in the text diff and of every string value
+	// in the diff map. Longer values are cut and followed by "…(N bytes total)", or "...(N bytes total)" with
+	// Plain. Multi-line values shown line by line are cut after MaxValueLength bytes of lines, followed by
+	// "…(N more lines)". Zero leaves values uncapped.
+	MaxValueLength int
+
+	// ValueMasks replaces every match of the patterns in values with "***", e.g. to keep account IDs,
+	// ARNs or email addresses out of logs. Masks apply to every value in the text diff, including
+	// nested maps and lists and multi-line values, and to every string value in the diff map.
+	ValueMasks []*regexp.Regexp
+
+	// ShowContextAttributes lists unchanged anchor attributes of each changed resource, such as its id or
+	// name, below the resource header for context. They are indented without a change marker and are not
+	// part of the diff map. ContextAttributes selects the attributes, in order, and defaults to id, url and
+	// content, the attributes that are otherwise listed first.
+	ShowContextAttributes bool
+	ContextAttributes     []string
+
+	// RenderStyle configures how changes are rendered in the text diff.
+	RenderStyle RenderStyle
+
+	// schemaOrder maps configuration addresses to their declared attribute order.
+	schemaOrder map[string][]string
+
+	// skipPatterns are the compiled regular expression entries of SkipAttributes.
+	skipPatterns []*regexp.Regexp
+
+	// ignoredAddresses and ignoredAttributes are the compiled patterns of Ignore.
+	ignoredAddresses  []*regexp.Regexp
+	ignoredAttributes []*regexp.Regexp
+
+	// declaredAttributes maps configuration addresses to the attributes their configuration sets.
+	declaredAttributes map[string]map[string]bool
+
+	// fingerprints memoizes resource fingerprints for the duration of a comparison.
+	fingerprints fingerprintCache
+}
+
+// resolveOptions returns the options to use for a comparison, falling back to defaults when opts is nil.
+func resolveOptions(opts *CompareOptions) *CompareOptions {
+	if opts == nil {
+		return &CompareOptions{}
+	}
+
+	resolved := *opts
+	return &resolved
+}
+
+// Option configures a comparison. Options are applied in order on top of the defaults.
+type Option func(*CompareOptions)
+
+// WithCompareOptions replaces the options being built with a copy of base.
+func WithCompareOptions(base CompareOptions) Option {
+	return func(o *CompareOptions) {
+		*o = base
+	}
+}
+
+// newOptions builds the options for a comparison from functional options.
+func newOptions(opts ...Option) *CompareOptions {
+	resolved := &CompareOptions{}
+	for _, opt := range opts {
+		opt(resolved)
+	}
+	return resolved
+}
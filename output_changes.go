@@ -0,0 +1,126 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// outputActionDelete is the output_changes action of an output that the plan removes.
+	outputActionDelete = "delete"
+
+	// exposureWarning marks outputs that are no longer sensitive.
+	exposureWarning = "⚠"
+)
+
+// canonicalPlannedOutput converts a planned_values output to the canonical {value, sensitive} shape,
+// dropping any other fields such as type.
+func canonicalPlannedOutput(output interface{}) interface{} {
+	outputMap, ok := output.(map[string]interface{})
+	if !ok {
+		return output
+	}
+
+	sensitive, _ := outputMap["sensitive"].(bool)
+	return map[string]interface{}{"sensitive": sensitive, "value": outputMap["value"]}
+}
+
+// canonicalOutputChange converts an output_changes entry, which wraps the value in actions and
+// before/after fields, to the canonical {value, sensitive} shape of its planned value. It returns false
+// for outputs the plan deletes.
+func canonicalOutputChange(change interface{}) (interface{}, bool) {
+	changeMap, ok := change.(map[string]interface{})
+	if !ok {
+		return change, true
+	}
+
+	if actions, ok := changeMap["actions"].([]interface{}); ok && outputAction(actions) == outputActionDelete {
+		return nil, false
+	}
+
+	sensitive, _ := changeMap["after_sensitive"].(bool)
+	return map[string]interface{}{"sensitive": sensitive, "value": changeMap["after"]}, true
+}
+
+// getOutputActions returns the action of each output in a plan's output_changes, e.g. "create",
+// "update" or "delete". Replacements are reported as "delete-create".
+func getOutputActions(plan map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+
+	if outputChanges, ok := plan["output_changes"].(map[string]interface{}); ok {
+		for name, change := range outputChanges {
+			changeMap, ok := change.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if actions, ok := changeMap["actions"].([]interface{}); ok {
+				result[name] = outputAction(actions)
+			}
+		}
+	}
+
+	return result
+}
+
+// outputAction joins the actions of an output change.
+func outputAction(actions []interface{}) string {
+	parts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if s, ok := action.(string); ok {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// addOutputActions adds the action of each output to its entries in an outputs diff map.
+func addOutputActions(diffMap map[string]interface{}, actions map[string]string) {
+	for _, section := range []string{"added", "removed", "changed"} {
+		for _, entry := range entryList(diffMap[section]) {
+			if action, ok := actions[entryKey(entry)]; ok {
+				entry["action"] = action
+			}
+		}
+	}
+}
+
+// compareOutputSensitivity writes a line for each output present in both plans whose sensitivity changed,
+// whatever its value, and returns them as diff map entries. An output that is no longer sensitive is a
+// possible data exposure and is flagged with a warning marker, or "!" with Plain output.
+func compareOutputSensitivity(diff *strings.Builder, origOutputs, newOutputs map[string]interface{}, opts *CompareOptions) []map[string]interface{} {
+	changes := make([]map[string]interface{}, 0)
+
+	for _, name := range getSortedKeys(origOutputs, nil) {
+		newOutput, exists := newOutputs[name]
+		if !exists {
+			continue
+		}
+
+		origSensitive, newSensitive := isSensitive(origOutputs[name]), isSensitive(newOutput)
+		switch {
+		case origSensitive && !newSensitive:
+			diff.WriteString(fmt.Sprintf("%s output %s\n", exposureMarker(opts), formatChange(name, "sensitive", "not sensitive", opts)))
+		case !origSensitive && newSensitive:
+			diff.WriteString(fmt.Sprintf("~ output %s\n", formatChange(name, "not sensitive", "sensitive", opts)))
+		default:
+			continue
+		}
+
+		changes = append(changes, map[string]interface{}{
+			"name":          name,
+			"old_sensitive": origSensitive,
+			"new_sensitive": newSensitive,
+			"exposed":       origSensitive && !newSensitive,
+		})
+	}
+
+	return changes
+}
+
+// exposureMarker returns the marker of lines reporting a possible data exposure.
+func exposureMarker(opts *CompareOptions) string {
+	if opts.Plain {
+		return "!"
+	}
+	return exposureWarning
+}
@@ -0,0 +1,194 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOutputs(t *testing.T) {
+	plan := map[string]interface{}{
+		"planned_values": map[string]interface{}{
+			"outputs": map[string]interface{}{
+				"planned": map[string]interface{}{"sensitive": false, "value": "p", "type": "string"},
+				"url":     map[string]interface{}{"sensitive": true, "value": "https://planned"},
+			},
+		},
+		"output_changes": map[string]interface{}{
+			"url":   map[string]interface{}{"actions": []interface{}{"update"}, "before": "a", "after": "b"},
+			"token": map[string]interface{}{"actions": []interface{}{"create"}, "before": nil, "after": "t", "after_sensitive": true},
+			"old":   map[string]interface{}{"actions": []interface{}{"delete"}, "before": "x", "after": nil},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"planned": map[string]interface{}{"sensitive": false, "value": "p"},
+		"url":     map[string]interface{}{"sensitive": true, "value": "https://planned"},
+		"token":   map[string]interface{}{"sensitive": true, "value": "t"},
+	}, getOutputs(plan))
+	assert.Equal(t, map[string]string{"url": "update", "token": "create", "old": "delete"}, getOutputActions(plan))
+}
+
+func TestComparePlans_OutputChanges(t *testing.T) {
+	origJSON := `{"output_changes": {
+		"url": {"actions": ["update"], "before": "https://a", "after": "https://b"},
+		"id": {"actions": ["no-op"], "before": "1", "after": "1"},
+		"legacy": {"actions": ["no-op"], "before": "l", "after": "l"},
+		"secret": {"actions": ["create"], "before": null, "after": "s1", "after_sensitive": true}
+	}}`
+	newJSON := `{"output_changes": {
+		"url": {"actions": ["update"], "before": "https://a", "after": "https://c"},
+		"id": {"actions": ["update"], "before": "0", "after": "1"},
+		"legacy": {"actions": ["delete"], "before": "l", "after": null},
+		"secret": {"actions": ["update"], "before": "s1", "after": "s2", "after_sensitive": true},
+		"endpoint": {"actions": ["create"], "before": null, "after": "https://api"}
+	}}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "+ endpoint: https://api\n")
+	assert.Contains(t, result.Text, "- legacy: l\n")
+	assert.Contains(t, result.Text, "~ url: https://b => https://c\n")
+	assert.Contains(t, result.Text, "~ secret: (sensitive value) => (sensitive value)\n")
+	assert.NotContains(t, result.Text, "actions")
+	assert.NotContains(t, result.Text, "~ id")
+
+	outputs := result.Changes["outputs"].(map[string]interface{})
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "endpoint", "value": map[string]interface{}{"sensitive": false, "value": "https://api"}, "action": "create"},
+	}, outputs["added"])
+	assert.Equal(t, "delete", outputs["removed"].([]map[string]interface{})[0]["action"])
+	assert.Equal(t, "update", outputs["changed"].([]map[string]interface{})[0]["action"])
+}
+
+func TestComparePlans_OutputChangesSamePlannedValues(t *testing.T) {
+	origJSON := `{"output_changes": {"url": {"actions": ["update"], "before": "https://a", "after": "https://c"}}}`
+	newJSON := `{"output_changes": {"url": {"actions": ["update"], "before": "https://b", "after": "https://c"}}}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.NotContains(t, result.Changes, "outputs")
+}
+
+func TestComparePlans_OutputsFromDifferentSections(t *testing.T) {
+	planned := `{"planned_values": {"outputs": {
+		"url": {"sensitive": false, "value": "https://a"},
+		"secret": {"sensitive": true, "value": "s"}
+	}}}`
+	changes := `{"output_changes": {
+		"url": {"actions": ["update"], "before": "https://old", "after": "https://a"},
+		"secret": {"actions": ["no-op"], "before": "s", "after": "s", "after_sensitive": true}
+	}}`
+
+	result, err := ComparePlans(planned, changes, &CompareOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+
+	equal, err := PlansEqual(planned, changes)
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	changed := `{"output_changes": {
+		"url": {"actions": ["update"], "before": "https://a", "after": "https://b"},
+		"secret": {"actions": ["no-op"], "before": "s", "after": "s", "after_sensitive": true}
+	}}`
+	result, err = ComparePlans(planned, changed, &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "~ url: https://a => https://b\n")
+	assert.NotContains(t, result.Text, "secret")
+}
+
+func TestComparePlans_OutputSensitivity(t *testing.T) {
+	plan := func(passwordSensitive, keySensitive bool) string {
+		return fmt.Sprintf(`{"planned_values": {"outputs": {
+			"db_password": {"sensitive": %t, "value": "hunter2"},
+			"api_key": {"sensitive": %t, "value": "k"},
+			"url": {"sensitive": false, "value": "https://a"}
+		}}}`, passwordSensitive, keySensitive)
+	}
+
+	tests := []struct {
+		name        string
+		orig, new   string
+		opts        *CompareOptions
+		contains    []string
+		notContains []string
+		changes     []map[string]interface{}
+	}{
+		{
+			name:     "exposure",
+			orig:     plan(true, false),
+			new:      plan(false, false),
+			opts:     &CompareOptions{},
+			contains: []string{"⚠ output db_password: sensitive => not sensitive\n"},
+			changes: []map[string]interface{}{
+				{"name": "db_password", "old_sensitive": true, "new_sensitive": false, "exposed": true},
+			},
+		},
+		{
+			name:        "exposure with plain output",
+			orig:        plan(true, false),
+			new:         plan(false, false),
+			opts:        &CompareOptions{Plain: true},
+			contains:    []string{"! output db_password: sensitive => not sensitive\n"},
+			notContains: []string{"⚠"},
+			changes: []map[string]interface{}{
+				{"name": "db_password", "old_sensitive": true, "new_sensitive": false, "exposed": true},
+			},
+		},
+		{
+			name:     "exposure with labels",
+			orig:     plan(true, false),
+			new:      plan(false, false),
+			opts:     &CompareOptions{RenderStyle: RenderStyle{Change: ChangeStyleLabels}},
+			contains: []string{"⚠ output db_password from sensitive to not sensitive\n"},
+			changes: []map[string]interface{}{
+				{"name": "db_password", "old_sensitive": true, "new_sensitive": false, "exposed": true},
+			},
+		},
+		{
+			name:     "becomes sensitive with a reverse arrow",
+			orig:     plan(false, false),
+			new:      plan(false, true),
+			opts:     &CompareOptions{RenderStyle: RenderStyle{Change: ChangeStyleReverseArrow}},
+			contains: []string{"~ output api_key: sensitive <= not sensitive\n"},
+			changes: []map[string]interface{}{
+				{"name": "api_key", "old_sensitive": false, "new_sensitive": true, "exposed": false},
+			},
+		},
+		{
+			name:        "becomes sensitive",
+			orig:        plan(false, false),
+			new:         plan(false, true),
+			opts:        &CompareOptions{},
+			contains:    []string{"~ output api_key: not sensitive => sensitive\n"},
+			notContains: []string{"⚠"},
+			changes: []map[string]interface{}{
+				{"name": "api_key", "old_sensitive": false, "new_sensitive": true, "exposed": false},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(tc.orig, tc.new, tc.opts)
+			require.NoError(t, err)
+			for _, expected := range tc.contains {
+				assert.Contains(t, result.Text, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, result.Text, notExpected)
+			}
+			assert.Equal(t, tc.changes, result.Changes["outputs"].(map[string]interface{})["sensitivity_changed"])
+		})
+	}
+
+	// Unchanged sensitivity adds no field
+	result, err := ComparePlans(plan(true, false), strings.Replace(plan(true, false), "https://a", "https://b", 1), &CompareOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Changes["outputs"], "sensitivity_changed")
+}
@@ -0,0 +1,83 @@
+package comparison
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// CompareOutputsAcross collects the named outputs from several workspace plans and flags where they diverge.
+// plans maps a workspace name to its plan JSON. The result is keyed by output name, and each entry holds
+// "values" (workspace => value), "missing" (workspaces without the output) and "consistent".
+func CompareOutputsAcross(plans map[string]string, outputs []string) (map[string]map[string]interface{}, error) {
+	workspaces := make([]string, 0, len(plans))
+	for name := range plans {
+		workspaces = append(workspaces, name)
+	}
+	sort.Strings(workspaces)
+
+	// Extract outputs per workspace
+	workspaceOutputs := make(map[string]map[string]interface{}, len(plans))
+	for _, name := range workspaces {
+		var plan map[string]interface{}
+		if err := json.Unmarshal([]byte(plans[name]), &plan); err != nil {
+			return nil, errors.Wrapf(err, "error parsing plan JSON for workspace %q", name)
+		}
+		workspaceOutputs[name] = getOutputs(plan)
+	}
+
+	result := make(map[string]map[string]interface{}, len(outputs))
+	for _, output := range outputs {
+		values := make(map[string]interface{})
+		missing := make([]string, 0)
+		consistent := true
+
+		var first interface{}
+		seen := false
+		for _, name := range workspaces {
+			raw, exists := workspaceOutputs[name][output]
+			if !exists {
+				missing = append(missing, name)
+				consistent = false
+				continue
+			}
+
+			value := outputValue(raw)
+			values[name] = value
+
+			if !seen {
+				first, seen = value, true
+			} else if !reflect.DeepEqual(first, value) {
+				consistent = false
+			}
+		}
+
+		result[output] = map[string]interface{}{
+			"values":     values,
+			"missing":    missing,
+			"consistent": consistent,
+		}
+	}
+
+	return result, nil
+}
+
+// outputValue returns the value of an output from either planned_values or output_changes.
+func outputValue(output interface{}) interface{} {
+	outputMap, ok := output.(map[string]interface{})
+	if !ok {
+		return output
+	}
+
+	if value, exists := outputMap["value"]; exists {
+		return value
+	}
+
+	if after, exists := outputMap["after"]; exists {
+		return after
+	}
+
+	return output
+}
@@ -0,0 +1,44 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareOutputsAcross(t *testing.T) {
+	plans := map[string]string{
+		"us-east-1": `{"planned_values": {"outputs": {
+			"db_endpoint": {"sensitive": false, "value": "db.internal"},
+			"bucket": {"sensitive": false, "value": "logs-use1"}}}}`,
+		"us-west-2": `{"planned_values": {"outputs": {
+			"db_endpoint": {"sensitive": false, "value": "db.internal"},
+			"bucket": {"sensitive": false, "value": "logs-usw2"}}}}`,
+		"eu-west-1": `{"output_changes": {
+			"db_endpoint": {"actions": ["no-op"], "before": "db.internal", "after": "db.internal"},
+			"bucket": {"actions": ["no-op"], "before": "logs-euw1", "after": "logs-euw1"}}}`,
+	}
+
+	result, err := CompareOutputsAcross(plans, []string{"db_endpoint", "bucket", "missing"})
+	require.NoError(t, err)
+
+	assert.Equal(t, true, result["db_endpoint"]["consistent"])
+	assert.Equal(t, map[string]interface{}{
+		"us-east-1": "db.internal",
+		"us-west-2": "db.internal",
+		"eu-west-1": "db.internal",
+	}, result["db_endpoint"]["values"])
+
+	assert.Equal(t, false, result["bucket"]["consistent"])
+	assert.Equal(t, "logs-euw1", result["bucket"]["values"].(map[string]interface{})["eu-west-1"])
+
+	assert.Equal(t, false, result["missing"]["consistent"])
+	assert.Equal(t, []string{"eu-west-1", "us-east-1", "us-west-2"}, result["missing"]["missing"])
+}
+
+func TestCompareOutputsAcross_InvalidJSON(t *testing.T) {
+	_, err := CompareOutputsAcross(map[string]string{"broken": "{"}, []string{"a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `workspace "broken"`)
+}
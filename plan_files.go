@@ -0,0 +1,39 @@
+package comparison
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ErrEmptyPlanFile is returned when a plan file has no content.
+var ErrEmptyPlanFile = errors.New("plan file is empty")
+
+// ComparePlanFiles reads two plan JSON files from disk and compares them with ComparePlansAndGenerateDiff.
+func ComparePlanFiles(origPath, newPath string) (string, map[string]interface{}, bool, error) {
+	origPlanFileJSON, err := readPlanFile(origPath)
+	if err != nil {
+		return "", nil, false, errors.Wrapf(err, "error reading original plan file %q", origPath)
+	}
+
+	newPlanFileJSON, err := readPlanFile(newPath)
+	if err != nil {
+		return "", nil, false, errors.Wrapf(err, "error reading new plan file %q", newPath)
+	}
+
+	return comparePlanBytesAndGenerateDiff(origPlanFileJSON, newPlanFileJSON, nil)
+}
+
+// readPlanFile reads a plan file in a single pass, rejecting empty files. The contents are decoded as they
+// are read, so each plan is held in memory once before decoding.
+func readPlanFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, ErrEmptyPlanFile
+	}
+	return data, nil
+}
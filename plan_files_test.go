@@ -0,0 +1,82 @@
+package comparison
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlanFiles(t *testing.T) {
+	dir := t.TempDir()
+	writePlan := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), planFileMode))
+		return path
+	}
+
+	orig := writePlan("orig.json", `{"variables": {"stage": {"value": "dev"}}}`)
+	updated := writePlan("new.json", `{"variables": {"stage": {"value": "prod"}}}`)
+	empty := writePlan("empty.json", "")
+	missing := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		name        string
+		origPath    string
+		newPath     string
+		hasDiff     bool
+		errContains string
+		errIs       error
+	}{
+		{name: "different plans", origPath: orig, newPath: updated, hasDiff: true},
+		{name: "identical plans", origPath: orig, newPath: orig},
+		{name: "missing original", origPath: missing, newPath: updated, errContains: "error reading original plan file", errIs: os.ErrNotExist},
+		{name: "missing new", origPath: orig, newPath: missing, errContains: "error reading new plan file", errIs: os.ErrNotExist},
+		{name: "empty new", origPath: orig, newPath: empty, errContains: "error reading new plan file", errIs: ErrEmptyPlanFile},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			text, _, hasDiff, err := ComparePlanFiles(tc.origPath, tc.newPath)
+			if tc.errIs != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				assert.ErrorIs(t, errors.Cause(err), tc.errIs)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.hasDiff, hasDiff)
+			if tc.hasDiff {
+				assert.Contains(t, text, "~ stage: dev => prod")
+			}
+		})
+	}
+}
+
+func TestComparePlanFiles_Msgpack(t *testing.T) {
+	dir := t.TempDir()
+	orig, updated := filepath.Join(dir, "orig.msgpack"), filepath.Join(dir, "new.msgpack")
+	require.NoError(t, os.WriteFile(orig, []byte(toMsgpack(t, `{"variables": {"stage": {"value": "dev"}}}`)), planFileMode))
+	require.NoError(t, os.WriteFile(updated, []byte(toMsgpack(t, `{"variables": {"stage": {"value": "prod"}}}`)), planFileMode))
+
+	text, _, hasDiff, err := ComparePlanFiles(orig, updated)
+	require.NoError(t, err)
+	assert.True(t, hasDiff)
+	assert.Contains(t, text, "~ stage: dev => prod")
+}
+
+func BenchmarkComparePlanFiles(b *testing.B) {
+	dir := b.TempDir()
+	orig, updated := filepath.Join(dir, "orig.json"), filepath.Join(dir, "new.json")
+	require.NoError(b, os.WriteFile(orig, []byte(hugePlan(20000, 0, "t3.micro")), planFileMode))
+	require.NoError(b, os.WriteFile(updated, []byte(hugePlan(20000, 0, "t3.large")), planFileMode))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _, _ = ComparePlanFiles(orig, updated)
+	}
+}
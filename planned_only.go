@@ -0,0 +1,50 @@
+package comparison
+
+// actionNoOp is the resource_changes action of a resource the plan leaves unchanged.
+const actionNoOp = "no-op"
+
+// getComparedResources returns the resources of a plan that take part in the comparison: every resource
+// found by getResources, with the no-op entries of resource_changes when IncludeNoOpChanges is set, or
+// only the planned changes when PlannedOnly is set.
+func getComparedResources(plan map[string]interface{}, opts *CompareOptions) map[string]interface{} {
+	if opts.PlannedOnly {
+		return getPlannedResources(plan)
+	}
+	return readResources(plan, opts.IncludeNoOpChanges)
+}
+
+// getPlannedResources extracts the entries of resource_changes whose actions are not ["no-op"], keyed
+// by address. Resources only found in prior_state or planned_values are left out.
+func getPlannedResources(plan map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	processResourceChanges(plan, result, true)
+
+	for address, resource := range result {
+		if isNoOpChange(resource) {
+			delete(result, address)
+		}
+	}
+
+	return result
+}
+
+// isNoOpChange reports whether a resource_changes entry plans no action. Imports are planned changes
+// even when their actions are ["no-op"].
+func isNoOpChange(resource interface{}) bool {
+	if _, ok := importID(resource); ok {
+		return false
+	}
+
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	change, ok := resMap["change"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	actions, ok := change["actions"].([]interface{})
+	return ok && len(actions) == 1 && actions[0] == actionNoOp
+}
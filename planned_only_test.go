@@ -0,0 +1,119 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPlannedResources(t *testing.T) {
+	plan := map[string]interface{}{
+		"prior_state": map[string]interface{}{"values": map[string]interface{}{"root_module": map[string]interface{}{
+			"resources": []interface{}{map[string]interface{}{"address": "aws_vpc.main", "values": map[string]interface{}{}}},
+		}}},
+		"resource_changes": []interface{}{
+			map[string]interface{}{"address": "aws_instance.web", "change": map[string]interface{}{"actions": []interface{}{"update"}}},
+			map[string]interface{}{"address": "aws_instance.db", "change": map[string]interface{}{"actions": []interface{}{"no-op"}}},
+			map[string]interface{}{"address": "aws_instance.new", "change": map[string]interface{}{"actions": []interface{}{"delete", "create"}}},
+		},
+	}
+
+	assert.Equal(t, []string{"aws_instance.new", "aws_instance.web"}, getSortedKeys(getPlannedResources(plan), nil))
+	assert.Len(t, getComparedResources(plan, &CompareOptions{}), 4)
+}
+
+func TestComparePlans_PlannedOnly(t *testing.T) {
+	plan := func(dbAMI, webActions, webAMI string) string {
+		return `{
+			"prior_state": {"values": {"root_module": {"resources": [
+				{"address": "aws_instance.db", "values": {"ami": "` + dbAMI + `"}}
+			]}}},
+			"resource_changes": [
+				{"address": "aws_instance.web", "change": {"actions": ` + webActions + `, "after": {"ami": "` + webAMI + `"}}}
+			]
+		}`
+	}
+
+	tests := []struct {
+		name        string
+		orig, new   string
+		hasDiff     bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "unchanged prior state is ignored",
+			orig:        plan("ami-1", `["update"]`, "ami-a"),
+			new:         plan("ami-2", `["update"]`, "ami-b"),
+			hasDiff:     true,
+			contains:    []string{"~ ami: ami-a => ami-b"},
+			notContains: []string{"aws_instance.db"},
+		},
+		{
+			name:        "resource becoming a no-op is removed",
+			orig:        plan("ami-1", `["update"]`, "ami-a"),
+			new:         plan("ami-1", `["no-op"]`, "ami-a"),
+			hasDiff:     true,
+			contains:    []string{"- aws_instance.web"},
+			notContains: []string{"aws_instance.db"},
+		},
+		{
+			name:    "no-op changes in both plans",
+			orig:    plan("ami-1", `["no-op"]`, "ami-a"),
+			new:     plan("ami-2", `["no-op"]`, "ami-b"),
+			hasDiff: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(tc.orig, tc.new, &CompareOptions{PlannedOnly: true})
+			require.NoError(t, err)
+			assert.Equal(t, tc.hasDiff, result.HasDiff)
+			for _, expected := range tc.contains {
+				assert.Contains(t, result.Text, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, result.Text, notExpected)
+			}
+
+			streamed, err := CompareStreaming(tc.orig, strings.NewReader(tc.new), &CompareOptions{PlannedOnly: true}, func(StreamedChange) {})
+			require.NoError(t, err)
+			assert.Equal(t, result.Text, streamed.Text)
+		})
+	}
+
+	// Without the option the prior state is compared too
+	result, err := ComparePlans(plan("ami-1", `["no-op"]`, "ami-a"), plan("ami-2", `["no-op"]`, "ami-a"), &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "~ ami: ami-1 => ami-2")
+}
+
+func TestComparePlans_NoOpChanges(t *testing.T) {
+	plan := func(computedID string) string {
+		return `{
+			"planned_values": {"root_module": {"resources": [
+				{"address": "aws_instance.web", "values": {"ami": "ami-1"}},
+				{"address": "aws_instance.db", "values": {"ami": "ami-1"}}
+			]}},
+			"resource_changes": [
+				{"address": "aws_instance.web", "change": {"actions": ["no-op"], "after": {"ami": "ami-1", "id": "` + computedID + `"}}},
+				{"address": "aws_instance.cache", "change": {"actions": ["no-op"], "after": {"ami": "ami-1"}}}
+			]
+		}`
+	}
+
+	// No-op entries give way to planned_values, and are kept for resources found nowhere else
+	result, err := ComparePlans(plan("i-1"), plan("i-2"), &CompareOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+
+	result, err = ComparePlans(plan("i-1"), plan("i-2"), &CompareOptions{IncludeNoOpChanges: true})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "id"},
+	}, actualChanges(result.Changes))
+}
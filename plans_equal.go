@@ -0,0 +1,67 @@
+package comparison
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// PlansEqual reports whether two plan documents are identical under the default options, i.e. whether
+// ComparePlans would report no diff. It stops at the first difference in the variables, resources or
+// outputs and renders nothing, which makes it a cheap check for CI gating.
+func PlansEqual(origPlanFileJSON, newPlanFileJSON string) (bool, error) {
+	origPlan, err := decodePlan([]byte(origPlanFileJSON), InputFormatAuto)
+	if err != nil {
+		return false, errors.Wrap(err, "error parsing original plan")
+	}
+
+	newPlan, err := decodePlan([]byte(newPlanFileJSON), InputFormatAuto)
+	if err != nil {
+		return false, errors.Wrap(err, "error parsing new plan")
+	}
+
+	// Provider version changes never make the plans differ on their own
+	opts := resolveOptions(nil)
+	opts.fingerprints = make(fingerprintCache)
+	switch {
+	case !reflect.DeepEqual(getVariables(origPlan), getVariables(newPlan)):
+		return false, nil
+	case resourcesDiffer(getResources(origPlan), getResources(newPlan), opts):
+		return false, nil
+	case !reflect.DeepEqual(getOutputs(origPlan), getOutputs(newPlan)):
+		return false, nil
+	}
+	return true, nil
+}
+
+// resourcesDiffer reports whether compareResources would report any change between the resources,
+// stopping at the first one.
+func resourcesDiffer(origResources, newResources map[string]interface{}, opts *CompareOptions) bool {
+	if sameResources(origResources, newResources, opts) {
+		return false
+	}
+	if len(origResources) != len(newResources) {
+		return true
+	}
+
+	for k, origV := range origResources {
+		newV, exists := newResources[k]
+		if !exists {
+			return true
+		}
+		if sameResource(origV, newV, opts) {
+			continue
+		}
+		if modeChanged(origV, newV) {
+			return true
+		}
+
+		origAttrs, newAttrs, skipped := dropSkippedAttributes(getComparedAttributes(origV, opts), getComparedAttributes(newV, opts), opts)
+		normalized, _ := normalizeAttributeSets(newV, origAttrs, newAttrs, opts)
+		if !rewrittenAttributesEqual(origV, newV, origAttrs, newAttrs, normalized || skipped > 0, opts) {
+			return true
+		}
+	}
+
+	return false
+}
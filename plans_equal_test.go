@@ -0,0 +1,99 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlansEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		origJSON string
+		newJSON  string
+		equal    bool
+	}{
+		{
+			name:     "identical plans",
+			origJSON: `{"variables": {"env": {"value": "dev"}}, "resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}}]}`,
+			newJSON:  `{"variables": {"env": {"value": "dev"}}, "resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}}]}`,
+			equal:    true,
+		},
+		{
+			name:     "changed variable",
+			origJSON: `{"variables": {"env": {"value": "dev"}}}`,
+			newJSON:  `{"variables": {"env": {"value": "prod"}}}`,
+		},
+		{
+			name:     "added resource",
+			origJSON: `{"resource_changes": []}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}}]}`,
+		},
+		{
+			name:     "renamed resource",
+			origJSON: `{"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}}]}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_instance.app", "change": {"after": {"ami": "ami-1"}}}]}`,
+		},
+		{
+			name:     "changed attribute",
+			origJSON: `{"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}}]}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2"}}}]}`,
+		},
+		{
+			name:     "changed sensitive value",
+			origJSON: `{"resource_changes": [{"address": "aws_db_instance.main", "change": {"after": {"password": "a"}, "after_sensitive": {"password": true}}}]}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_db_instance.main", "change": {"after": {"password": "b"}, "after_sensitive": {"password": true}}}]}`,
+		},
+		{
+			name:     "unchanged sensitive value",
+			origJSON: `{"resource_changes": [{"address": "aws_db_instance.main", "change": {"after": {"password": "a"}, "after_sensitive": {"password": true}}}]}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_db_instance.main", "change": {"after": {"password": "a"}, "after_sensitive": {"password": true}}}]}`,
+			equal:    true,
+		},
+		{
+			name:     "changed mode",
+			origJSON: `{"resource_changes": [{"address": "aws_s3_bucket.logs", "mode": "managed", "change": {"after": {}}}]}`,
+			newJSON:  `{"resource_changes": [{"address": "aws_s3_bucket.logs", "mode": "data", "change": {"after": {}}}]}`,
+		},
+		{
+			name:     "changed output",
+			origJSON: `{"output_changes": {"url": {"after": "a"}}}`,
+			newJSON:  `{"output_changes": {"url": {"after": "b"}}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			equal, err := PlansEqual(tc.origJSON, tc.newJSON)
+			require.NoError(t, err)
+			assert.Equal(t, tc.equal, equal)
+
+			result, err := ComparePlans(tc.origJSON, tc.newJSON, nil)
+			require.NoError(t, err)
+			assert.Equal(t, !result.HasDiff, equal, "PlansEqual must agree with ComparePlans")
+		})
+	}
+}
+
+func TestPlansEqual_InvalidJSON(t *testing.T) {
+	_, err := PlansEqual(`{`, `{}`)
+	assert.ErrorContains(t, err, "error parsing original plan")
+
+	_, err = PlansEqual(`{}`, `{`)
+	assert.ErrorContains(t, err, "error parsing new plan")
+}
+
+func BenchmarkPlansEqual_Identical(b *testing.B) {
+	plan := hugePlan(20000, -1, "t3.micro")
+	for i := 0; i < b.N; i++ {
+		_, _ = PlansEqual(plan, plan)
+	}
+}
+
+func BenchmarkComparePlans_Identical(b *testing.B) {
+	plan := hugePlan(20000, -1, "t3.micro")
+	for i := 0; i < b.N; i++ {
+		_, _ = ComparePlans(plan, plan, nil)
+	}
+}
@@ -0,0 +1,63 @@
+package comparison
+
+import "strings"
+
+// projectAttributes returns a copy of attrs that only contains the given attribute paths.
+// A path is a dot-separated list of map keys, e.g. "tags.Name".
+func projectAttributes(attrs map[string]interface{}, paths []string) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for _, path := range paths {
+		keys := strings.Split(path, ".")
+
+		value, ok := lookupPath(attrs, keys)
+		if !ok {
+			continue
+		}
+
+		setPath(result, keys, value)
+	}
+
+	return result
+}
+
+// allowAttributes returns a copy of attrs that only contains the given top-level attribute names.
+func allowAttributes(attrs map[string]interface{}, names []string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, name := range names {
+		if value, ok := attrs[name]; ok {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// lookupPath returns the value at the given key path in a nested map.
+func lookupPath(m map[string]interface{}, keys []string) (interface{}, bool) {
+	var current interface{} = m
+	for _, key := range keys {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = currentMap[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath stores value at the given key path, creating intermediate maps as needed.
+func setPath(m map[string]interface{}, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+}
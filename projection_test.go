@@ -0,0 +1,92 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectAttributes(t *testing.T) {
+	attrs := map[string]interface{}{
+		"name": "web",
+		"ami":  "ami-123",
+		"tags": map[string]interface{}{"Name": "web", "Owner": "team-a"},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"name": "web",
+		"tags": map[string]interface{}{"Name": "web"},
+	}, projectAttributes(attrs, []string{"name", "tags.Name", "missing", "ami.nested"}))
+}
+
+func TestAllowAttributes(t *testing.T) {
+	attrs := map[string]interface{}{
+		"name": "web",
+		"ami":  "ami-123",
+		"tags": map[string]interface{}{"Name": "web"},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"ami":  "ami-123",
+		"tags": map[string]interface{}{"Name": "web"},
+	}, allowAttributes(attrs, []string{"ami", "tags", "missing", "tags.Name"}))
+}
+
+func TestCompareResources_ProjectAttributes(t *testing.T) {
+	makeResources := func(name, ami, owner string) map[string]interface{} {
+		return map[string]interface{}{
+			"aws_instance.web": map[string]interface{}{
+				"values": map[string]interface{}{
+					"name": name,
+					"ami":  ami,
+					"tags": map[string]interface{}{"Name": "web", "Owner": owner},
+				},
+			},
+		}
+	}
+
+	opts := &CompareOptions{ProjectAttributes: []string{"name", "tags.Name"}}
+
+	// Unlisted attribute changes produce no diff at all
+	diff, diffMap := compareResources(makeResources("web", "ami-1", "team-a"), makeResources("web", "ami-2", "team-b"), opts)
+	assert.Empty(t, diff)
+	assert.Empty(t, diffMap["changed"])
+
+	// Listed attribute changes are reported without the unlisted ones
+	diff, diffMap = compareResources(makeResources("web", "ami-1", "team-a"), makeResources("api", "ami-2", "team-b"), opts)
+	assert.Contains(t, diff, "~ name: web => api")
+	assert.NotContains(t, diff, "ami")
+	assert.NotContains(t, diff, "Owner")
+	assert.Len(t, diffMap["changed"], 1)
+}
+
+func TestComparePlans_AttributeAllowlist(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.api", "change": {"after": {"id": "i-1", "ami": "ami-1", "instance_type": "t3.micro", "user_data": "a"}}},
+		{"address": "aws_instance.web", "change": {"after": {"id": "i-2", "ami": "ami-1", "instance_type": "t3.micro", "user_data": "a"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.api", "change": {"after": {"id": "i-1", "ami": "ami-1", "instance_type": "t3.micro", "user_data": "b"}}},
+		{"address": "aws_instance.web", "change": {"after": {"id": "i-3", "ami": "ami-2", "instance_type": "t3.micro", "user_data": "b"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{AttributeAllowlist: []string{"ami", "id", "instance_type", "tags"}})
+	assert.NoError(t, err)
+	assert.Contains(t, result.Text, "aws_instance.web\n  ~ id: i-2 => i-3\n  ~ ami: ami-1 => ami-2\n")
+	assert.NotContains(t, result.Text, "aws_instance.api")
+	assert.NotContains(t, result.Text, "user_data")
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "id"},
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+	}, actualChanges(result.Changes))
+
+	// Both options restrict the compared attributes
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{
+		ProjectAttributes:  []string{"ami", "user_data"},
+		AttributeAllowlist: []string{"ami", "id"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+	}, actualChanges(result.Changes))
+}
@@ -0,0 +1,130 @@
+package comparison
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatProperties renders a diff map as a Java .properties file. Changed variables, outputs and resource
+// attributes become `<key>.old=value` and `<key>.new=value` lines, where the key is `var.<name>`,
+// `output.<name>` or `<address>.<attribute>`. Added, removed, moved, mode-changed and count-changed resources
+// get a `<address>.status=<kind>` line, keyed by the new address of a moved resource, and the changed address,
+// mode or count is written like an attribute. Tag-only changes are written like other changed resources, and
+// drift like resources under the `drift.` prefix. Sensitive values are redacted and other non-string values
+// written as JSON. Lines are sorted by key.
+func FormatProperties(diffMap map[string]interface{}) string {
+	props := make(map[string]string)
+
+	variables, _ := diffMap["variables"].(map[string]interface{})
+	addPropertyChanges(props, "var.", variables, propertyValue)
+	outputs, _ := diffMap["outputs"].(map[string]interface{})
+	addPropertyChanges(props, "output.", outputs, func(value interface{}) string {
+		return propertyValue(csvOutputValue(value))
+	})
+
+	for _, section := range []struct{ name, prefix string }{{"resources", ""}, {"tag_changes", ""}, {"drift", "drift."}} {
+		categories, _ := diffMap[section.name].(map[string]interface{})
+		addResourceProperties(props, section.prefix, categories)
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", escapeProperty(k, true), escapeProperty(props[k], false)))
+	}
+	return sb.String()
+}
+
+// addResourceProperties adds the status and attribute changes of the entries of a resources section, with
+// keys under prefix.
+func addResourceProperties(props map[string]string, prefix string, categories map[string]interface{}) {
+	for _, kind := range []string{"count_changed", "added", "removed", "moved", "mode_changed"} {
+		for _, entry := range entryList(categories[kind]) {
+			key := prefix + entryAddress(entry)
+			props[key+".status"] = kind
+			for _, field := range csvPairedFields {
+				if _, ok := entry[field.old]; ok {
+					props[key+"."+field.attribute+".old"] = propertyValue(entry[field.old])
+					props[key+"."+field.attribute+".new"] = propertyValue(entry[field.new])
+				}
+			}
+		}
+	}
+
+	for _, kind := range []string{"moved", "changed"} {
+		for _, entry := range entryList(categories[kind]) {
+			attrs, _ := entry["attributes"].(map[string]interface{})
+			addPropertyChanges(props, prefix+entryAddress(entry)+".", attrs, propertyValue)
+		}
+	}
+}
+
+// addPropertyChanges adds the old and new values of added, removed and changed entries under prefix, formatted
+// with format.
+func addPropertyChanges(props map[string]string, prefix string, categories map[string]interface{}, format func(interface{}) string) {
+	for _, entry := range entryList(categories["added"]) {
+		props[prefix+entryKey(entry)+".new"] = format(entry["value"])
+	}
+	for _, entry := range entryList(categories["removed"]) {
+		props[prefix+entryKey(entry)+".old"] = format(entry["value"])
+	}
+	for _, entry := range entryList(categories["changed"]) {
+		props[prefix+entryKey(entry)+".old"] = format(entry["old"])
+		props[prefix+entryKey(entry)+".new"] = format(entry["new"])
+	}
+}
+
+// propertyValue returns strings as-is, redacts sensitive values like formatValue and encodes any other value
+// as JSON.
+func propertyValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	if isSensitive(value) {
+		return sensitiveValueText
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// escapeProperty escapes a key or value for the .properties format. Keys also escape spaces, and values
+// escape a leading space so it is not trimmed on load.
+func escapeProperty(s string, isKey bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case ' ':
+			if isKey || i == 0 {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
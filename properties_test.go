@@ -0,0 +1,117 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatProperties(t *testing.T) {
+	diffMap := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"changed": []map[string]interface{}{{"name": "env", "old": "dev", "new": "prod"}},
+		},
+		"outputs": map[string]interface{}{
+			"added": []map[string]interface{}{{"name": "url", "value": "https://example.com:8443/a=b"}},
+		},
+		"resources": map[string]interface{}{
+			"added":   []map[string]interface{}{{"address": `aws_s3_bucket.logs["a b"]`}},
+			"removed": []map[string]interface{}{{"address": "aws_s3_bucket.old"}},
+			"changed": []map[string]interface{}{{
+				"address": "aws_instance.web",
+				"attributes": map[string]interface{}{
+					"changed": []map[string]interface{}{
+						{"name": "user_data", "old": "#!/bin/sh\necho a", "new": " echo b\\c"},
+						{"name": "count", "old": 1.0, "new": 2.0},
+					},
+				},
+			}},
+		},
+		"tag_changes": map[string]interface{}{
+			"changed": []map[string]interface{}{{
+				"address": "aws_s3_bucket.old",
+				"attributes": map[string]interface{}{
+					"changed": []map[string]interface{}{{"name": "tags.Owner", "old": "a", "new": "b"}},
+				},
+			}},
+		},
+	}
+
+	text := FormatProperties(diffMap)
+	assert.Contains(t, text, "var.env.old=dev\n")
+	assert.Contains(t, text, `output.url.new=https\://example.com\:8443/a\=b`)
+	assert.Contains(t, text, `aws_instance.web.user_data.old=\#\!/bin/sh\necho a`)
+
+	// The file must round-trip through a properties parser
+	loaded, err := properties.LoadString(text)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"var.env.old":                      "dev",
+		"var.env.new":                      "prod",
+		"output.url.new":                   "https://example.com:8443/a=b",
+		`aws_s3_bucket.logs["a b"].status`: "added",
+		"aws_s3_bucket.old.status":         "removed",
+		"aws_instance.web.user_data.old":   "#!/bin/sh\necho a",
+		"aws_instance.web.user_data.new":   " echo b\\c",
+		"aws_instance.web.count.old":       "1",
+		"aws_instance.web.count.new":       "2",
+		"aws_s3_bucket.old.tags.Owner.old": "a",
+		"aws_s3_bucket.old.tags.Owner.new": "b",
+	}, loaded.Map())
+}
+
+func TestFormatProperties_ComparedPlans(t *testing.T) {
+	origJSON := `{
+		"resource_changes": [
+			{"address": "aws_instance.web[0]", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.web[1]", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.old", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_s3_bucket.site", "mode": "managed", "change": {"after": {"bucket": "site"}}}
+		],
+		"planned_values": {"outputs": {
+			"db_password": {"value": "hunter2", "sensitive": true},
+			"url": {"value": "https://a", "sensitive": false}
+		}}
+	}`
+	newJSON := `{
+		"resource_changes": [
+			{"address": "aws_instance.web[0]", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.web[1]", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.web[2]", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.new", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_s3_bucket.site", "mode": "data", "change": {"after": {"bucket": "site"}}}
+		],
+		"resource_drift": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}],
+		"planned_values": {"outputs": {
+			"db_password": {"value": "hunter3", "sensitive": true},
+			"url": {"value": "https://b", "sensitive": false}
+		}}
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{CollapseCountChurn: true, DriftMode: DriftModeSection})
+	require.NoError(t, err)
+
+	text := FormatProperties(result.Changes)
+	assert.NotContains(t, text, "hunter")
+
+	loaded, err := properties.LoadString(text)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"output.db_password.old":          "(sensitive value)",
+		"output.db_password.new":          "(sensitive value)",
+		"output.url.old":                  "https://a",
+		"output.url.new":                  "https://b",
+		"aws_instance.web.status":         "count_changed",
+		"aws_instance.web.count.old":      "2",
+		"aws_instance.web.count.new":      "3",
+		"aws_instance.new.status":         "moved",
+		"aws_instance.new.address.old":    "aws_instance.old",
+		"aws_instance.new.address.new":    "aws_instance.new",
+		"aws_s3_bucket.site.status":       "mode_changed",
+		"aws_s3_bucket.site.mode.old":     "managed",
+		"aws_s3_bucket.site.mode.new":     "data",
+		"drift.aws_s3_bucket.logs.status": "added",
+	}, loaded.Map())
+}
@@ -0,0 +1,81 @@
+package comparison
+
+import (
+	"reflect"
+	"strings"
+)
+
+// providerNoiseRule describes an attribute whose churn is known to be noise for a provider.
+type providerNoiseRule struct {
+	attribute string
+	// caseOnly limits the suppression to values that only differ in letter casing.
+	caseOnly bool
+}
+
+// knownProviderNoise maps a provider's short name to the attributes known to churn without a real change.
+var knownProviderNoise = map[string][]providerNoiseRule{
+	"aws": {
+		{attribute: "arn"},
+		{attribute: "unique_id"},
+		{attribute: "owner_id"},
+	},
+	"azurerm": {
+		{attribute: "id", caseOnly: true},
+	},
+	"google": {
+		{attribute: "fingerprint"},
+		{attribute: "label_fingerprint"},
+	},
+}
+
+// providerShortName returns the short provider name (e.g. "aws") from a resource's provider_name.
+func providerShortName(resource interface{}) string {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	name, ok := resMap["provider_name"].(string)
+	if !ok {
+		return ""
+	}
+
+	// provider_name is usually fully qualified, e.g. "registry.terraform.io/hashicorp/aws"
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
+
+// suppressProviderNoise removes known-noisy attributes from both attribute sets.
+// It reports whether any attribute change was suppressed.
+func suppressProviderNoise(provider string, origAttrs, newAttrs map[string]interface{}) bool {
+	suppressed := false
+
+	for _, rule := range knownProviderNoise[provider] {
+		origV, origExists := origAttrs[rule.attribute]
+		newV, newExists := newAttrs[rule.attribute]
+
+		if !origExists && !newExists || reflect.DeepEqual(origV, newV) {
+			continue
+		}
+
+		if rule.caseOnly && !equalFoldValues(origV, newV) {
+			continue
+		}
+
+		delete(origAttrs, rule.attribute)
+		delete(newAttrs, rule.attribute)
+		suppressed = true
+	}
+
+	return suppressed
+}
+
+// equalFoldValues reports whether both values are strings that are equal ignoring case.
+func equalFoldValues(a, b interface{}) bool {
+	aStr, aOk := a.(string)
+	bStr, bOk := b.(string)
+	return aOk && bOk && strings.EqualFold(aStr, bStr)
+}
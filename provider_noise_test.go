@@ -0,0 +1,87 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppressKnownProviderNoise(t *testing.T) {
+	makeResource := func(providerName, arn, instanceType string) map[string]interface{} {
+		return map[string]interface{}{
+			"aws_instance.web": map[string]interface{}{
+				"provider_name": providerName,
+				"values": map[string]interface{}{
+					"arn":           arn,
+					"instance_type": instanceType,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		origRes     map[string]interface{}
+		newRes      map[string]interface{}
+		suppress    bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "aws arn churn shown when disabled",
+			origRes:  makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:1", "t3.micro"),
+			newRes:   makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:2", "t3.micro"),
+			suppress: false,
+			contains: []string{"aws_instance.web", "~ arn:"},
+		},
+		{
+			name:        "aws arn churn suppressed when enabled",
+			origRes:     makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:1", "t3.micro"),
+			newRes:      makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:2", "t3.micro"),
+			suppress:    true,
+			notContains: []string{"aws_instance.web", "arn"},
+		},
+		{
+			name:        "real changes still reported alongside suppressed noise",
+			origRes:     makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:1", "t3.micro"),
+			newRes:      makeResource("registry.terraform.io/hashicorp/aws", "arn:aws:ec2:2", "t3.large"),
+			suppress:    true,
+			contains:    []string{"aws_instance.web", "~ instance_type: t3.micro => t3.large"},
+			notContains: []string{"arn"},
+		},
+		{
+			name:     "arn is not noise for other providers",
+			origRes:  makeResource("registry.terraform.io/hashicorp/google", "arn:1", "t3.micro"),
+			newRes:   makeResource("registry.terraform.io/hashicorp/google", "arn:2", "t3.micro"),
+			suppress: true,
+			contains: []string{"~ arn:"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _ := compareResources(tc.origRes, tc.newRes, &CompareOptions{SuppressKnownProviderNoise: tc.suppress})
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff, notExpected)
+			}
+		})
+	}
+}
+
+func TestSuppressProviderNoise_AzureIDCasing(t *testing.T) {
+	origAttrs := map[string]interface{}{"id": "/subscriptions/abc/resourceGroups/RG"}
+	newAttrs := map[string]interface{}{"id": "/subscriptions/abc/resourcegroups/rg"}
+
+	assert.True(t, suppressProviderNoise("azurerm", origAttrs, newAttrs))
+	assert.NotContains(t, origAttrs, "id")
+
+	origAttrs = map[string]interface{}{"id": "/subscriptions/abc/resourceGroups/one"}
+	newAttrs = map[string]interface{}{"id": "/subscriptions/abc/resourceGroups/two"}
+
+	assert.False(t, suppressProviderNoise("azurerm", origAttrs, newAttrs))
+	assert.Contains(t, origAttrs, "id")
+}
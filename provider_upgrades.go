@@ -0,0 +1,152 @@
+package comparison
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// majorVersionPattern matches the first version number in a provider version constraint.
+var majorVersionPattern = regexp.MustCompile(`(\d+)(\.\d+)*`)
+
+// compareProviderUpgrades reports providers whose major version changed between the plans, together with
+// the changed resources that belong to each provider, read from the changed entries of the given resource
+// sections. Upgrades explain other changes rather than being changes themselves, so the caller only reports
+// them next to other changes.
+func compareProviderUpgrades(origPlan, newPlan map[string]interface{}, resourceSections ...interface{}) (string, []map[string]interface{}, bool) {
+	origVersions, newVersions := getProviderVersions(origPlan), getProviderVersions(newPlan)
+
+	providers := make([]string, 0, len(newVersions))
+	for name := range newVersions {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	affected := affectedResourcesByProvider(newPlan, resourceSections...)
+	upgrades := make([]map[string]interface{}, 0)
+
+	var diff strings.Builder
+	for _, name := range providers {
+		origVersion, exists := origVersions[name]
+		if !exists {
+			continue
+		}
+
+		origMajor, origOk := majorVersion(origVersion)
+		newMajor, newOk := majorVersion(newVersions[name])
+		if !origOk || !newOk || origMajor == newMajor {
+			continue
+		}
+
+		resources := affected[name]
+		diff.WriteString(fmt.Sprintf("~ provider %s upgraded from v%d to v%d, affecting %d resources\n",
+			name, origMajor, newMajor, len(resources)))
+		for _, address := range resources {
+			diff.WriteString(fmt.Sprintf("  %s\n", address))
+		}
+
+		upgrades = append(upgrades, map[string]interface{}{
+			"provider":           name,
+			"old_version":        origVersion,
+			"new_version":        newVersions[name],
+			"old_major":          origMajor,
+			"new_major":          newMajor,
+			"affected_resources": resources,
+		})
+	}
+
+	if len(upgrades) == 0 {
+		return "", nil, false
+	}
+
+	return "Provider Upgrades:\n------------------\n" + diff.String() + "\n", upgrades, true
+}
+
+// getProviderVersions extracts the version constraint per provider from a plan's configuration block.
+func getProviderVersions(plan map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+
+	configuration, ok := plan["configuration"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	providerConfig, ok := configuration["provider_config"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	for key, v := range providerConfig {
+		provider, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := provider["name"].(string)
+		if !ok {
+			name = key
+		}
+
+		version, ok := provider["version_constraint"].(string)
+		if !ok || version == "" {
+			continue
+		}
+
+		// Aliased configurations of the same provider share its version
+		if _, exists := result[name]; !exists {
+			result[name] = version
+		}
+	}
+
+	return result
+}
+
+// majorVersion returns the major version from a version constraint such as "~> 5.0".
+func majorVersion(constraint string) (int, bool) {
+	match := majorVersionPattern.FindStringSubmatch(constraint)
+	if match == nil {
+		return 0, false
+	}
+
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return major, true
+}
+
+// affectedResourcesByProvider groups the changed resource addresses in resource diff sections, such as
+// resources and tag_changes, by provider short name.
+func affectedResourcesByProvider(newPlan map[string]interface{}, resourceSections ...interface{}) map[string][]string {
+	result := make(map[string][]string)
+
+	var newResources map[string]interface{}
+	for _, section := range resourceSections {
+		sectionMap, ok := section.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, entry := range entryList(sectionMap["changed"]) {
+			address, ok := entry["address"].(string)
+			if !ok {
+				continue
+			}
+
+			if newResources == nil {
+				newResources = getResources(newPlan)
+			}
+			provider := providerShortName(newResources[address])
+			result[provider] = append(result[provider], address)
+		}
+	}
+
+	for _, addresses := range result {
+		sort.Strings(addresses)
+	}
+
+	return result
+}
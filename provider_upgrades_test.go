@@ -0,0 +1,99 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func providerUpgradePlan(awsConstraint, instanceType string) string {
+	return `{
+		"resource_changes": [
+			{"address": "aws_instance.a", "provider_name": "registry.terraform.io/hashicorp/aws",
+			 "change": {"after": {"instance_type": "` + instanceType + `"}}},
+			{"address": "aws_instance.b", "provider_name": "registry.terraform.io/hashicorp/aws",
+			 "change": {"after": {"instance_type": "` + instanceType + `"}}},
+			{"address": "random_id.c", "provider_name": "registry.terraform.io/hashicorp/random",
+			 "change": {"after": {"byte_length": 8}}}
+		],
+		"configuration": {"provider_config": {
+			"aws": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "version_constraint": "` + awsConstraint + `"},
+			"random": {"name": "random", "version_constraint": "~> 3.0"}
+		}}
+	}`
+}
+
+func TestCompareProviderUpgrades(t *testing.T) {
+	result, err := ComparePlans(providerUpgradePlan("~> 4.0", "t3.micro"), providerUpgradePlan("~> 5.0", "t3.large"), nil)
+	require.NoError(t, err)
+	require.True(t, result.HasDiff)
+
+	assert.Contains(t, result.Text, "~ provider aws upgraded from v4 to v5, affecting 2 resources")
+
+	upgrades := result.Changes["provider_upgrades"].([]map[string]interface{})
+	require.Len(t, upgrades, 1)
+	assert.Equal(t, "aws", upgrades[0]["provider"])
+	assert.Equal(t, 4, upgrades[0]["old_major"])
+	assert.Equal(t, 5, upgrades[0]["new_major"])
+	assert.Equal(t, []string{"aws_instance.a", "aws_instance.b"}, upgrades[0]["affected_resources"])
+}
+
+func TestCompareProviderUpgrades_MinorBumpIgnored(t *testing.T) {
+	result, err := ComparePlans(providerUpgradePlan("~> 5.0", "t3.micro"), providerUpgradePlan("~> 5.40", "t3.micro"), nil)
+	require.NoError(t, err)
+
+	assert.False(t, result.HasDiff)
+	assert.NotContains(t, result.Changes, "provider_upgrades")
+}
+
+func TestCompareProviderUpgrades_NoAffectedResources(t *testing.T) {
+	orig, updated := providerUpgradePlan("~> 4.0", "t3.micro"), providerUpgradePlan("~> 5.0", "t3.micro")
+
+	result, err := ComparePlans(orig, updated, nil)
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.Empty(t, result.Text)
+	assert.NotContains(t, result.Changes, "provider_upgrades")
+
+	equal, err := PlansEqual(orig, updated)
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	// Next to other changes the upgrade is reported, even without affected resources
+	result, err = ComparePlans(orig, strings.Replace(updated, `"byte_length": 8`, `"byte_length": 16`, 1), nil)
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "~ provider aws upgraded from v4 to v5, affecting 0 resources\n")
+}
+
+func TestCompareProviderUpgrades_TagOnlyChanges(t *testing.T) {
+	plan := func(constraint, owner string) string {
+		return `{
+			"resource_changes": [
+				{"address": "aws_s3_bucket.logs", "provider_name": "registry.terraform.io/hashicorp/aws",
+				 "change": {"after": {"bucket": "logs", "tags": {"Owner": "` + owner + `"}}}}
+			],
+			"configuration": {"provider_config": {"aws": {"name": "aws", "version_constraint": "` + constraint + `"}}}
+		}`
+	}
+
+	result, err := ComparePlans(plan("~> 4.0", "a"), plan("~> 5.0", "b"), &CompareOptions{SeparateTagChanges: true})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "~ provider aws upgraded from v4 to v5, affecting 1 resources\n  aws_s3_bucket.logs\n")
+
+	upgrades := result.Changes["provider_upgrades"].([]map[string]interface{})
+	require.Len(t, upgrades, 1)
+	assert.Equal(t, []string{"aws_s3_bucket.logs"}, upgrades[0]["affected_resources"])
+}
+
+func TestMajorVersion(t *testing.T) {
+	major, ok := majorVersion(">= 4.2, < 6.0")
+	assert.True(t, ok)
+	assert.Equal(t, 4, major)
+
+	_, ok = majorVersion("latest")
+	assert.False(t, ok)
+}
@@ -0,0 +1,33 @@
+package comparison
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// CompareRawKeys deep-diffs the named top-level keys of two plan JSON documents verbatim, without the
+// semantic handling of variables, resources and outputs. It covers plan keys the structured comparison
+// does not model. Change paths start with the top-level key, e.g. "apply_errored" or "custom.items[0]".
+func CompareRawKeys(origPlanJSON, newPlanJSON string, keys []string) ([]Change, error) {
+	var origPlan, newPlan map[string]interface{}
+	if err := json.Unmarshal([]byte(origPlanJSON), &origPlan); err != nil {
+		return nil, errors.Wrap(err, "error parsing original plan JSON")
+	}
+	if err := json.Unmarshal([]byte(newPlanJSON), &newPlan); err != nil {
+		return nil, errors.Wrap(err, "error parsing new plan JSON")
+	}
+
+	return DeepDiff(selectKeys(origPlan, keys), selectKeys(newPlan, keys))
+}
+
+// selectKeys returns the entries of m whose keys are listed.
+func selectKeys(m map[string]interface{}, keys []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if v, ok := m[key]; ok {
+			result[key] = v
+		}
+	}
+	return result
+}
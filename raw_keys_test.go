@@ -0,0 +1,58 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareRawKeys(t *testing.T) {
+	orig := `{
+		"apply_errored": false,
+		"custom_wrapper": {"owner": "team-a", "checks": ["lint"]},
+		"variables": {"env": {"value": "dev"}}
+	}`
+	updated := `{
+		"custom_wrapper": {"owner": "team-b", "checks": ["lint", "test"]},
+		"variables": {"env": {"value": "prod"}}
+	}`
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []Change
+	}{
+		{
+			name: "custom key",
+			keys: []string{"custom_wrapper"},
+			expected: []Change{
+				{Path: "custom_wrapper.checks[1]", Kind: ChangeAdded, New: "test"},
+				{Path: "custom_wrapper.owner", Kind: ChangeModified, Old: "team-a", New: "team-b"},
+			},
+		},
+		{
+			name:     "key removed from new plan",
+			keys:     []string{"apply_errored"},
+			expected: []Change{{Path: "apply_errored", Kind: ChangeRemoved, Old: false}},
+		},
+		{
+			name:     "unlisted keys are ignored",
+			keys:     []string{"missing"},
+			expected: []Change{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := CompareRawKeys(orig, updated, tc.keys)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, changes)
+		})
+	}
+}
+
+func TestCompareRawKeys_InvalidJSON(t *testing.T) {
+	_, err := CompareRawKeys("{}", "not json", []string{"a"})
+	require.Error(t, err)
+}
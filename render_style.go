@@ -0,0 +1,56 @@
+package comparison
+
+import "fmt"
+
+// ChangeStyle selects how a value change is rendered in the text diff.
+type ChangeStyle int
+
+const (
+	// ChangeStyleArrow renders changes as "old => new". This is the default.
+	ChangeStyleArrow ChangeStyle = iota
+
+	// ChangeStyleReverseArrow renders changes as "new <= old".
+	ChangeStyleReverseArrow
+
+	// ChangeStyleLabels renders changes with explicit labels, e.g. "ami from ami-123 to ami-456".
+	ChangeStyleLabels
+)
+
+// RenderStyle configures how changes are rendered in the text diff.
+type RenderStyle struct {
+	// Change selects the arrow or label style.
+	Change ChangeStyle
+
+	// Arrow overrides the arrow used by the arrow styles. Defaults to "=>" or "<=".
+	Arrow string
+}
+
+// renderTransition renders the transition between two formatted values in the configured style.
+func renderTransition(origValue, newValue string, opts *CompareOptions) string {
+	style := opts.RenderStyle
+
+	switch style.Change {
+	case ChangeStyleReverseArrow:
+		arrow := style.Arrow
+		if arrow == "" {
+			arrow = "<="
+		}
+		return fmt.Sprintf("%s %s %s", newValue, arrow, origValue)
+	case ChangeStyleLabels:
+		return fmt.Sprintf("from %s to %s", origValue, newValue)
+	default:
+		arrow := style.Arrow
+		if arrow == "" {
+			arrow = "=>"
+		}
+		return fmt.Sprintf("%s %s %s", origValue, arrow, newValue)
+	}
+}
+
+// formatChange renders a named change between two formatted values in the configured style.
+func formatChange(key, origValue, newValue string, opts *CompareOptions) string {
+	if opts.RenderStyle.Change == ChangeStyleLabels {
+		return fmt.Sprintf("%s %s", key, renderTransition(origValue, newValue, opts))
+	}
+	return fmt.Sprintf("%s: %s", key, renderTransition(origValue, newValue, opts))
+}
@@ -0,0 +1,73 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderStyle(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     RenderStyle
+		attribute string
+		variable  string
+		output    string
+	}{
+		{
+			name:      "default arrow",
+			style:     RenderStyle{},
+			attribute: "  ~ ami: ami-123 => ami-456\n",
+			variable:  "~ stage: dev => prod\n",
+			output:    "~ url: a => b\n",
+		},
+		{
+			name:      "reverse arrow",
+			style:     RenderStyle{Change: ChangeStyleReverseArrow},
+			attribute: "  ~ ami: ami-456 <= ami-123\n",
+			variable:  "~ stage: prod <= dev\n",
+			output:    "~ url: b <= a\n",
+		},
+		{
+			name:      "custom arrow",
+			style:     RenderStyle{Arrow: "->"},
+			attribute: "  ~ ami: ami-123 -> ami-456\n",
+			variable:  "~ stage: dev -> prod\n",
+			output:    "~ url: a -> b\n",
+		},
+		{
+			name:      "labels",
+			style:     RenderStyle{Change: ChangeStyleLabels},
+			attribute: "  ~ ami from ami-123 to ami-456\n",
+			variable:  "~ stage from dev to prod\n",
+			output:    "~ url from a to b\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &CompareOptions{RenderStyle: tc.style}
+
+			resourceDiff, _ := compareResources(
+				map[string]interface{}{"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-123"}}},
+				map[string]interface{}{"aws_instance.web": map[string]interface{}{"values": map[string]interface{}{"ami": "ami-456"}}},
+				opts,
+			)
+			assert.Contains(t, resourceDiff, tc.attribute)
+
+			varsDiff, _, _ := compareVariables(
+				map[string]interface{}{"variables": makeVariablesMap(map[string]interface{}{"stage": "dev"})},
+				map[string]interface{}{"variables": makeVariablesMap(map[string]interface{}{"stage": "prod"})},
+				opts,
+			)
+			assert.Contains(t, varsDiff, tc.variable)
+
+			outputsDiff, _ := compareOutputs(
+				map[string]interface{}{"url": map[string]interface{}{"sensitive": false, "value": "a"}},
+				map[string]interface{}{"url": map[string]interface{}{"sensitive": false, "value": "b"}},
+				opts,
+			)
+			assert.Contains(t, outputsDiff, tc.output)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// replacePaths returns the attribute paths that force a resource_changes entry to be replaced, e.g.
+// `instance_type` or `network_interface[0].subnet_id`. It is empty unless the entry plans a replacement.
+func replacePaths(resource interface{}) []string {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	change, ok := resMap["change"].(map[string]interface{})
+	if !ok || !isReplacement(change) {
+		return nil
+	}
+
+	rawPaths, _ := change["replace_paths"].([]interface{})
+	paths := make([]string, 0, len(rawPaths))
+	for _, rawPath := range rawPaths {
+		steps, ok := rawPath.([]interface{})
+		if !ok {
+			continue
+		}
+
+		path := ""
+		for _, step := range steps {
+			switch step := step.(type) {
+			case string:
+				path = joinPath(path, step)
+			case float64:
+				path = elementPath(path, int(step))
+			}
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// isReplacement reports whether a change deletes and recreates its resource, in either order.
+func isReplacement(change map[string]interface{}) bool {
+	actions, ok := change["actions"].([]interface{})
+	if !ok || len(actions) != 2 {
+		return false
+	}
+	return (actions[0] == "delete" && actions[1] == "create") || (actions[0] == "create" && actions[1] == "delete")
+}
+
+// replaceNote explains which attribute paths force a replacement, for the resource line of the text diff.
+func replaceNote(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (forces replacement due to: %s)", strings.Join(paths, ", "))
+}
+
+// tagReplacement records the attribute paths forcing a replacement on a diff map entry, when the
+// resource is being replaced because of them.
+func tagReplacement(entry map[string]interface{}, paths []string) map[string]interface{} {
+	if len(paths) > 0 {
+		entry["replace_paths"] = paths
+	}
+	return entry
+}
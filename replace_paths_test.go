@@ -0,0 +1,82 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "replacement",
+			change: map[string]interface{}{
+				"actions": []interface{}{"delete", "create"},
+				"replace_paths": []interface{}{
+					[]interface{}{"instance_type"},
+					[]interface{}{"network_interface", float64(0), "subnet_id"},
+				},
+			},
+			expected: []string{"instance_type", "network_interface[0].subnet_id"},
+		},
+		{
+			name: "create before destroy",
+			change: map[string]interface{}{
+				"actions":       []interface{}{"create", "delete"},
+				"replace_paths": []interface{}{[]interface{}{"ami"}},
+			},
+			expected: []string{"ami"},
+		},
+		{
+			name:     "replacement without paths",
+			change:   map[string]interface{}{"actions": []interface{}{"delete", "create"}},
+			expected: []string{},
+		},
+		{
+			name: "update",
+			change: map[string]interface{}{
+				"actions":       []interface{}{"update"},
+				"replace_paths": []interface{}{[]interface{}{"ami"}},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replacePaths(map[string]interface{}{"change": tt.change}))
+		})
+	}
+}
+
+func TestComparePlans_ReplacePaths(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"actions": ["update"], "after": {"ami": "ami-1", "instance_type": "t3.micro"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"actions": ["delete", "create"], "after": {"ami": "ami-2", "instance_type": "t3.large"},
+			"replace_paths": [["instance_type"], ["ami"]]}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "aws_instance.web (forces replacement due to: instance_type, ami)\n")
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	changed := entryList(resources["changed"])
+	require.Len(t, changed, 1)
+	assert.Equal(t, []string{"instance_type", "ami"}, changed[0]["replace_paths"])
+
+	// Resources that are not replaced have no replace paths
+	result, err = ComparePlans(newJSON, origJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Text, "forces replacement")
+	changed = entryList(result.Changes["resources"].(map[string]interface{})["changed"])
+	require.Len(t, changed, 1)
+	assert.NotContains(t, changed[0], "replace_paths")
+}
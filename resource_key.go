@@ -0,0 +1,98 @@
+package comparison
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/log"
+)
+
+// ResourceKeyFunc returns the key used to pair a resource with its counterpart in the other plan.
+// An empty key falls back to the resource address.
+type ResourceKeyFunc func(resMap map[string]interface{}) string
+
+// WithResourceKeyFunc pairs resources across plans by the key returned from fn instead of their address,
+// e.g. by a tag value, by id or by a composite of attributes.
+func WithResourceKeyFunc(fn ResourceKeyFunc) Option {
+	return func(o *CompareOptions) {
+		o.ResourceKeyFunc = fn
+	}
+}
+
+// keyResources returns the resources rekeyed by keyFunc. When two resources produce the same key, the first
+// in address order keeps it and the other keeps its address; keys never displace a resource keeping its
+// address.
+func keyResources(resources map[string]interface{}, keyFunc ResourceKeyFunc) map[string]interface{} {
+	return rekeyResources(resources, func(address string, resource interface{}) string {
+		if resMap, ok := resource.(map[string]interface{}); ok {
+			return keyFunc(resMap)
+		}
+		return ""
+	})
+}
+
+// rekeyResources returns the resources under the keys returned by keyOf, where an empty key keeps the
+// address. Resources keeping their address claim it first, then the others claim their keys in address
+// order. A resource whose key is already claimed keeps its address, and one whose address is claimed as
+// well gets its address with a numbered suffix, so no resource is overwritten. Collisions are logged.
+func rekeyResources(resources map[string]interface{}, keyOf func(address string, resource interface{}) string) map[string]interface{} {
+	result := make(map[string]interface{}, len(resources))
+
+	// Resolve keys in address order so collisions are handled deterministically
+	addresses := make([]string, 0, len(resources))
+	for address := range resources {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	keys := make(map[string]string, len(resources))
+	for _, address := range addresses {
+		key := keyOf(address, resources[address])
+		if key == "" || key == address {
+			result[address] = resources[address]
+			continue
+		}
+		keys[address] = key
+	}
+
+	keyedBy := make(map[string]string, len(keys))
+	fallbacks := make([]string, 0)
+	for _, address := range addresses {
+		key, ok := keys[address]
+		if !ok {
+			continue
+		}
+		if _, exists := result[key]; exists {
+			log.Warn("Resource key collision, keeping address", "key", key, "address", address, "paired", pairedAddress(keyedBy, key))
+			fallbacks = append(fallbacks, address)
+			continue
+		}
+		keyedBy[key] = address
+		result[key] = resources[address]
+	}
+
+	for _, address := range fallbacks {
+		key := address
+		for n := 2; ; n++ {
+			if _, exists := result[key]; !exists {
+				break
+			}
+			key = fmt.Sprintf("%s#%d", address, n)
+		}
+		if key != address {
+			log.Warn("Resource address collision, renaming", "address", address, "key", key, "paired", pairedAddress(keyedBy, address))
+		}
+		result[key] = resources[address]
+	}
+
+	return result
+}
+
+// pairedAddress returns the address of the resource that claimed a key, which is the key itself when a
+// resource kept its address.
+func pairedAddress(keyedBy map[string]string, key string) string {
+	if address, ok := keyedBy[key]; ok {
+		return address
+	}
+	return key
+}
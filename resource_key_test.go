@@ -0,0 +1,86 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagNameKey(resMap map[string]interface{}) string {
+	attrs := getResourceAttributes(resMap, &CompareOptions{})
+	tags, _ := attrs["tags"].(map[string]interface{})
+	name, _ := tags["Name"].(string)
+	return name
+}
+
+func TestWithResourceKeyFunc_PairsByTag(t *testing.T) {
+	plan := func(address, instanceType string) string {
+		return `{"resource_changes": [{
+			"address": "` + address + `",
+			"change": {"after": {"instance_type": "` + instanceType + `", "tags": {"Name": "web"}}}
+		}]}`
+	}
+
+	result, err := ComparePlans(plan("aws_instance.legacy", "t3.micro"), plan("aws_instance.web", "t3.large"),
+		newOptions(WithResourceKeyFunc(tagNameKey)))
+	require.NoError(t, err)
+
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "~ instance_type: t3.micro => t3.large")
+	assert.NotContains(t, result.Text, "+ aws_instance.web")
+	assert.NotContains(t, result.Text, "- aws_instance.legacy")
+}
+
+func TestKeyResources(t *testing.T) {
+	resources := map[string]interface{}{
+		"aws_instance.a": map[string]interface{}{"values": map[string]interface{}{"tags": map[string]interface{}{"Name": "web"}}},
+		"aws_instance.b": map[string]interface{}{"values": map[string]interface{}{"tags": map[string]interface{}{"Name": "web"}}},
+		"aws_instance.c": map[string]interface{}{"values": map[string]interface{}{}},
+	}
+
+	keyed := keyResources(resources, tagNameKey)
+
+	tests := []struct {
+		name   string
+		key    string
+		source string
+	}{
+		{name: "first resource takes the key", key: "web", source: "aws_instance.a"},
+		{name: "colliding resource keeps its address", key: "aws_instance.b", source: "aws_instance.b"},
+		{name: "empty key falls back to address", key: "aws_instance.c", source: "aws_instance.c"},
+	}
+
+	require.Len(t, keyed, len(tests))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, resources[tc.source], keyed[tc.key])
+		})
+	}
+}
+
+func TestKeyResources_KeysCollidingWithAddresses(t *testing.T) {
+	resource := func(name string) map[string]interface{} {
+		return map[string]interface{}{"values": map[string]interface{}{"tags": map[string]interface{}{"Name": name}}}
+	}
+	resources := map[string]interface{}{
+		"aws_instance.a": resource("aws_instance.z"),
+		"aws_instance.b": resource("aws_instance.c"),
+		"aws_instance.c": resource("aws_instance.a"),
+		"aws_instance.d": resource("web"),
+		"aws_instance.e": resource("web"),
+		"aws_instance.z": resource(""),
+	}
+
+	keyed := keyResources(resources, tagNameKey)
+
+	assert.Equal(t, map[string]interface{}{
+		// aws_instance.z keeps its address, so aws_instance.a falls back to an address that is now a key
+		"aws_instance.a#2": resources["aws_instance.a"],
+		"aws_instance.c":   resources["aws_instance.b"],
+		"aws_instance.a":   resources["aws_instance.c"],
+		"web":              resources["aws_instance.d"],
+		"aws_instance.e":   resources["aws_instance.e"],
+		"aws_instance.z":   resources["aws_instance.z"],
+	}, keyed)
+}
@@ -0,0 +1,95 @@
+package comparison
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RevertRecord is a resource attribute that changed in the intermediate plan and is back to its
+// baseline value in the current plan.
+type RevertRecord struct {
+	Address      string
+	Path         string
+	Baseline     interface{}
+	Intermediate interface{}
+}
+
+// DetectReverts compares three plans and reports the resource attributes that went from their baseline
+// value to a different one in the intermediate plan and back to the baseline value in the current plan.
+// Only resources present in all three plans are considered. Records are sorted by address and path.
+func DetectReverts(baselineJSON, intermediateJSON, currentJSON string) ([]RevertRecord, error) {
+	plans := make([]map[string]interface{}, 0, 3)
+	for _, input := range []struct{ name, planJSON string }{
+		{"baseline", baselineJSON},
+		{"intermediate", intermediateJSON},
+		{"current", currentJSON},
+	} {
+		var plan map[string]interface{}
+		if err := json.Unmarshal([]byte(input.planJSON), &plan); err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s plan JSON", input.name)
+		}
+		plans = append(plans, plan)
+	}
+
+	baseline, intermediate, current := getResources(plans[0]), getResources(plans[1]), getResources(plans[2])
+	opts := &CompareOptions{}
+
+	addresses := make([]string, 0, len(baseline))
+	for address := range baseline {
+		_, inIntermediate := intermediate[address]
+		_, inCurrent := current[address]
+		if inIntermediate && inCurrent {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	reverts := make([]RevertRecord, 0)
+	for _, address := range addresses {
+		baselineAttrs := getResourceAttributes(baseline[address], opts)
+		intermediateAttrs := getResourceAttributes(intermediate[address], opts)
+		currentAttrs := getResourceAttributes(current[address], opts)
+
+		remaining, err := DeepDiff(baselineAttrs, currentAttrs)
+		if err != nil {
+			return nil, err
+		}
+		intermediateChanges, err := DeepDiff(baselineAttrs, intermediateAttrs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range intermediateChanges {
+			if pathTouched(change.Path, remaining) {
+				continue
+			}
+
+			reverts = append(reverts, RevertRecord{
+				Address:      address,
+				Path:         change.Path,
+				Baseline:     change.Old,
+				Intermediate: change.New,
+			})
+		}
+	}
+
+	return reverts, nil
+}
+
+// pathTouched reports whether any change is at path, inside it or encloses it.
+func pathTouched(path string, changes []Change) bool {
+	for _, change := range changes {
+		if change.Path == path || isChildPath(change.Path, path) || isChildPath(path, change.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChildPath reports whether path is nested below parent, e.g. "tags.Name" below "tags".
+func isChildPath(path, parent string) bool {
+	return parent == "" || strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[")
+}
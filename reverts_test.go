@@ -0,0 +1,55 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectReverts(t *testing.T) {
+	plan := func(instanceType, ami string) string {
+		return `{"resource_changes": [{
+			"address": "aws_instance.web",
+			"change": {"after": {"instance_type": "` + instanceType + `", "ami": "` + ami + `", "tags": {"Name": "web"}}}
+		}]}`
+	}
+
+	// instance_type reverts to its baseline value, ami moves on to a third value
+	reverts, err := DetectReverts(plan("t3.micro", "ami-1"), plan("t3.large", "ami-2"), plan("t3.micro", "ami-3"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []RevertRecord{{
+		Address:      "aws_instance.web",
+		Path:         "instance_type",
+		Baseline:     "t3.micro",
+		Intermediate: "t3.large",
+	}}, reverts)
+}
+
+func TestDetectReverts_InvalidJSON(t *testing.T) {
+	_, err := DetectReverts("{}", "not json", "{}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing intermediate plan JSON")
+}
+
+func TestPathTouched(t *testing.T) {
+	changes := []Change{{Path: "tags.Name"}, {Path: "ingress[0].from_port"}}
+
+	tests := []struct {
+		path    string
+		touched bool
+	}{
+		{path: "tags.Name", touched: true},
+		{path: "tags", touched: true},
+		{path: "ingress", touched: true},
+		{path: "tags.Env", touched: false},
+		{path: "tag", touched: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.touched, pathTouched(tc.path, changes))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultReviewCommentMaxLength is GitHub's limit on the size of a pull request comment.
+const defaultReviewCommentMaxLength = 65536
+
+// defaultHighRiskChangeCount is the number of changes at which a diff is rated high risk.
+const defaultHighRiskChangeCount = 20
+
+// Risk levels of a review comment.
+const (
+	riskLow    = "low"
+	riskMedium = "medium"
+	riskHigh   = "high"
+)
+
+// reviewTruncatedNote replaces the detailed diff lines that did not fit in a review comment.
+const reviewTruncatedNote = "... %d more lines truncated\n"
+
+// ReviewCommentOptions configures FormatReviewComment.
+type ReviewCommentOptions struct {
+	// MaxLength caps the size of the comment in bytes. Detailed diff lines that do not fit are
+	// truncated. Zero uses GitHub's comment limit.
+	MaxLength int
+
+	// HighRiskChangeCount is the number of changes at which a diff without destructive changes or
+	// sensitivity regressions is rated high risk instead of medium. Zero uses 20.
+	HighRiskChangeCount int
+}
+
+// reviewCounts tallies the entries of a diff map.
+type reviewCounts struct {
+	added, removed, changed int
+}
+
+// FormatReviewComment renders a diff map as a single Markdown comment for a pull request: a headline
+// with the change counts and a risk level, callouts for destructive resource changes (removals and
+// mode changes) and sensitivity regressions (values that are no longer sensitive), and the detailed
+// diff in a collapsible block. Destructive changes and sensitivity regressions make the risk high;
+// other diffs are medium risk, or high from HighRiskChangeCount changes on. A nil opts uses the defaults.
+func FormatReviewComment(diffMap map[string]interface{}, opts *ReviewCommentOptions) string {
+	maxLength, highRiskCount := defaultReviewCommentMaxLength, defaultHighRiskChangeCount
+	if opts != nil && opts.MaxLength > 0 {
+		maxLength = opts.MaxLength
+	}
+	if opts != nil && opts.HighRiskChangeCount > 0 {
+		highRiskCount = opts.HighRiskChangeCount
+	}
+
+	counts := countReviewChanges(diffMap)
+	destructive := destructiveChanges(diffMap)
+	regressions := sensitivityRegressions(diffMap)
+
+	var head strings.Builder
+	head.WriteString(fmt.Sprintf("### Plan comparison: %d added, %d removed, %d changed (risk: %s)\n",
+		counts.added, counts.removed, counts.changed, reviewRisk(counts, destructive, regressions, highRiskCount)))
+	writeCallout(&head, "Destructive changes", destructive)
+	writeCallout(&head, "Sensitivity regressions", regressions)
+
+	lines := reviewDiffLines(diffMap)
+	fence := codeFence(strings.Join(lines, ""))
+	detailsOpen := "\n<details>\n<summary>Detailed diff</summary>\n\n" + fence + "diff\n"
+	detailsClose := fence + "\n\n</details>\n"
+
+	budget := maxLength - head.Len() - len(detailsOpen) - len(detailsClose)
+	if len(lines) == 0 || budget < len(fmt.Sprintf(reviewTruncatedNote, len(lines))) {
+		return truncateString(head.String(), maxLength)
+	}
+
+	var details strings.Builder
+	for i, line := range lines {
+		remaining := len(lines) - i
+		note := ""
+		if remaining > 1 {
+			note = fmt.Sprintf(reviewTruncatedNote, remaining-1)
+		}
+		if details.Len()+len(line)+len(note) > budget {
+			details.WriteString(fmt.Sprintf(reviewTruncatedNote, remaining))
+			break
+		}
+		details.WriteString(line)
+	}
+
+	return head.String() + detailsOpen + details.String() + detailsClose
+}
+
+// countReviewChanges counts the added, removed and changed entries of every section. Moved, mode-changed
+// and count-changed resources and tag-only changes count as changed.
+func countReviewChanges(diffMap map[string]interface{}) reviewCounts {
+	var counts reviewCounts
+	summary := SummarizeDiff(diffMap)
+	for _, section := range []SectionCounts{summary.Variables, summary.Resources, summary.Tags, summary.Drift, summary.Outputs} {
+		counts.added += section.Added
+		counts.removed += section.Removed
+		counts.changed += section.Changed
+	}
+	counts.changed += len(entryList(diffMap["provider_upgrades"]))
+	return counts
+}
+
+// destructiveChanges describes the removed and mode-changed resources of a diff map.
+func destructiveChanges(diffMap map[string]interface{}) []string {
+	resources, _ := diffMap["resources"].(map[string]interface{})
+
+	result := make([]string, 0)
+	for _, entry := range entryList(resources["removed"]) {
+		result = append(result, fmt.Sprintf("`%s` is destroyed", entryKey(entry)))
+	}
+	for _, entry := range entryList(resources["mode_changed"]) {
+		result = append(result, fmt.Sprintf("`%s` changes mode from %v to %v", entryKey(entry), entry["old_mode"], entry["new_mode"]))
+	}
+	return result
+}
+
+// sensitivityRegressions describes the changed resource attributes and outputs whose value was sensitive
+// and no longer is. Resource attributes are recognized by their redaction text, outputs by their recorded
+// sensitivity.
+func sensitivityRegressions(diffMap map[string]interface{}) []string {
+	result := make([]string, 0)
+
+	resources, _ := diffMap["resources"].(map[string]interface{})
+	for _, entry := range entryList(resources["changed"]) {
+		attrs, _ := entry["attributes"].(map[string]interface{})
+		for _, attr := range entryList(attrs["changed"]) {
+			if isRedacted(attr["old"]) && !isRedacted(attr["new"]) {
+				result = append(result, fmt.Sprintf("`%s.%s` is no longer sensitive", entryKey(entry), entryKey(attr)))
+			}
+		}
+	}
+
+	outputs, _ := diffMap["outputs"].(map[string]interface{})
+	for _, entry := range entryList(outputs["changed"]) {
+		if isSensitive(entry["old"]) && !isSensitive(entry["new"]) {
+			result = append(result, fmt.Sprintf("`output.%s` is no longer sensitive", entryKey(entry)))
+		}
+	}
+
+	return result
+}
+
+// reviewRisk rates the risk of a diff.
+func reviewRisk(counts reviewCounts, destructive, regressions []string, highRiskCount int) string {
+	total := counts.added + counts.removed + counts.changed
+	switch {
+	case len(destructive) > 0 || len(regressions) > 0 || total >= highRiskCount:
+		return riskHigh
+	case total > 0:
+		return riskMedium
+	default:
+		return riskLow
+	}
+}
+
+// writeCallout writes a titled Markdown list of items, if there are any.
+func writeCallout(sb *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\n**%s:**\n", title))
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+}
+
+// reviewDiffLines renders the changes of a diff map as diff lines, one per entry and changed attribute.
+func reviewDiffLines(diffMap map[string]interface{}) []string {
+	opts := resolveOptions(nil)
+	lines := make([]string, 0)
+
+	for _, section := range []struct{ name, prefix string }{
+		{"variables", "var."}, {"resources", ""}, {"tag_changes", ""}, {"drift", "drift: "}, {"outputs", "output."},
+	} {
+		categories, ok := diffMap[section.name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, entry := range entryList(categories["added"]) {
+			lines = append(lines, fmt.Sprintf("+ %s%s\n", section.prefix, entryKey(entry)))
+		}
+		for _, entry := range entryList(categories["removed"]) {
+			lines = append(lines, fmt.Sprintf("- %s%s\n", section.prefix, entryKey(entry)))
+		}
+		for _, kind := range []string{"moved", "mode_changed"} {
+			for _, entry := range entryList(categories[kind]) {
+				lines = append(lines, fmt.Sprintf("! %s%s\n", section.prefix, entryKey(entry)))
+			}
+		}
+		for _, entry := range entryList(categories["changed"]) {
+			attrs, ok := entry["attributes"].(map[string]interface{})
+			if !ok {
+				lines = append(lines, fmt.Sprintf("~ %s%s\n", section.prefix, formatChange(entryKey(entry),
+					formatValue(entry["old"], opts), formatValue(entry["new"], opts), opts)))
+				continue
+			}
+
+			lines = append(lines, fmt.Sprintf("~ %s%s\n", section.prefix, entryKey(entry)))
+			for _, attr := range entryList(attrs["added"]) {
+				lines = append(lines, fmt.Sprintf("  + %s: %s\n", entryKey(attr), formatValue(attr["value"], opts)))
+			}
+			for _, attr := range entryList(attrs["removed"]) {
+				lines = append(lines, fmt.Sprintf("  - %s: %s\n", entryKey(attr), formatValue(attr["value"], opts)))
+			}
+			for _, attr := range entryList(attrs["changed"]) {
+				lines = append(lines, fmt.Sprintf("  ~ %s\n", formatChange(entryKey(attr),
+					formatValue(attr["old"], opts), formatValue(attr["new"], opts), opts)))
+			}
+		}
+	}
+
+	for _, entry := range entryList(diffMap["provider_upgrades"]) {
+		lines = append(lines, fmt.Sprintf("~ provider %s\n", entryKey(entry)))
+	}
+
+	return lines
+}
+
+// truncateString cuts s to at most maxLength bytes without splitting a UTF-8 sequence.
+func truncateString(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
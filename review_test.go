@@ -0,0 +1,186 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatReviewComment_Headline(t *testing.T) {
+	tests := []struct {
+		name        string
+		diffMap     map[string]interface{}
+		opts        *ReviewCommentOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "destroyed resource is high risk",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"added":   []map[string]interface{}{{"address": "aws_s3_bucket.new"}},
+					"removed": []map[string]interface{}{{"address": "aws_db_instance.main"}},
+				},
+			},
+			contains: []string{
+				"### Plan comparison: 1 added, 1 removed, 0 changed (risk: high)\n",
+				"**Destructive changes:**\n- `aws_db_instance.main` is destroyed\n",
+				"- aws_db_instance.main\n",
+				"+ aws_s3_bucket.new\n",
+			},
+			notContains: []string{"Sensitivity regressions"},
+		},
+		{
+			name: "mode change is destructive",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"mode_changed": []map[string]interface{}{{"address": "aws_s3_bucket.logs", "old_mode": "managed", "new_mode": "data"}},
+				},
+			},
+			contains: []string{"(risk: high)", "- `aws_s3_bucket.logs` changes mode from managed to data\n"},
+		},
+		{
+			name: "attribute changes are medium risk",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"changed": []map[string]interface{}{{
+						"address": "aws_instance.web",
+						"attributes": map[string]interface{}{
+							"changed": []map[string]interface{}{{"name": "instance_type", "old": "t3.micro", "new": "t3.large"}},
+						},
+					}},
+				},
+			},
+			contains:    []string{"(risk: medium)", "~ aws_instance.web\n  ~ instance_type: t3.micro => t3.large\n"},
+			notContains: []string{"Destructive changes", "Sensitivity regressions"},
+		},
+		{
+			name: "tag-only changes are listed",
+			diffMap: map[string]interface{}{
+				"tag_changes": map[string]interface{}{
+					"changed": []map[string]interface{}{{
+						"address": "aws_s3_bucket.logs",
+						"attributes": map[string]interface{}{
+							"changed": []map[string]interface{}{{"name": "tags.Owner", "old": "a", "new": "b"}},
+						},
+					}},
+				},
+			},
+			contains: []string{"0 added, 0 removed, 1 changed (risk: medium)", "~ aws_s3_bucket.logs\n  ~ tags.Owner: a => b\n"},
+		},
+		{
+			name: "count changes are counted",
+			diffMap: map[string]interface{}{
+				"resources": map[string]interface{}{
+					"count_changed": []map[string]interface{}{{"address": "aws_instance.web", "old_count": 2, "new_count": 3}},
+				},
+			},
+			contains: []string{"0 added, 0 removed, 1 changed (risk: medium)"},
+		},
+		{
+			name: "values with backticks cannot close the fence",
+			diffMap: map[string]interface{}{
+				"variables": map[string]interface{}{
+					"changed": []map[string]interface{}{{"name": "script", "old": "a", "new": "b\n```\n# injected"}},
+				},
+			},
+			contains: []string{"\n````diff\n~ var.script: a => b\n```\n# injected\n````\n\n</details>\n"},
+		},
+		{
+			name: "many changes reach the high risk threshold",
+			diffMap: map[string]interface{}{
+				"variables": map[string]interface{}{
+					"added": []map[string]interface{}{{"name": "a", "value": 1}, {"name": "b", "value": 2}},
+				},
+			},
+			opts:     &ReviewCommentOptions{HighRiskChangeCount: 2},
+			contains: []string{"2 added, 0 removed, 0 changed (risk: high)", "+ var.a\n"},
+		},
+		{
+			name:        "no changes",
+			diffMap:     map[string]interface{}{},
+			contains:    []string{"0 added, 0 removed, 0 changed (risk: low)"},
+			notContains: []string{"<details>"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			comment := FormatReviewComment(tc.diffMap, tc.opts)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, comment, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, comment, notExpected)
+			}
+		})
+	}
+}
+
+func TestFormatReviewComment_SensitivityRegressions(t *testing.T) {
+	origJSON := `{
+		"resource_changes": [
+			{"address": "aws_db_instance.main", "change": {"after": {"password": "hunter2", "port": 5432}, "after_sensitive": {"password": true}}},
+			{"address": "aws_iam_user.ci", "change": {"after": {"key": "k1"}, "after_sensitive": {"key": true}}}
+		],
+		"planned_values": {"outputs": {"password": {"value": "hunter2", "sensitive": true}}}
+	}`
+	newJSON := `{
+		"resource_changes": [
+			{"address": "aws_db_instance.main", "change": {"after": {"password": "hunter2", "port": 5432}}},
+			{"address": "aws_iam_user.ci", "change": {"after": {"key": "k2"}, "after_sensitive": {"key": true}}}
+		],
+		"planned_values": {"outputs": {"password": {"value": "hunter2", "sensitive": false}}}
+	}`
+
+	for _, hashed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hashed %v", hashed), func(t *testing.T) {
+			result, err := ComparePlans(origJSON, newJSON, &CompareOptions{HashSensitiveValues: hashed})
+			require.NoError(t, err)
+
+			comment := FormatReviewComment(result.Changes, nil)
+			assert.Contains(t, comment, "(risk: high)")
+			assert.Contains(t, comment, "**Sensitivity regressions:**\n"+
+				"- `aws_db_instance.main.password` is no longer sensitive\n"+
+				"- `output.password` is no longer sensitive\n")
+			assert.NotContains(t, comment, "aws_iam_user.ci.key` is no longer sensitive")
+			assert.NotContains(t, comment, "Destructive changes")
+		})
+	}
+}
+
+func TestFormatReviewComment_SizeCap(t *testing.T) {
+	removed := make([]map[string]interface{}, 0, 500)
+	for i := 0; i < 500; i++ {
+		removed = append(removed, map[string]interface{}{"address": fmt.Sprintf("aws_instance.web[%d]", i)})
+	}
+	diffMap := map[string]interface{}{"resources": map[string]interface{}{"removed": removed}}
+
+	tests := []struct {
+		name      string
+		maxLength int
+		detailed  bool
+	}{
+		{name: "detailed diff is truncated", maxLength: 30000, detailed: true},
+		{name: "callouts alone exceed the cap", maxLength: 1000},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			comment := FormatReviewComment(diffMap, &ReviewCommentOptions{MaxLength: tc.maxLength})
+
+			assert.LessOrEqual(t, len(comment), tc.maxLength)
+			assert.True(t, strings.HasPrefix(comment, "### Plan comparison: 0 added, 500 removed, 0 changed (risk: high)\n"))
+			if tc.detailed {
+				require.Contains(t, comment, "more lines truncated\n")
+				assert.True(t, strings.HasSuffix(comment, "```\n\n</details>\n"))
+			}
+		})
+	}
+
+	assert.NotContains(t, FormatReviewComment(diffMap, nil), "truncated")
+}
@@ -0,0 +1,163 @@
+package comparison
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// instanceKeyPattern matches the count/for_each instance keys in a resource address.
+var instanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// planConfiguration is the subset of a plan's configuration block needed to recover attribute order.
+type planConfiguration struct {
+	Configuration struct {
+		RootModule configModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+// configModule is a module in the plan's configuration block.
+type configModule struct {
+	Resources   []configResource `json:"resources"`
+	ModuleCalls map[string]struct {
+		Module configModule `json:"module"`
+	} `json:"module_calls"`
+}
+
+// configResource is a resource in the plan's configuration block.
+type configResource struct {
+	Address     string      `json:"address"`
+	Expressions orderedKeys `json:"expressions"`
+}
+
+// orderedKeys holds the keys of a JSON object in the order they were declared.
+type orderedKeys []string
+
+// UnmarshalJSON records the object's keys in document order and discards the values.
+func (k *orderedKeys) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected JSON object, got %v", token)
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", token)
+		}
+		*k = append(*k, key)
+
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadSchemaOrder returns the declared attribute order per configuration address from the given plans.
+// Later plans take precedence. Plans without a readable configuration block are ignored.
+func loadSchemaOrder(planJSONs ...[]byte) map[string][]string {
+	result := make(map[string][]string)
+
+	for _, planJSON := range planJSONs {
+		var plan planConfiguration
+		if err := json.Unmarshal(planJSON, &plan); err != nil {
+			continue
+		}
+		collectModuleOrder(plan.Configuration.RootModule, "", result)
+	}
+
+	return result
+}
+
+// collectModuleOrder records the attribute order of a module's resources and recurses into its module calls.
+func collectModuleOrder(module configModule, prefix string, result map[string][]string) {
+	for _, res := range module.Resources {
+		if len(res.Expressions) > 0 {
+			result[prefix+res.Address] = res.Expressions
+		}
+	}
+
+	for name, call := range module.ModuleCalls {
+		collectModuleOrder(call.Module, prefix+"module."+name+".", result)
+	}
+}
+
+// configAddress strips instance keys from a resource address so it matches the configuration block.
+func configAddress(address string) string {
+	return instanceKeyPattern.ReplaceAllString(address, "")
+}
+
+// attributeOrder returns the declared attribute order for a resource when UseSchemaOrder is set, or nil otherwise.
+// Resources without schema information get an empty order, which sorts attributes alphabetically.
+func (o *CompareOptions) attributeOrder(address string) []string {
+	if !o.UseSchemaOrder {
+		return nil
+	}
+
+	if order, ok := o.schemaOrder[configAddress(address)]; ok {
+		return order
+	}
+
+	return []string{}
+}
+
+// processOrderedAttributes handles attribute changes following the given declaration order.
+// Attributes missing from the order come last, alphabetically.
+func processOrderedAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, order []string, skipAttrs map[string]bool, added, removed, changed *[]map[string]interface{}, opts *CompareOptions) {
+	rank := make(map[string]int, len(order))
+	for i, attrK := range order {
+		rank[attrK] = i
+	}
+
+	keys := getSortedKeys(origAttrs, newAttrs)
+	sort.SliceStable(keys, func(i, j int) bool {
+		rankI, okI := rank[keys[i]]
+		rankJ, okJ := rank[keys[j]]
+		if okI && okJ {
+			return rankI < rankJ
+		}
+		return okI && !okJ
+	})
+
+	for _, attrK := range keys {
+		if skipAttrs[attrK] {
+			continue
+		}
+
+		origAttrV, origExists := origAttrs[attrK]
+		newAttrV, newExists := newAttrs[attrK]
+
+		switch {
+		case origExists && newExists && !valuesEqual(origAttrV, newAttrV, opts):
+			*changed = append(*changed, processAttributeChange(diff, attrK, attrK, origAttrV, newAttrV, 0, opts))
+		case origExists && !newExists:
+			diff.WriteString(fmt.Sprintf("  - %s: %v\n", attrK, formatValue(origAttrV, opts)))
+			*removed = append(*removed, map[string]interface{}{
+				"name":  attrK,
+				"value": origAttrV,
+			})
+		case !origExists && newExists:
+			diff.WriteString(fmt.Sprintf("  + %s: %v\n", attrK, formatValue(newAttrV, opts)))
+			*added = append(*added, map[string]interface{}{
+				"name":  attrK,
+				"value": newAttrV,
+			})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaOrderPlan(instanceType, ami, name string) string {
+	return `{
+		"resource_changes": [{
+			"address": "module.app.aws_instance.web[0]",
+			"change": {"after": {"instance_type": "` + instanceType + `", "ami": "` + ami + `", "name": "` + name + `"}}
+		}],
+		"configuration": {"root_module": {"module_calls": {"app": {"module": {"resources": [{
+			"address": "aws_instance.web",
+			"expressions": {
+				"name": {"constant_value": "x"},
+				"instance_type": {"constant_value": "x"},
+				"ami": {"constant_value": "x"}
+			}
+		}]}}}}}
+	}`
+}
+
+func TestUseSchemaOrder_FollowsConfiguration(t *testing.T) {
+	orig := schemaOrderPlan("t3.micro", "ami-1", "old")
+	updated := schemaOrderPlan("t3.large", "ami-2", "new")
+
+	diff, _, hasDiff, err := ComparePlansAndGenerateDiffWithOptions(orig, updated, &CompareOptions{UseSchemaOrder: true})
+	require.NoError(t, err)
+	require.True(t, hasDiff)
+
+	nameIdx := strings.Index(diff, "~ name:")
+	typeIdx := strings.Index(diff, "~ instance_type:")
+	amiIdx := strings.Index(diff, "~ ami:")
+	require.NotEqual(t, -1, nameIdx)
+	assert.Less(t, nameIdx, typeIdx)
+	assert.Less(t, typeIdx, amiIdx)
+}
+
+func TestUseSchemaOrder_FallsBackToAlphabetical(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"zone": "a", "ami": "ami-1", "id": "i-1"},
+		},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.web": map[string]interface{}{
+			"values": map[string]interface{}{"zone": "b", "ami": "ami-2", "id": "i-2"},
+		},
+	}
+
+	diff, _ := compareResources(origRes, newRes, &CompareOptions{UseSchemaOrder: true})
+
+	amiIdx := strings.Index(diff, "~ ami:")
+	idIdx := strings.Index(diff, "~ id:")
+	zoneIdx := strings.Index(diff, "~ zone:")
+	assert.Less(t, amiIdx, idIdx)
+	assert.Less(t, idIdx, zoneIdx)
+}
+
+func TestLoadSchemaOrder(t *testing.T) {
+	order := loadSchemaOrder([]byte(schemaOrderPlan("a", "b", "c")))
+	assert.Equal(t, map[string][]string{
+		"module.app.aws_instance.web": {"name", "instance_type", "ami"},
+	}, order)
+	assert.Equal(t, "module.app.aws_instance.web", configAddress(`module.app.aws_instance.web["x"]`))
+}
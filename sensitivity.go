@@ -0,0 +1,237 @@
+package comparison
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompareSensitivitySets reports which resource attributes and outputs became sensitive (added) or stopped
+// being sensitive (removed) between two plans. Entries are `<address>.<attribute path>` for resources and
+// `output.<name>` for outputs, sorted. Only the after_sensitive and sensitive_values metadata is read,
+// never the values themselves.
+func CompareSensitivitySets(origPlanJSON, newPlanJSON string) (added, removed []string, err error) {
+	var origPlan, newPlan map[string]interface{}
+	if err := json.Unmarshal([]byte(origPlanJSON), &origPlan); err != nil {
+		return nil, nil, errors.Wrap(err, "error parsing original plan JSON")
+	}
+	if err := json.Unmarshal([]byte(newPlanJSON), &newPlan); err != nil {
+		return nil, nil, errors.Wrap(err, "error parsing new plan JSON")
+	}
+
+	origSet, newSet := sensitivitySet(origPlan), sensitivitySet(newPlan)
+	return setDifference(newSet, origSet), setDifference(origSet, newSet), nil
+}
+
+// sensitivitySet collects the sensitive attribute paths of a plan's resources and outputs.
+func sensitivitySet(plan map[string]interface{}) map[string]bool {
+	result := make(map[string]bool)
+
+	for address, resource := range getResources(plan) {
+		resMap, ok := resource.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		collectSensitivePaths(resMap["sensitive_values"], address, result)
+		if change, ok := resMap["change"].(map[string]interface{}); ok {
+			collectSensitivePaths(change["after_sensitive"], address, result)
+		}
+	}
+
+	if outputChanges, ok := plan["output_changes"].(map[string]interface{}); ok {
+		for name, change := range outputChanges {
+			if changeMap, ok := change.(map[string]interface{}); ok {
+				collectSensitivePaths(changeMap["after_sensitive"], "output."+name, result)
+			}
+		}
+	}
+
+	return result
+}
+
+// collectSensitivePaths records every path in a sensitivity mask whose value is true.
+func collectSensitivePaths(mask interface{}, path string, result map[string]bool) {
+	switch typed := mask.(type) {
+	case bool:
+		if typed {
+			result[path] = true
+		}
+	case map[string]interface{}:
+		for k, v := range typed {
+			collectSensitivePaths(v, joinPath(path, k), result)
+		}
+	case []interface{}:
+		for i, v := range typed {
+			collectSensitivePaths(v, fmt.Sprintf("%s[%d]", path, i), result)
+		}
+	}
+}
+
+// setDifference returns the sorted members of a that are not in b.
+func setDifference(a, b map[string]bool) []string {
+	result := make([]string, 0)
+	for k := range a {
+		if !b[k] {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// redactedValue replaces a sensitive value, with a short hash of it when HashSensitiveValues is set.
+func redactedValue(value interface{}, opts *CompareOptions) string {
+	if !opts.HashSensitiveValues {
+		return sensitiveValueText
+	}
+
+	encoded, _ := json.Marshal(value)
+	hash := sha256.Sum256(encoded)
+	return fmt.Sprintf("(sensitive value %s)", hex.EncodeToString(hash[:])[:sensitiveHashLength])
+}
+
+// isRedacted reports whether a diff map value is the redaction text of a sensitive value, with or without a
+// hash.
+func isRedacted(value interface{}) bool {
+	text, ok := value.(string)
+	return ok && (text == sensitiveValueText || strings.HasPrefix(text, "(sensitive value ") && strings.HasSuffix(text, ")"))
+}
+
+// sensitiveStandIn takes the place of a sensitive value while attributes and variables are compared. It
+// renders as the redaction text, but compares by a digest of the raw value, so a changed secret is still
+// reported as a change. resolveSensitiveStandIns replaces stand-ins by their text in the diff map.
+type sensitiveStandIn struct {
+	text   string
+	digest [sha256.Size]byte
+}
+
+// String returns the redaction text, so stand-ins render like redacted values in the text diff.
+func (s sensitiveStandIn) String() string {
+	return s.text
+}
+
+// standInValue returns the stand-in of a sensitive value.
+func standInValue(value interface{}, opts *CompareOptions) interface{} {
+	encoded, _ := json.Marshal(value)
+	return sensitiveStandIn{text: redactedValue(value, opts), digest: sha256.Sum256(encoded)}
+}
+
+// resolveSensitiveStandIns replaces every stand-in in a diff map by its redaction text, recursing into
+// nested maps and lists. Stand-ins only occur in values built for the comparison, which are rewritten in place.
+func resolveSensitiveStandIns(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case sensitiveStandIn:
+		return typed.text
+	case map[string]interface{}:
+		for k, nested := range typed {
+			typed[k] = resolveSensitiveStandIns(nested)
+		}
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = resolveSensitiveStandIns(nested)
+		}
+	case []map[string]interface{}:
+		for _, nested := range typed {
+			resolveSensitiveStandIns(nested)
+		}
+	}
+	return v
+}
+
+// redactSensitive returns value with every part marked in a sensitivity mask replaced. The mask mirrors
+// the value: true marks a whole value, and nested maps and lists mark their elements.
+func redactSensitive(value, mask interface{}, opts *CompareOptions) interface{} {
+	return replaceSensitive(value, mask, func(v interface{}) interface{} { return redactedValue(v, opts) })
+}
+
+// replaceSensitive returns value with every part marked in a sensitivity mask replaced by the result of
+// replace.
+func replaceSensitive(value, mask interface{}, replace func(interface{}) interface{}) interface{} {
+	switch typed := mask.(type) {
+	case bool:
+		if typed && value != nil {
+			return replace(value)
+		}
+	case map[string]interface{}:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+
+		result := make(map[string]interface{}, len(valueMap))
+		for k, v := range valueMap {
+			result[k] = replaceSensitive(v, typed[k], replace)
+		}
+		return result
+	case []interface{}:
+		valueList, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+
+		result := make([]interface{}, len(valueList))
+		for i, v := range valueList {
+			if i < len(typed) {
+				v = replaceSensitive(v, typed[i], replace)
+			}
+			result[i] = v
+		}
+		return result
+	}
+
+	return value
+}
+
+// hasSensitiveMarks reports whether a resource's sensitivity masks mark any of its values as sensitive.
+func hasSensitiveMarks(resource interface{}) bool {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	marked := make(map[string]bool)
+	collectSensitivePaths(resMap["sensitive_values"], "", marked)
+	if change, ok := resMap["change"].(map[string]interface{}); ok {
+		collectSensitivePaths(change["after_sensitive"], "", marked)
+	}
+	return len(marked) > 0
+}
+
+// replaceSensitiveAttributes replaces the sensitive parts of the attributes extracted from a resource
+// using its sensitive_values and change.after_sensitive masks.
+func replaceSensitiveAttributes(resMap, attrs map[string]interface{}, replace func(interface{}) interface{}) map[string]interface{} {
+	attrs = replaceSensitive(attrs, resMap["sensitive_values"], replace).(map[string]interface{})
+	if change, ok := resMap["change"].(map[string]interface{}); ok {
+		attrs = replaceSensitive(attrs, change["after_sensitive"], replace).(map[string]interface{})
+	}
+	return attrs
+}
+
+// redactResource returns a copy of a resource whose values and change.after have their sensitive
+// parts redacted, so whole resources can be placed in the diff map.
+func redactResource(resource interface{}, opts *CompareOptions) interface{} {
+	resMap, ok := resource.(map[string]interface{})
+	if !ok {
+		return resource
+	}
+
+	result := copyEntry(resMap)
+	if values, ok := resMap["values"]; ok {
+		result["values"] = redactSensitive(values, resMap["sensitive_values"], opts)
+	}
+	if change, ok := resMap["change"].(map[string]interface{}); ok {
+		change = copyEntry(change)
+		change["after"] = redactSensitive(change["after"], change["after_sensitive"], opts)
+		if _, ok := change["before"]; ok {
+			change["before"] = redactSensitive(change["before"], change["before_sensitive"], opts)
+		}
+		result["change"] = change
+	}
+	return result
+}
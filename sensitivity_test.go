@@ -0,0 +1,164 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSensitivitySets(t *testing.T) {
+	orig := `{
+		"resource_changes": [{
+			"address": "aws_db_instance.db",
+			"change": {
+				"after": {"password": "hunter2", "username": "admin", "tags": {"Owner": "ops"}},
+				"after_sensitive": {"password": true, "tags": {}}
+			}
+		}],
+		"output_changes": {"conn": {"after": "x", "after_sensitive": true}}
+	}`
+	updated := `{
+		"resource_changes": [{
+			"address": "aws_db_instance.db",
+			"change": {
+				"after": {"password": "hunter2", "username": "root-secret", "tags": {"Owner": "ops"}},
+				"after_sensitive": {"password": true, "username": true, "tags": {"Owner": true}}
+			}
+		}],
+		"output_changes": {"conn": {"after": "x", "after_sensitive": false}}
+	}`
+
+	added, removed, err := CompareSensitivitySets(orig, updated)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"aws_db_instance.db.tags.Owner", "aws_db_instance.db.username"}, added)
+	assert.Equal(t, []string{"output.conn"}, removed)
+
+	// Values are never part of the result
+	for _, entry := range append(added, removed...) {
+		assert.NotContains(t, entry, "root-secret")
+		assert.NotContains(t, entry, "hunter2")
+	}
+}
+
+func TestCompareSensitivitySets_InvalidJSON(t *testing.T) {
+	_, _, err := CompareSensitivitySets("not json", "{}")
+	require.Error(t, err)
+}
+
+func TestCollectSensitivePaths(t *testing.T) {
+	result := make(map[string]bool)
+	collectSensitivePaths(map[string]interface{}{
+		"a":    true,
+		"b":    false,
+		"list": []interface{}{false, map[string]interface{}{"c": true}},
+	}, "res", result)
+
+	assert.Equal(t, map[string]bool{"res.a": true, "res.list[1].c": true}, result)
+}
+
+func TestRedactSensitive(t *testing.T) {
+	value := map[string]interface{}{
+		"username": "admin",
+		"password": "hunter2",
+		"settings": map[string]interface{}{"token": "abc", "region": "eu-west-1"},
+		"keys":     []interface{}{"public", "private"},
+		"empty":    nil,
+	}
+
+	tests := []struct {
+		name     string
+		mask     interface{}
+		expected interface{}
+	}{
+		{name: "no mask", mask: nil, expected: value},
+		{name: "whole value", mask: true, expected: sensitiveValueText},
+		{
+			name: "nested map and list markers",
+			mask: map[string]interface{}{
+				"password": true,
+				"settings": map[string]interface{}{"token": true},
+				"keys":     []interface{}{false, true},
+				"empty":    true,
+			},
+			expected: map[string]interface{}{
+				"username": "admin",
+				"password": sensitiveValueText,
+				"settings": map[string]interface{}{"token": sensitiveValueText, "region": "eu-west-1"},
+				"keys":     []interface{}{"public", sensitiveValueText},
+				"empty":    nil,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, redactSensitive(value, tc.mask, &CompareOptions{}))
+		})
+	}
+
+	assert.Equal(t, "hunter2", value["password"], "the value must not be modified")
+}
+
+func TestComparePlans_RedactsSensitiveValues(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_db_instance.main", "change": {
+			"after": {"password": "hunter2", "instance_class": "db.t3.micro", "settings": {"token": "tok-1"}},
+			"after_sensitive": {"password": true, "settings": {"token": true}}
+		}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_db_instance.main", "change": {
+			"after": {"password": "swordfish", "instance_class": "db.t3.micro", "settings": {"token": "tok-2"}},
+			"after_sensitive": {"password": true, "settings": {"token": true}}
+		}},
+		{"address": "aws_ssm_parameter.api_key", "change": {
+			"after": {"name": "api_key", "value": "sk-live-123"},
+			"after_sensitive": {"value": true}
+		}}
+	]}`
+	secrets := []string{"hunter2", "swordfish", "tok-1", "tok-2", "sk-live-123"}
+
+	tests := []struct {
+		name     string
+		opts     *CompareOptions
+		contains []string
+		pattern  string
+	}{
+		{
+			name: "sensitive changes are redacted",
+			opts: &CompareOptions{},
+			contains: []string{
+				"+ aws_ssm_parameter.api_key",
+				"aws_db_instance.main\n  ~ password: (sensitive value) => (sensitive value)\n  ~ settings.token: (sensitive value) => (sensitive value)\n",
+			},
+		},
+		{
+			name:     "hashes reveal that sensitive values changed",
+			opts:     &CompareOptions{HashSensitiveValues: true},
+			contains: []string{"aws_db_instance.main\n", "~ settings.token: (sensitive value "},
+			pattern:  `~ password: \(sensitive value [0-9a-f]{12}\) => \(sensitive value [0-9a-f]{12}\)`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(origJSON, newJSON, tc.opts)
+			require.NoError(t, err)
+			encoded, err := MarshalDiffJSON(result.Changes)
+			require.NoError(t, err)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, result.Text, expected)
+			}
+			if tc.pattern != "" {
+				assert.Regexp(t, tc.pattern, result.Text)
+			}
+			for _, secret := range secrets {
+				assert.NotContains(t, result.Text, secret)
+				assert.NotContains(t, string(encoded), secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package comparison
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// skipPatternPrefix marks a SkipAttributes entry as a regular expression.
+const skipPatternPrefix = "re:"
+
+// Validate reports options that cannot be used, such as SkipAttributes patterns that do not compile.
+func (o *CompareOptions) Validate() error {
+	_, err := compileSkipPatterns(o.SkipAttributes)
+	return err
+}
+
+// compile validates the options and prepares the compiled state the comparison needs.
+func (o *CompareOptions) compile() error {
+	patterns, err := compileSkipPatterns(o.SkipAttributes)
+	if err != nil {
+		return err
+	}
+
+	o.skipPatterns = patterns
+	o.ignoredAddresses, o.ignoredAttributes = compileIgnoreSet(o.Ignore)
+	return nil
+}
+
+// compileSkipPatterns compiles the regular expression entries of a skip list. Patterns must match the
+// whole attribute name.
+func compileSkipPatterns(entries []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0)
+	for _, entry := range entries {
+		expr, ok := strings.CutPrefix(entry, skipPatternPrefix)
+		if !ok {
+			continue
+		}
+
+		pattern, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid SkipAttributes pattern %q", entry)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// skipAttributes returns the attributes of the given attribute sets to skip in the diff: the built-in
+// skipAttrs unless disabled, the configured SkipAttributes names, and the names matching a pattern.
+func (o *CompareOptions) skipAttributes(attrSets ...map[string]interface{}) map[string]bool {
+	if len(o.SkipAttributes) == 0 && !o.DisableDefaultSkipAttributes {
+		return skipAttrs
+	}
+
+	skip := make(map[string]bool)
+	for _, entry := range o.SkipAttributes {
+		if !strings.HasPrefix(entry, skipPatternPrefix) {
+			skip[entry] = true
+		}
+	}
+	if !o.DisableDefaultSkipAttributes {
+		for attr := range skipAttrs {
+			skip[attr] = true
+		}
+	}
+
+	for _, attrs := range attrSets {
+		for attr := range attrs {
+			if !skip[attr] && matchesAnyPattern(attr, o.skipPatterns) {
+				skip[attr] = true
+			}
+		}
+	}
+	return skip
+}
+
+// dropSkippedAttributes returns copies of a resource's attribute sets without the attributes to skip, and the
+// number of dropped attributes whose value changed.
+func dropSkippedAttributes(origAttrs, newAttrs map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}, int) {
+	changed := 0
+	remainingOrig, remainingNew := copyEntry(origAttrs), copyEntry(newAttrs)
+	for attr := range opts.skipAttributes(origAttrs, newAttrs) {
+		origV, origExists := origAttrs[attr]
+		newV, newExists := newAttrs[attr]
+		if !origExists && !newExists {
+			continue
+		}
+		if origExists != newExists || !valuesEqual(origV, newV, opts) {
+			changed++
+		}
+		delete(remainingOrig, attr)
+		delete(remainingNew, attr)
+	}
+	return remainingOrig, remainingNew, changed
+}
+
+// matchesAnyPattern reports whether s matches one of the patterns.
+func matchesAnyPattern(s string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipAttributes_Patterns(t *testing.T) {
+	origAttrs := map[string]interface{}{
+		"source_sha1": "a", "source_sha256": "a", "sha256_note": "a", "etag": "1", "bucket": "logs",
+	}
+	newAttrs := map[string]interface{}{
+		"source_sha1": "b", "source_sha256": "b", "sha256_note": "b", "etag": "2", "bucket": "logs-v2",
+	}
+
+	tests := []struct {
+		name     string
+		skip     []string
+		contains []string
+		skipped  []string
+	}{
+		{
+			name:     "pattern covers checksum variants",
+			skip:     []string{`re:.*_sha\d+`},
+			contains: []string{"~ bucket", "~ etag", "~ sha256_note"},
+			skipped:  []string{"source_sha1", "source_sha256"},
+		},
+		{
+			name:     "pattern must match the whole name",
+			skip:     []string{"re:sha256"},
+			contains: []string{"~ sha256_note", "~ source_sha256"},
+		},
+		{
+			name:     "patterns combine with names",
+			skip:     []string{"etag", "re:source_.*"},
+			contains: []string{"~ bucket", "~ sha256_note"},
+			skipped:  []string{"etag", "source_sha1", "source_sha256"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &CompareOptions{SkipAttributes: tc.skip}
+			require.NoError(t, opts.compile())
+
+			var diff strings.Builder
+			processAttributeDifferences(&diff, origAttrs, newAttrs, nil, opts)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff.String(), expected)
+			}
+			for _, attr := range tc.skipped {
+				assert.NotContains(t, diff.String(), attr)
+			}
+		})
+	}
+}
+
+func TestComparePlans_OnlySkippedAttributesChanged(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_s3_object.site", "change": {"after": {"key": "index.html", "etag": "1", "content_md5": "a"}}},
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "etag": "1"}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_s3_object.site", "change": {"after": {"key": "index.html", "etag": "2", "content_md5": "b"}}},
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "etag": "1"}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{SkipAttributes: []string{"etag"}})
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+	assert.NotContains(t, result.Text, "aws_s3_object.site")
+	assert.Empty(t, actualChanges(result.Changes))
+
+	// The built-in skipped attributes alone leave the plans equal
+	newJSON = strings.Replace(origJSON, `"content_md5": "a"`, `"content_md5": "b"`, 1)
+	result, err = ComparePlans(origJSON, newJSON, nil)
+	require.NoError(t, err)
+	assert.False(t, result.HasDiff)
+
+	equal, err := PlansEqual(origJSON, newJSON)
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	// A change next to the skipped attributes is still reported
+	newJSON = strings.Replace(newJSON, `"index.html"`, `"404.html"`, 1)
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{SkipAttributes: []string{"etag"}})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_s3_object.site", Attribute: "key"},
+	}, actualChanges(result.Changes))
+}
+
+func TestCompareOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		skip        []string
+		errContains string
+	}{
+		{name: "names and valid patterns", skip: []string{"etag", `re:.*_sha\d+`}},
+		{name: "invalid pattern", skip: []string{"etag", "re:content_(md5"}, errContains: `invalid SkipAttributes pattern "re:content_(md5"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &CompareOptions{SkipAttributes: tc.skip}
+			err := opts.Validate()
+
+			_, compareErr := ComparePlans(`{}`, `{}`, opts)
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, compareErr)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+			require.Error(t, compareErr)
+			assert.Contains(t, compareErr.Error(), tc.errContains)
+		})
+	}
+}
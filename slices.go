@@ -0,0 +1,50 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compareSlices writes the element changes between two list values and returns them as added, removed
+// and changed entries with their element index. Elements are aligned by their longest common
+// subsequence, so reordering or inserting elements does not report every following element as changed.
+// Unaligned elements between two aligned ones are paired up as changes in order; the rest are added
+// (indexed in the new list) or removed (indexed in the original list).
+func compareSlices(diff *strings.Builder, path string, origSlice, newSlice []interface{}, depth int, opts *CompareOptions) map[string]interface{} {
+	added := make([]map[string]interface{}, 0)
+	removed := make([]map[string]interface{}, 0)
+	changed := make([]map[string]interface{}, 0)
+
+	pairs := longestCommonSubsequence(len(origSlice), len(newSlice), func(i, j int) bool {
+		return valuesEqual(origSlice[i], newSlice[j], opts)
+	})
+	pairs = append(pairs, [2]int{len(origSlice), len(newSlice)})
+
+	i, j := 0, 0
+	for _, pair := range pairs {
+		for ; i < pair[0] && j < pair[1]; i, j = i+1, j+1 {
+			entry := map[string]interface{}{"index": j}
+			changed = append(changed, processValueChange(diff, elementPath(path, j), entry, origSlice[i], newSlice[j], depth, opts))
+		}
+		for ; i < pair[0]; i++ {
+			diff.WriteString(fmt.Sprintf("  - %s: %v\n", elementPath(path, i), formatValue(origSlice[i], opts)))
+			removed = append(removed, map[string]interface{}{"index": i, "value": origSlice[i]})
+		}
+		for ; j < pair[1]; j++ {
+			diff.WriteString(fmt.Sprintf("  + %s: %v\n", elementPath(path, j), formatValue(newSlice[j], opts)))
+			added = append(added, map[string]interface{}{"index": j, "value": newSlice[j]})
+		}
+		i, j = pair[0]+1, pair[1]+1
+	}
+
+	return map[string]interface{}{
+		"added":   added,
+		"removed": removed,
+		"changed": changed,
+	}
+}
+
+// elementPath returns the path of a list element.
+func elementPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
@@ -0,0 +1,112 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSlices(t *testing.T) {
+	tests := []struct {
+		name     string
+		orig     []interface{}
+		new      []interface{}
+		expected string
+		elements map[string]interface{}
+	}{
+		{
+			name:     "element appended",
+			orig:     []interface{}{"sg-1", "sg-2"},
+			new:      []interface{}{"sg-1", "sg-2", "sg-3"},
+			expected: "  + ids[2]: sg-3\n",
+			elements: map[string]interface{}{
+				"added":   []map[string]interface{}{{"index": 2, "value": "sg-3"}},
+				"removed": []map[string]interface{}{},
+				"changed": []map[string]interface{}{},
+			},
+		},
+		{
+			name:     "element inserted in the middle",
+			orig:     []interface{}{"sg-1", "sg-3"},
+			new:      []interface{}{"sg-1", "sg-2", "sg-3"},
+			expected: "  + ids[1]: sg-2\n",
+			elements: map[string]interface{}{
+				"added":   []map[string]interface{}{{"index": 1, "value": "sg-2"}},
+				"removed": []map[string]interface{}{},
+				"changed": []map[string]interface{}{},
+			},
+		},
+		{
+			name:     "reordered list moves one element",
+			orig:     []interface{}{"a", "b", "c"},
+			new:      []interface{}{"c", "a", "b"},
+			expected: "  + ids[0]: c\n  - ids[2]: c\n",
+			elements: map[string]interface{}{
+				"added":   []map[string]interface{}{{"index": 0, "value": "c"}},
+				"removed": []map[string]interface{}{{"index": 2, "value": "c"}},
+				"changed": []map[string]interface{}{},
+			},
+		},
+		{
+			name:     "element replaced in place",
+			orig:     []interface{}{"a", "b", "c"},
+			new:      []interface{}{"a", "x", "c"},
+			expected: "  ~ ids[1]: b => x\n",
+			elements: map[string]interface{}{
+				"added":   []map[string]interface{}{},
+				"removed": []map[string]interface{}{},
+				"changed": []map[string]interface{}{{"index": 1, "old": "b", "new": "x"}},
+			},
+		},
+		{
+			name:     "element removed",
+			orig:     []interface{}{"a", "b", "c"},
+			new:      []interface{}{"a", "c"},
+			expected: "  - ids[1]: b\n",
+			elements: map[string]interface{}{
+				"added":   []map[string]interface{}{},
+				"removed": []map[string]interface{}{{"index": 1, "value": "b"}},
+				"changed": []map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var diff strings.Builder
+			elements := compareSlices(&diff, "ids", tc.orig, tc.new, 1, &CompareOptions{})
+
+			assert.Equal(t, tc.expected, diff.String())
+			assert.Equal(t, tc.elements, elements)
+		})
+	}
+}
+
+func TestProcessAttributeDifferences_ListElements(t *testing.T) {
+	rule := func(port int) map[string]interface{} {
+		return map[string]interface{}{"from_port": port, "protocol": "tcp"}
+	}
+	origAttrs := map[string]interface{}{"ingress": []interface{}{rule(22), rule(80)}}
+	newAttrs := map[string]interface{}{"ingress": []interface{}{rule(22), rule(8080)}}
+
+	tests := []struct {
+		name     string
+		opts     *CompareOptions
+		expected string
+	}{
+		{name: "changed element is diffed by key", opts: &CompareOptions{}, expected: "  ~ ingress[1].from_port: 80 => 8080\n"},
+		{name: "depth limit reports the element whole", opts: &CompareOptions{MaxAttributeDepth: 1}, expected: "  ~ ingress[1]: "},
+		{name: "negative depth reports the list whole", opts: &CompareOptions{MaxAttributeDepth: -1}, expected: "  ~ ingress: "},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var diff strings.Builder
+			attrChanges := processAttributeDifferences(&diff, origAttrs, newAttrs, nil, tc.opts)
+
+			assert.True(t, strings.HasPrefix(diff.String(), tc.expected), diff.String())
+			assert.Len(t, attrChanges["changed"], 1)
+		})
+	}
+}
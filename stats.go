@@ -0,0 +1,16 @@
+package comparison
+
+import "time"
+
+// CompareStats records the cost of a comparison, for tracking it over time.
+type CompareStats struct {
+	// OriginalBytes and NewBytes are the sizes of the input documents.
+	OriginalBytes int
+	NewBytes      int
+
+	// ParseDuration covers decoding both inputs, NormalizeDuration sorting the decoded plans and
+	// DiffDuration generating the diff.
+	ParseDuration     time.Duration
+	NormalizeDuration time.Duration
+	DiffDuration      time.Duration
+}
@@ -0,0 +1,47 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans_Stats(t *testing.T) {
+	origJSON := `{
+		"variables": {"count": {"value": 2}},
+		"resource_changes": [{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}}]
+	}`
+	newJSON := `{
+		"variables": {"count": {"value": 3}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs"}}}
+		]
+	}`
+
+	tests := []struct {
+		name     string
+		origPlan string
+		newPlan  string
+		opts     *CompareOptions
+	}{
+		{name: "json inputs", origPlan: origJSON, newPlan: newJSON},
+		{name: "identical inputs", origPlan: origJSON, newPlan: origJSON},
+		{name: "msgpack inputs", origPlan: toMsgpack(t, origJSON), newPlan: toMsgpack(t, newJSON), opts: &CompareOptions{InputFormat: InputFormatMsgpack}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(tc.origPlan, tc.newPlan, tc.opts)
+			require.NoError(t, err)
+
+			stats := result.Stats
+			assert.Equal(t, len(tc.origPlan), stats.OriginalBytes)
+			assert.Equal(t, len(tc.newPlan), stats.NewBytes)
+			assert.GreaterOrEqual(t, int64(stats.ParseDuration), int64(0))
+			assert.GreaterOrEqual(t, int64(stats.NormalizeDuration), int64(0))
+			assert.GreaterOrEqual(t, int64(stats.DiffDuration), int64(0))
+		})
+	}
+}
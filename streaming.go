@@ -0,0 +1,211 @@
+package comparison
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// StreamedChange is a resource change emitted by CompareStreaming while the new plan is still being read.
+type StreamedChange struct {
+	Address string
+
+	// Kind is ChangeAdded for resources missing from the original plan and ChangeModified otherwise.
+	Kind ChangeKind
+
+	// Attributes lists the top-level attribute differences, before any normalization options apply.
+	Attributes []Change
+}
+
+// CompareStreaming compares a plan document against a new plan read in a single forward pass. Entries of
+// the new plan's resource_changes are compared with the original plan as soon as they are decoded, and
+// every added or changed resource is passed to emit before the rest of the new plan is read. Resources are
+// filtered, ignored and paired like ComparePlans does, and skipped and ignored attributes are left out.
+// Removed resources are only known once the whole plan is read, and so are no-op entries for resources that
+// neither prior_state nor planned_values have held so far, since ComparePlans only skips no-op entries for
+// resources those sections hold. With a ResourceKeyFunc, keys can only be told apart once every resource is
+// known, so every change is emitted after the whole plan is read. The returned diff is identical to
+// ComparePlans, except that UseSchemaOrder only reads declaration order from the original plan.
+func CompareStreaming(origPlanJSON string, newPlan io.Reader, opts *CompareOptions, emit func(StreamedChange)) (*PlanDiff, error) {
+	opts = resolveOptions(opts)
+	if err := opts.compile(); err != nil {
+		return nil, err
+	}
+
+	origData := []byte(origPlanJSON)
+	var origPlan map[string]interface{}
+	if err := json.Unmarshal(origData, &origPlan); err != nil {
+		return nil, errors.Wrap(err, "error parsing original plan JSON")
+	}
+	origResources := getComparedResources(origPlan, opts)
+	if opts.DriftMode == DriftModeMerge {
+		origResources = mergeDriftResources(origPlan, origResources)
+	}
+
+	if opts.UseSchemaOrder {
+		opts.schemaOrder = loadSchemaOrder(origData)
+	}
+
+	emitChange := func(key string, origResource, newResource interface{}) {
+		if matchesAnyPattern(key, opts.ignoredAddresses) {
+			return
+		}
+		if origResource == nil {
+			emit(StreamedChange{Address: key, Kind: ChangeAdded})
+			return
+		}
+
+		origAttrs, newAttrs, _ := dropSkippedAttributes(getComparedAttributes(origResource, opts), getComparedAttributes(newResource, opts), opts)
+		origAttrs, newAttrs, _ = dropIgnoredAttributes(origAttrs, newAttrs, opts)
+		changes := diffTopLevel(origAttrs, newAttrs)
+		for i := range changes {
+			changes[i].Old, changes[i].New = resolveSensitiveStandIns(changes[i].Old), resolveSensitiveStandIns(changes[i].New)
+		}
+		if len(changes) > 0 {
+			emit(StreamedChange{Address: key, Kind: ChangeModified, Attributes: maskChanges(changes, opts)})
+		}
+	}
+
+	// Keys are resolved against every resource, so keyed resources are paired once the plan is read
+	if opts.ResourceKeyFunc != nil {
+		plan, err := decodePlanStreaming(newPlan, func(_, _ map[string]interface{}) {})
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing new plan JSON")
+		}
+
+		newResources := getComparedResources(plan, opts)
+		if opts.DriftMode == DriftModeMerge {
+			newResources = mergeDriftResources(plan, newResources)
+		}
+		pairedOrig, pairedNew := pairResources(origResources, newResources, opts)
+		for _, key := range getSortedKeys(pairedNew, nil) {
+			emitChange(key, pairedOrig[key], pairedNew[key])
+		}
+
+		return comparePlanMaps(origPlan, plan, opts)
+	}
+	origResources, _ = pairResources(origResources, nil, opts)
+
+	// The sections decoded before resource_changes are complete by its first entry
+	var held map[string]interface{}
+	deferred := make([]map[string]interface{}, 0)
+	onResourceChange := func(partialPlan, entry map[string]interface{}) {
+		address, ok := entry["address"].(string)
+		if !ok || (opts.PlannedOnly && isNoOpChange(entry)) {
+			return
+		}
+		if _, ok := filterAddresses(map[string]interface{}{address: entry}, opts)[address]; !ok {
+			return
+		}
+
+		origResource, exists := origResources[address]
+		if exists && !opts.IncludeNoOpChanges && isNoOpChange(entry) {
+			if held == nil {
+				held = heldResources(partialPlan)
+			}
+			if _, ok := held[address]; !ok {
+				deferred = append(deferred, entry)
+			}
+			return
+		}
+
+		emitChange(address, origResource, entry)
+	}
+
+	plan, err := decodePlanStreaming(newPlan, onResourceChange)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing new plan JSON")
+	}
+
+	// No-op entries stand for their resource unless a section read later holds it
+	if len(deferred) > 0 {
+		held = heldResources(plan)
+		for _, entry := range deferred {
+			address := entry["address"].(string)
+			if _, ok := held[address]; !ok {
+				emitChange(address, origResources[address], entry)
+			}
+		}
+	}
+
+	return comparePlanMaps(origPlan, plan, opts)
+}
+
+// heldResources returns the resources a plan holds in prior_state and planned_values, which ComparePlans
+// prefers over no-op entries of resource_changes.
+func heldResources(plan map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	processPriorStateResources(plan, result)
+	processPlannedValuesResources(plan, result)
+	return result
+}
+
+// decodePlanStreaming decodes a plan document in a single pass, calling onResourceChange with the plan
+// decoded so far for every entry of resource_changes as soon as it is decoded.
+func decodePlanStreaming(r io.Reader, onResourceChange func(plan, entry map[string]interface{})) (map[string]interface{}, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	plan := make(map[string]interface{})
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		if key != "resource_changes" {
+			var value interface{}
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			plan[key] = value
+			continue
+		}
+
+		changes, err := decodeResourceChanges(dec, func(entry map[string]interface{}) {
+			onResourceChange(plan, entry)
+		})
+		if err != nil {
+			return nil, err
+		}
+		plan[key] = changes
+	}
+
+	return plan, expectDelim(dec, '}')
+}
+
+// decodeResourceChanges decodes the resource_changes array entry by entry.
+func decodeResourceChanges(dec *json.Decoder, onResourceChange func(map[string]interface{})) ([]interface{}, error) {
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, err
+	}
+
+	changes := make([]interface{}, 0)
+	for dec.More() {
+		var entry map[string]interface{}
+		if err := dec.Decode(&entry); err != nil {
+			return nil, err
+		}
+		onResourceChange(entry)
+		changes = append(changes, entry)
+	}
+
+	return changes, expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token and checks that it is the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(token, delim) {
+		return errors.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
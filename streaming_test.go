@@ -0,0 +1,237 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hugePlan builds a plan with n resources. The resource at index changed gets a different instance type.
+func hugePlan(n, changed int, instanceType string) string {
+	var sb strings.Builder
+	sb.WriteString(`{"format_version": "1.2", "resource_changes": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		value := "t3.micro"
+		if i == changed {
+			value = instanceType
+		}
+		fmt.Fprintf(&sb, `{"address": "aws_instance.web[%d]", "change": {"after": {"instance_type": %q, "ami": "ami-1"}}}`, i, value)
+	}
+	sb.WriteString(`], "output_changes": {}}`)
+	return sb.String()
+}
+
+func TestCompareStreaming(t *testing.T) {
+	orig := `{
+		"variables": {"env": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.old", "change": {"after": {"bucket": "old"}}}
+		]
+	}`
+	updated := `{
+		"variables": {"env": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"instance_type": "t3.large"}}},
+			{"address": "aws_s3_bucket.new", "change": {"after": {"bucket": "new"}}}
+		]
+	}`
+
+	emitted := make([]StreamedChange, 0)
+	result, err := CompareStreaming(orig, strings.NewReader(updated), nil, func(change StreamedChange) {
+		emitted = append(emitted, change)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []StreamedChange{
+		{
+			Address:    "aws_instance.web",
+			Kind:       ChangeModified,
+			Attributes: []Change{{Path: "instance_type", Kind: ChangeModified, Old: "t3.micro", New: "t3.large"}},
+		},
+		{Address: "aws_s3_bucket.new", Kind: ChangeAdded},
+	}, emitted)
+
+	expected, err := ComparePlans(orig, updated, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Text, result.Text)
+	assert.Equal(t, expected.Changes, result.Changes)
+	assert.Equal(t, expected.HasDiff, result.HasDiff)
+}
+
+func TestCompareStreaming_NoOpEntries(t *testing.T) {
+	held := `{"root_module": {"resources": [{"address": "aws_instance.web", "values": {"instance_type": "t3.micro"}}]}}`
+	noOp := `{"address": "aws_instance.web", "change": {"actions": ["no-op"], "after": {"instance_type": "t3.large"}}}`
+	orig := `{"planned_values": ` + held + `}`
+
+	tests := []struct {
+		name     string
+		updated  string
+		expected []StreamedChange
+	}{
+		{
+			name:    "no-op entry standing for its resource",
+			updated: `{"resource_changes": [` + noOp + `]}`,
+			expected: []StreamedChange{{
+				Address:    "aws_instance.web",
+				Kind:       ChangeModified,
+				Attributes: []Change{{Path: "instance_type", Kind: ChangeModified, Old: "t3.micro", New: "t3.large"}},
+			}},
+		},
+		{
+			name:     "resource held by planned_values before the entry",
+			updated:  `{"planned_values": ` + held + `, "resource_changes": [` + noOp + `]}`,
+			expected: []StreamedChange{},
+		},
+		{
+			name:     "resource held by prior_state after the entry",
+			updated:  `{"resource_changes": [` + noOp + `], "prior_state": {"values": ` + held + `}}`,
+			expected: []StreamedChange{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			emitted := make([]StreamedChange, 0)
+			result, err := CompareStreaming(orig, strings.NewReader(tc.updated), nil, func(change StreamedChange) {
+				emitted = append(emitted, change)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, emitted)
+
+			expected, err := ComparePlans(orig, tc.updated, nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Text, result.Text)
+			assert.Equal(t, expected.Changes, result.Changes)
+			assert.Equal(t, len(tc.expected) > 0, expected.HasDiff)
+		})
+	}
+}
+
+func TestCompareStreaming_FilterOptions(t *testing.T) {
+	orig := `{"resource_changes": [
+		{"address": "aws_instance.a", "change": {"after": {"ami": "ami-1", "tags": {"Owner": "a"}}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "acl": "private", "content_md5": "1"}}},
+		{"address": "aws_s3_bucket.legacy", "change": {"after": {"bucket": "legacy"}}},
+		{"address": "aws_lb.old", "change": {"after": {"name": "main", "idle_timeout": 60}}},
+		{"address": "aws_iam_role.ci", "change": {"after": {"name": "ci", "path": "/"}}}
+	]}`
+	updated := `{"resource_changes": [
+		{"address": "aws_instance.a", "change": {"after": {"ami": "ami-2", "tags": {"Owner": "a"}}}},
+		{"address": "aws_instance.b", "change": {"after": {"ami": "ami-1"}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "acl": "private", "content_md5": "2"}}},
+		{"address": "aws_s3_bucket.legacy", "change": {"after": {"bucket": "legacy-2"}}},
+		{"address": "aws_lb.new", "change": {"after": {"name": "main", "idle_timeout": 120}}},
+		{"address": "aws_iam_role.deploy", "change": {"after": {"name": "ci", "path": "/ci/"}}}
+	]}`
+
+	tests := []struct {
+		name     string
+		opts     *CompareOptions
+		expected []StreamedChange
+	}{
+		{
+			name: "excluded addresses and skipped attributes",
+			opts: &CompareOptions{
+				ExcludeAddresses: []string{"aws_instance.*", "aws_lb.*", "aws_iam_role.*"},
+				SkipAttributes:   []string{"ami", "bucket"},
+			},
+			expected: []StreamedChange{},
+		},
+		{
+			name: "excluded types and ignored resources and attributes",
+			opts: &CompareOptions{
+				ExcludeTypes: []string{"aws_instance", "aws_iam_role"},
+				Ignore:       &IgnoreSet{Addresses: []string{"aws_s3_bucket.leg*"}, Attributes: []string{"idle_*"}},
+			},
+			expected: []StreamedChange{
+				{Address: "aws_lb.new", Kind: ChangeAdded},
+			},
+		},
+		{
+			name: "aliased addresses",
+			opts: &CompareOptions{
+				IncludeAddresses: []string{"aws_lb.*"},
+				AddressAliases:   map[string]string{"aws_lb.old": "aws_lb.new"},
+			},
+			expected: []StreamedChange{
+				{Address: "aws_lb.new", Kind: ChangeModified, Attributes: []Change{{Path: "idle_timeout", Kind: ChangeModified, Old: 60.0, New: 120.0}}},
+			},
+		},
+		{
+			name: "resource keys",
+			opts: &CompareOptions{
+				IncludeTypes: []string{"aws_iam_role"},
+				ResourceKeyFunc: func(resMap map[string]interface{}) string {
+					after, _ := resMap["change"].(map[string]interface{})["after"].(map[string]interface{})
+					name, _ := after["name"].(string)
+					return "role:" + name
+				},
+			},
+			expected: []StreamedChange{
+				{Address: "role:ci", Kind: ChangeModified, Attributes: []Change{{Path: "path", Kind: ChangeModified, Old: "/", New: "/ci/"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emitted := make([]StreamedChange, 0)
+			result, err := CompareStreaming(orig, strings.NewReader(updated), tt.opts, func(change StreamedChange) {
+				emitted = append(emitted, change)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, emitted)
+
+			expected, err := ComparePlans(orig, updated, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Changes, result.Changes)
+			assert.Equal(t, len(emitted) > 0, expected.HasDiff)
+		})
+	}
+}
+
+func TestCompareStreaming_InvalidJSON(t *testing.T) {
+	_, err := CompareStreaming("{}", strings.NewReader(`{"resource_changes": [{"address": `), nil, func(StreamedChange) {})
+	require.Error(t, err)
+
+	_, err = CompareStreaming("{}", strings.NewReader(`[]`), nil, func(StreamedChange) {})
+	require.Error(t, err)
+}
+
+func BenchmarkTimeToFirstChange_Streaming(b *testing.B) {
+	orig, updated := hugePlan(20000, 0, "t3.micro"), hugePlan(20000, 0, "t3.large")
+
+	var firstChange time.Duration
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		seen := false
+		_, _ = CompareStreaming(orig, strings.NewReader(updated), nil, func(StreamedChange) {
+			if !seen {
+				firstChange += time.Since(start)
+				seen = true
+			}
+		})
+	}
+	b.ReportMetric(float64(firstChange.Nanoseconds())/float64(b.N), "ns/first-change")
+}
+
+func BenchmarkTimeToFirstChange_ParseAll(b *testing.B) {
+	orig, updated := hugePlan(20000, 0, "t3.micro"), hugePlan(20000, 0, "t3.large")
+
+	// Without streaming the first change is only available once the whole diff is done
+	var firstChange time.Duration
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		_, _ = ComparePlans(orig, updated, nil)
+		firstChange += time.Since(start)
+	}
+	b.ReportMetric(float64(firstChange.Nanoseconds())/float64(b.N), "ns/first-change")
+}
@@ -0,0 +1,100 @@
+package comparison
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SectionCounts counts the changes of one section of a diff map.
+type SectionCounts struct {
+	Added   int
+	Removed int
+
+	// Changed counts changed entries, including moved and mode-changed resources.
+	Changed int
+}
+
+// DiffSummary counts the changes of each section of a diff map.
+type DiffSummary struct {
+	Resources SectionCounts
+	Variables SectionCounts
+	Outputs   SectionCounts
+
+	// Drift is only counted when drift is compared in a section of its own.
+	Drift SectionCounts
+
+	// Tags is only counted when tag-only changes are separated from the other resource changes.
+	Tags SectionCounts
+
+	// Ignored counts the resource and attribute changes left out by the Ignore option.
+	Ignored int
+}
+
+// SummarizeDiff counts the added, removed and changed entries of each section of a diff map. The diff
+// map is the filtered result of a comparison, so the counts match what the text diff shows.
+func SummarizeDiff(diffMap map[string]interface{}) DiffSummary {
+	return DiffSummary{
+		Resources: countSection(diffMap["resources"]),
+		Variables: countSection(diffMap["variables"]),
+		Outputs:   countSection(diffMap["outputs"]),
+		Drift:     countSection(diffMap["drift"]),
+		Tags:      countSection(diffMap["tag_changes"]),
+		Ignored:   countIgnored(diffMap["ignored"]),
+	}
+}
+
+// String formats the summary as a single line, e.g. "Resources: +3 -1 ~5, Variables: +0 -0 ~2, Outputs: +1 -0 ~0".
+// Drift and Tags are only included when they have changes, and ignored changes when there are any.
+func (s DiffSummary) String() string {
+	parts := []string{
+		s.Resources.format("Resources"),
+		s.Variables.format("Variables"),
+		s.Outputs.format("Outputs"),
+	}
+	if s.Drift != (SectionCounts{}) {
+		parts = append(parts, s.Drift.format("Drift"))
+	}
+	if s.Tags != (SectionCounts{}) {
+		parts = append(parts, s.Tags.format("Tags"))
+	}
+	if s.Ignored > 0 {
+		parts = append(parts, fmt.Sprintf("%d ignored", s.Ignored))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// format formats the counts of a section under its title.
+func (c SectionCounts) format(title string) string {
+	return fmt.Sprintf("%s: +%d -%d ~%d", title, c.Added, c.Removed, c.Changed)
+}
+
+// countSection counts the entries of a diff map section. Moved, mode-changed and count-changed entries
+// count as changed.
+func countSection(section interface{}) SectionCounts {
+	categories, ok := section.(map[string]interface{})
+	if !ok {
+		return SectionCounts{}
+	}
+
+	counts := SectionCounts{
+		Added:   len(entryList(categories["added"])),
+		Removed: len(entryList(categories["removed"])),
+	}
+	for _, kind := range []string{"changed", "moved", "mode_changed", "count_changed"} {
+		counts.Changed += len(entryList(categories[kind]))
+	}
+	return counts
+}
+
+// countIgnored reads the count of ignored changes of a diff map, which is a float64 once the diff map has
+// been through JSON.
+func countIgnored(ignored interface{}) int {
+	switch count := ignored.(type) {
+	case int:
+		return count
+	case float64:
+		return int(count)
+	default:
+		return 0
+	}
+}
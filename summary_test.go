@@ -0,0 +1,92 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		diffMap  map[string]interface{}
+		expected DiffSummary
+		line     string
+	}{
+		{
+			name:    "empty diff",
+			diffMap: map[string]interface{}{},
+			line:    "Resources: +0 -0 ~0, Variables: +0 -0 ~0, Outputs: +0 -0 ~0",
+		},
+		{
+			name: "every section",
+			diffMap: map[string]interface{}{
+				"variables": map[string]interface{}{
+					"changed": []map[string]interface{}{{"name": "a"}, {"name": "b"}},
+				},
+				"resources": map[string]interface{}{
+					"added":           []map[string]interface{}{{"address": "a"}, {"address": "b"}, {"address": "c"}},
+					"removed":         []map[string]interface{}{{"address": "d"}},
+					"changed":         []map[string]interface{}{{"address": "e"}},
+					"moved":           []map[string]interface{}{{"address": "f"}},
+					"mode_changed":    []map[string]interface{}{{"address": "g"}},
+					"normalized_away": []map[string]interface{}{{"address": "h"}},
+				},
+				"outputs": map[string]interface{}{
+					"added": []map[string]interface{}{{"name": "url"}},
+				},
+			},
+			expected: DiffSummary{
+				Resources: SectionCounts{Added: 3, Removed: 1, Changed: 3},
+				Variables: SectionCounts{Changed: 2},
+				Outputs:   SectionCounts{Added: 1},
+			},
+			line: "Resources: +3 -1 ~3, Variables: +0 -0 ~2, Outputs: +1 -0 ~0",
+		},
+		{
+			name: "drift section",
+			diffMap: map[string]interface{}{
+				"drift": map[string]interface{}{
+					"added": []map[string]interface{}{{"address": "aws_s3_bucket.logs"}},
+				},
+			},
+			expected: DiffSummary{Drift: SectionCounts{Added: 1}},
+			line:     "Resources: +0 -0 ~0, Variables: +0 -0 ~0, Outputs: +0 -0 ~0, Drift: +1 -0 ~0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			summary := SummarizeDiff(tc.diffMap)
+			assert.Equal(t, tc.expected, summary)
+			assert.Equal(t, tc.line, summary.String())
+		})
+	}
+}
+
+func TestComparePlans_SummaryLine(t *testing.T) {
+	origJSON := `{
+		"variables": {"stage": {"value": "dev"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1"}}},
+			{"address": "aws_instance.skipped", "change": {"after": {"ami": "ami-1"}}}
+		]
+	}`
+	newJSON := `{
+		"variables": {"stage": {"value": "prod"}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2"}}},
+			{"address": "aws_instance.skipped", "change": {"after": {"ami": "ami-2"}}}
+		]
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{ExcludeAddresses: []string{"aws_instance.skipped"}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Text, "Resources: +0 -0 ~1, Variables: +0 -0 ~1, Outputs: +0 -0 ~0\n\nVariables:\n"))
+
+	identical, err := ComparePlans(origJSON, origJSON, nil)
+	require.NoError(t, err)
+	assert.Empty(t, identical.Text)
+}
@@ -0,0 +1,87 @@
+package comparison
+
+import (
+	"strings"
+)
+
+// tagAttributes are the attributes holding resource tags.
+var tagAttributes = stringSet([]string{"tags", "tags_all"})
+
+// separateTagChanges takes the changed resources of a resources diff map whose only changed attributes are
+// tags out of the compared resources, and compares them on their own. It returns the remaining resources,
+// the diff of the tag-only changes and their diff map, with the changed entries and the noise and
+// normalized_away entries set aside while comparing them. The resources are returned as they are, with a
+// nil diff map, when no change is tag-only.
+func separateTagChanges(origResources, newResources map[string]interface{}, diffMap map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}, string, map[string]interface{}) {
+	tagOrig, tagNew := make(map[string]interface{}), make(map[string]interface{})
+	for _, entry := range entryList(diffMap["changed"]) {
+		address := entryKey(entry)
+		origV, origExists := origResources[address]
+		newV, newExists := newResources[address]
+		if origExists && newExists && isTagOnlyChange(entry) {
+			tagOrig[address], tagNew[address] = origV, newV
+		}
+	}
+	if len(tagOrig) == 0 {
+		return origResources, newResources, "", nil
+	}
+
+	remainingOrig, remainingNew := copyEntry(origResources), copyEntry(newResources)
+	for address := range tagOrig {
+		delete(remainingOrig, address)
+		delete(remainingNew, address)
+	}
+
+	var diff strings.Builder
+	changed, normalizedAway, noise := processChangedResources(&diff, tagOrig, tagNew, opts)
+	tagDiffMap := map[string]interface{}{"changed": changed}
+	if len(noise) > 0 {
+		tagDiffMap["noise"] = noise
+	}
+	if opts.ReportNormalized && len(normalizedAway) > 0 {
+		tagDiffMap["normalized_away"] = normalizedAway
+	}
+	return remainingOrig, remainingNew, diff.String(), tagDiffMap
+}
+
+// mergeSetAsideChanges adds the noise and normalized_away entries of the tag-only changes to those of the
+// resources diff map, ordered by address and attribute name.
+func mergeSetAsideChanges(resourceDiffMap, tagDiffMap map[string]interface{}) {
+	for _, category := range []string{"noise", "normalized_away"} {
+		entries := entryList(tagDiffMap[category])
+		if len(entries) == 0 {
+			continue
+		}
+
+		merged := append(entryList(resourceDiffMap[category]), entries...)
+		sortNormalizedAway(merged)
+		resourceDiffMap[category] = merged
+	}
+}
+
+// isTagOnlyChange reports whether every attribute change of a changed resource entry is within tags or tags_all.
+func isTagOnlyChange(entry map[string]interface{}) bool {
+	attrs, ok := entry["attributes"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	found := false
+	for _, kind := range []string{"added", "removed", "changed"} {
+		for _, attr := range entryList(attrs[kind]) {
+			if !tagAttributes[attributeRoot(entryKey(attr))] {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// attributeRoot returns the top-level attribute of an attribute path, e.g. "tags" for "tags.Name".
+func attributeRoot(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,108 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans_SeparateTagChanges(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "tags": {"Owner": "a"}, "tags_all": {"Owner": "a"}}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "a", "Team": "x"}}}},
+		{"address": "aws_sqs_queue.jobs", "change": {"after": {"name": "jobs", "tags": {"Owner": "a"}}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "tags": {"Owner": "b"}, "tags_all": {"Owner": "b"}}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "b"}}}},
+		{"address": "aws_sqs_queue.jobs", "change": {"after": {"name": "jobs", "tags": {"Owner": "a"}, "tags_all": {"Owner": "a"}}}}
+	]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{SeparateTagChanges: true})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.Contains(t, result.Text, "Resources: +0 -0 ~1, Variables: +0 -0 ~0, Outputs: +0 -0 ~0, Tags: +0 -0 ~2\n")
+	assert.Contains(t, result.Text, "Resources:\n-----------\n\naws_instance.web\n  ~ ami: ami-1 => ami-2\n")
+	assert.Contains(t, result.Text, "Tag Changes:\n------------\n\naws_s3_bucket.logs\n  ~ tags.Owner: a => b\n  - tags.Team: x\naws_sqs_queue.jobs\n  + tags_all: {Owner: a}\n\n")
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "ami"},
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "tags"},
+		{Section: "resources", Kind: "changed", Address: "aws_instance.web", Attribute: "tags_all"},
+		{Section: "tag_changes", Kind: "changed", Address: "aws_s3_bucket.logs", Attribute: "tags"},
+		{Section: "tag_changes", Kind: "changed", Address: "aws_sqs_queue.jobs", Attribute: "tags_all"},
+	}, actualChanges(result.Changes))
+
+	// Without the option tag changes stay with the other resource changes
+	result, err = ComparePlans(origJSON, newJSON, &CompareOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Text, "Tag Changes:")
+	assert.NotContains(t, result.Changes, "tag_changes")
+}
+
+func TestComparePlans_SeparateTagChanges_OnlyTags(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "a"}}}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "tags": {"Owner": "b"}}}}]}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{SeparateTagChanges: true})
+	require.NoError(t, err)
+	assert.True(t, result.HasDiff)
+	assert.NotContains(t, result.Text, "Resources:\n")
+	assert.Contains(t, result.Text, "Tag Changes:\n------------\n\naws_s3_bucket.logs\n  ~ tags.Owner: a => b\n\n")
+	assert.Equal(t, DiffSummary{Tags: SectionCounts{Changed: 1}}, SummarizeDiff(result.Changes))
+}
+
+func TestComparePlans_SeparateTagChanges_KeepsSetAsideChanges(t *testing.T) {
+	origJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-1", "user_data": "echo  a"}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "etag": "3f2b8c1e-9d4a-4e6f-8a7b-1c2d3e4f5a6b", "policy": "allow  all", "tags": {"Owner": "a"}}}}
+	]}`
+	newJSON := `{"resource_changes": [
+		{"address": "aws_instance.web", "change": {"after": {"ami": "ami-2", "user_data": "echo a"}}},
+		{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs", "etag": "a1b2c3d4-e5f6-4789-8abc-def012345678", "policy": "allow all", "tags": {"Owner": "b"}}}}
+	]}`
+
+	opts := &CompareOptions{SeparateTagChanges: true, NoiseHeuristics: true, IgnoreWhitespace: true, ReportNormalized: true}
+	result, err := ComparePlans(origJSON, newJSON, opts)
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "Tag Changes:\n------------\n\naws_s3_bucket.logs\n  ~ tags.Owner: a => b\n\n")
+
+	resources := result.Changes["resources"].(map[string]interface{})
+	assert.Equal(t, []map[string]interface{}{
+		{"address": "aws_s3_bucket.logs", "name": "etag", "old": "3f2b8c1e-9d4a-4e6f-8a7b-1c2d3e4f5a6b", "new": "a1b2c3d4-e5f6-4789-8abc-def012345678"},
+	}, resources["noise"])
+	assert.Equal(t, []map[string]interface{}{
+		{"address": "aws_instance.web", "name": "user_data", "reason": "whitespace", "old": "echo  a", "new": "echo a"},
+		{"address": "aws_s3_bucket.logs", "name": "policy", "reason": "whitespace", "old": "allow  all", "new": "allow all"},
+	}, resources["normalized_away"])
+}
+
+func TestIsTagOnlyChange(t *testing.T) {
+	attrs := func(names ...string) map[string]interface{} {
+		changed := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			changed = append(changed, map[string]interface{}{"name": name})
+		}
+		return map[string]interface{}{"attributes": map[string]interface{}{"changed": changed}}
+	}
+
+	tests := []struct {
+		name     string
+		entry    map[string]interface{}
+		expected bool
+	}{
+		{name: "tags", entry: attrs("tags.Owner", "tags_all.Owner"), expected: true},
+		{name: "whole tags map", entry: attrs("tags"), expected: true},
+		{name: "list index", entry: attrs("tags[0]"), expected: true},
+		{name: "mixed", entry: attrs("tags.Owner", "ami"), expected: false},
+		{name: "similar name", entry: attrs("tagset"), expected: false},
+		{name: "no changes", entry: attrs(), expected: false},
+		{name: "no attributes", entry: map[string]interface{}{"address": "a"}, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isTagOnlyChange(tc.entry))
+		})
+	}
+}
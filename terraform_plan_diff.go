@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/pkg/errors"
@@ -14,62 +15,239 @@ import (
 var (
 	// ErrNoJSONOutput is returned when no JSON output is found in terraform show output.
 	ErrNoJSONOutput = errors.New("no JSON output found in terraform show output")
+
+	// ErrPlanHasDiff is returned by RunComparison when the plans differ.
+	ErrPlanHasDiff = errors.New("plan files have differences")
+
+	// ErrUnsupportedFormatVersion is returned with StrictVersion when a plan's format_version is missing or unknown.
+	ErrUnsupportedFormatVersion = errors.New("unsupported plan format version")
+
+	// ErrNotAPlan is returned by ValidatePlanJSON when a document is not a Terraform plan.
+	ErrNotAPlan = errors.New("not a terraform plan")
 )
 
+// ExitCodePlanHasDiff is the conventional process exit code for a CLI whose comparison found differences,
+// distinguishing them from success (0) and errors (1).
+const ExitCodePlanHasDiff = 2
+
+// PlanDiff is the result of comparing two plans.
+type PlanDiff struct {
+	// Text is the human-readable diff.
+	Text string
+
+	// Changes is the structured diff keyed by section ("variables", "resources", "drift", "outputs").
+	Changes map[string]interface{}
+
+	// HasDiff reports whether the plans differ.
+	HasDiff bool
+
+	// Stats records the input sizes and how long each stage of the comparison took.
+	Stats CompareStats
+}
+
 // ComparePlansAndGenerateDiff compares two plan files and generates a diff.
 func ComparePlansAndGenerateDiff(origPlanFileJSON, newPlanFileJSON string) (string, map[string]interface{}, bool, error) {
-	// Parse the JSON
-	var origPlan, newPlan map[string]interface{}
-	err := json.Unmarshal([]byte(origPlanFileJSON), &origPlan)
+	return ComparePlansAndGenerateDiffWithOptions(origPlanFileJSON, newPlanFileJSON, nil)
+}
+
+// ComparePlansFromShowOutput compares two plans captured from `terraform show -json` output, which may be
+// surrounded by log lines or warnings, and generates a diff like ComparePlansAndGenerateDiff. The error
+// names the side whose output holds no plan JSON.
+func ComparePlansFromShowOutput(origShowOut, newShowOut string) (string, map[string]interface{}, bool, error) {
+	origPlanFileJSON, err := extractJSONFromOutput(origShowOut)
 	if err != nil {
-		return "", nil, false, errors.Wrap(err, "error parsing original plan JSON")
+		return "", nil, false, errors.Wrap(err, "error extracting original plan JSON")
 	}
 
-	err = json.Unmarshal([]byte(newPlanFileJSON), &newPlan)
+	newPlanFileJSON, err := extractJSONFromOutput(newShowOut)
 	if err != nil {
-		return "", nil, false, errors.Wrap(err, "error parsing new plan JSON")
+		return "", nil, false, errors.Wrap(err, "error extracting new plan JSON")
 	}
 
-	log.Printf("Parsed both JSONs. Sorting maps now...")
+	return ComparePlansAndGenerateDiff(origPlanFileJSON, newPlanFileJSON)
+}
 
-	// Sort maps to ensure consistent ordering
-	origPlan = sortMapKeys(origPlan)
-	newPlan = sortMapKeys(newPlan)
+// ComparePlansAndGenerateDiffWithOptions compares two plan files and generates a diff using the given options.
+// The diff is printed to opts.Writer unless opts.Silent is set. A nil opts uses the defaults.
+func ComparePlansAndGenerateDiffWithOptions(origPlanFileJSON, newPlanFileJSON string, opts *CompareOptions) (string, map[string]interface{}, bool, error) {
+	return comparePlanBytesAndGenerateDiff([]byte(origPlanFileJSON), []byte(newPlanFileJSON), opts)
+}
 
-	log.Printf("Sorted maps. Generating diff now...")
+// comparePlanBytesAndGenerateDiff compares two plan documents held as bytes like
+// ComparePlansAndGenerateDiffWithOptions, decoding them as they are.
+func comparePlanBytesAndGenerateDiff(origPlanFileJSON, newPlanFileJSON []byte, opts *CompareOptions) (string, map[string]interface{}, bool, error) {
+	opts = resolveOptions(opts)
 
-	// Generate the diff
-	diff_string, diff_map, hasDiff := generatePlanDiff(origPlan, newPlan)
+	differ, err := NewDiffer(opts)
+	if err != nil {
+		return "", nil, false, err
+	}
+	result, err := differ.compareBytes(origPlanFileJSON, newPlanFileJSON)
+	if err != nil {
+		return "", nil, false, err
+	}
 
-	// Print the diff
-	if hasDiff {
-		fmt.Fprintln(os.Stdout, "\nDiff Output")
-		fmt.Fprintln(os.Stdout, "===========")
-		fmt.Fprintln(os.Stdout, "")
-		fmt.Fprintln(os.Stdout, diff_string)
+	if !opts.Silent {
+		printPlanDiff(result, opts)
+	}
+	return result.Text, result.Changes, result.HasDiff, nil
+}
 
-		// Print the error message
-		// u.PrintErrorMarkdown("", terrerrors.ErrPlanHasDiff, "")
+// RunComparison compares two plan files, prints the diff like ComparePlansAndGenerateDiffWithOptions and
+// returns ErrPlanHasDiff when the plans differ. It never exits the process: a CLI wrapper decides the exit
+// code, by convention ExitCodePlanHasDiff for ErrPlanHasDiff. A nil opts uses the defaults.
+//
+//	if err := comparison.RunComparison(orig, updated, nil); errors.Is(err, comparison.ErrPlanHasDiff) {
+//		os.Exit(comparison.ExitCodePlanHasDiff)
+//	}
+func RunComparison(origPlanFileJSON, newPlanFileJSON string, opts *CompareOptions) error {
+	_, _, hasDiff, err := ComparePlansAndGenerateDiffWithOptions(origPlanFileJSON, newPlanFileJSON, opts)
+	if err != nil {
+		return err
+	}
+	if hasDiff {
+		return ErrPlanHasDiff
+	}
+	return nil
+}
 
-		// Exit with code 2 to indicate that the plans are different
-		// u.OsExit(2)
+// printPlanDiff prints a comparison result to the configured writer.
+func printPlanDiff(result *PlanDiff, opts *CompareOptions) {
+	w := opts.Writer
+	if w == nil {
+		w = os.Stdout
+	}
 
+	// Print the diff
+	if result.HasDiff {
+		text := result.Text
+		if useColor(w, opts) {
+			text = colorizeDiff(text)
+		}
+
+		fmt.Fprintln(w, "\nDiff Output")
+		fmt.Fprintln(w, "===========")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, text)
 	} else {
-		fmt.Fprintln(os.Stdout, "The planfiles are identical")
+		fmt.Fprintln(w, "The planfiles are identical")
+		if explanation, ok := result.Changes["explanation"].(map[string]interface{}); ok {
+			fmt.Fprint(w, formatExplanation(explanation))
+		}
+	}
+}
+
+// ComparePlans compares two plan JSON documents and returns the diff without printing it.
+// A nil opts uses the defaults.
+func ComparePlans(origPlanFileJSON, newPlanFileJSON string, opts *CompareOptions) (*PlanDiff, error) {
+	differ, err := NewDiffer(opts)
+	if err != nil {
+		return nil, err
 	}
-	return diff_string, diff_map, hasDiff, nil
+	return differ.Compare(origPlanFileJSON, newPlanFileJSON)
 }
 
-// extractJSONFromOutput extracts the JSON part from terraform show output.
+// comparePlanMaps compares two parsed plans with resolved options.
+func comparePlanMaps(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (*PlanDiff, error) {
+	// Refuse plan schemas the comparison may misread
+	if opts.StrictVersion {
+		if err := validateFormatVersion(origPlan); err != nil {
+			return nil, errors.Wrap(err, "error validating original plan")
+		}
+		if err := validateFormatVersion(newPlan); err != nil {
+			return nil, errors.Wrap(err, "error validating new plan")
+		}
+	}
+
+	log.Printf("Parsed both JSONs. Sorting maps now...")
+	normalizeStart := time.Now()
+
+	// Sort maps to ensure consistent ordering
+	origPlan, err := sortMapKeys(origPlan)
+	if err != nil {
+		return nil, errors.Wrap(err, "error normalizing original plan")
+	}
+
+	newPlan, err = sortMapKeys(newPlan)
+	if err != nil {
+		return nil, errors.Wrap(err, "error normalizing new plan")
+	}
+
+	log.Printf("Sorted maps. Generating diff now...")
+	diffStart := time.Now()
+
+	// Generate the diff
+	diffString, diffMap, hasDiff := generatePlanDiff(origPlan, newPlan, opts)
+
+	return &PlanDiff{
+		Text:    diffString,
+		Changes: diffMap,
+		HasDiff: hasDiff,
+		Stats: CompareStats{
+			NormalizeDuration: diffStart.Sub(normalizeStart),
+			DiffDuration:      time.Since(diffStart),
+		},
+	}, nil
+}
+
+// extractJSONFromOutput extracts the JSON part from terraform show output: the first balanced object that
+// starts a line and is valid JSON. Log lines before it, braces inside them and warnings after it are
+// ignored. Objects nested in a truncated or invalid document are never returned on their own.
 func extractJSONFromOutput(output string) (string, error) {
-	// Find the beginning of the JSON output (first '{' character)
-	jsonStartIdx := strings.Index(output, "{")
-	if jsonStartIdx == -1 {
-		return "", ErrNoJSONOutput
+	for start := 0; start < len(output); {
+		next := strings.IndexByte(output[start:], '{')
+		if next == -1 {
+			break
+		}
+		start += next
+
+		// Only a brace at the start of a line opens a document
+		if start > 0 && output[start-1] != '\n' {
+			start++
+			continue
+		}
+
+		end := balancedObjectEnd(output, start)
+		if end == -1 {
+			// Everything that follows is inside the unclosed object
+			break
+		}
+		if json.Valid([]byte(output[start:end])) {
+			return output[start:end], nil
+		}
+
+		// Skip the whole invalid object, so nothing nested in it is taken for a document
+		start = end
 	}
 
-	// Extract just the JSON part
-	jsonOutput := output[jsonStartIdx:]
+	return "", ErrNoJSONOutput
+}
+
+// balancedObjectEnd returns the index just past the brace that closes the object opened at start, or -1
+// if it is never closed. Braces inside JSON strings are skipped.
+func balancedObjectEnd(s string, start int) int {
+	depth := 0
+	inString, escaped := false, false
+
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
 
-	return jsonOutput, nil
+	return -1
 }
@@ -8,81 +8,200 @@ import (
 )
 
 // sortMapKeys recursively sorts map keys for consistent comparison.
-func sortMapKeys(m map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// Get all keys
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-
-	// Sort keys
-	sort.Strings(keys)
-
-	// Process each key in sorted order
-	for _, k := range keys {
-		v := m[k]
-		result[k] = processValue(v)
+// It returns ErrCyclicValue when the map refers back to itself.
+func sortMapKeys(m map[string]interface{}) (map[string]interface{}, error) {
+	result, err := processValue(m, cycleGuard{})
+	if err != nil {
+		return nil, err
 	}
-
-	return result
+	return result.(map[string]interface{}), nil
 }
 
 // processValue recursively processes a value, sorting map keys and handling slices.
-func processValue(v interface{}) interface{} {
+func processValue(v interface{}, guard cycleGuard) (interface{}, error) {
+	if err := guard.enter(v); err != nil {
+		return nil, err
+	}
+	defer guard.leave(v)
+
 	// Handle maps
 	if nestedMap, ok := v.(map[string]interface{}); ok {
-		return sortMapKeys(nestedMap)
+		result := make(map[string]interface{})
+
+		// Get all keys
+		keys := make([]string, 0, len(nestedMap))
+		for k := range nestedMap {
+			keys = append(keys, k)
+		}
+
+		// Sort keys
+		sort.Strings(keys)
+
+		// Process each key in sorted order
+		for _, k := range keys {
+			processed, err := processValue(nestedMap[k], guard)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = processed
+		}
+
+		return result, nil
 	}
 
 	// Handle slices
 	if nestedSlice, ok := v.([]interface{}); ok {
 		processedSlice := make([]interface{}, len(nestedSlice))
 		for i, item := range nestedSlice {
-			processedSlice[i] = processValue(item)
+			processed, err := processValue(item, guard)
+			if err != nil {
+				return nil, err
+			}
+			processedSlice[i] = processed
 		}
-		return processedSlice
+		return processedSlice, nil
 	}
 
 	// Return unchanged for other types
-	return v
+	return v, nil
 }
 
 // generatePlanDiff generates a diff between two terraform plans.
-func generatePlanDiff(origPlan, newPlan map[string]interface{}) (string, map[string]interface{}, bool) {
+func generatePlanDiff(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
 	var diff strings.Builder
 	hasDiff := false
 	diffMap := make(map[string]interface{})
 
+	// Fingerprint each resource once, however often it is compared
+	opts.fingerprints = make(fingerprintCache)
+	defer func() { opts.fingerprints = nil }()
+
 	// Compare variables
-	if varsDiff, varsMap, varsHasDiff := compareVariables(origPlan, newPlan); varsHasDiff {
+	if varsDiff, varsMap, varsHasDiff := compareVariables(origPlan, newPlan, opts); varsHasDiff {
 		hasDiff = true
 		diff.WriteString(varsDiff)
 		diffMap["variables"] = varsMap
 	}
 
 	// Compare resources
-	if resourcesDiff, resourcesMap, resourcesHasDiff := compareResourceSections(origPlan, newPlan); resourcesHasDiff {
+	resourcesDiff, resourcesMap, resourcesHasDiff := compareResourceSections(origPlan, newPlan, opts)
+	if resourcesHasDiff {
 		hasDiff = true
 		diff.WriteString(resourcesDiff)
 		diffMap["resources"] = resourcesMap
+
+		// Report tag-only changes in a section of their own
+		if tagChanges, ok := resourcesMap["tag_changes"]; ok {
+			delete(resourcesMap, "tag_changes")
+			diffMap["tag_changes"] = map[string]interface{}{"changed": tagChanges}
+		}
+	} else {
+		// Keep the diagnostics even when every change was normalized away or noise
+		diagnostics := make(map[string]interface{})
+		for _, category := range []string{"normalized_away", "noise"} {
+			if entries, ok := resourcesMap[category]; ok {
+				diagnostics[category] = entries
+			}
+		}
+		if len(diagnostics) > 0 {
+			diffMap["resources"] = diagnostics
+		}
 	}
 
-	// Compare outputs
-	if outputsDiff, outputsMap, outputsHasDiff := compareOutputSections(origPlan, newPlan); outputsHasDiff {
-		hasDiff = true
+	ignored := takeIgnoredCount(resourcesMap)
+
+	// Compare drift-only resources in a section of their own
+	if opts.DriftMode == DriftModeSection {
+		driftDiff, driftMap, driftHasDiff := compareDriftSections(origPlan, newPlan, opts)
+		if driftHasDiff {
+			hasDiff = true
+			diff.WriteString(driftDiff)
+			diffMap["drift"] = driftMap
+		}
+		ignored += takeIgnoredCount(driftMap)
+	}
+
+	// Record how many changes the ignore set hid, whether or not any other change remains
+	if ignored > 0 {
+		diffMap["ignored"] = ignored
+	}
+
+	// Compare provider major versions and outputs. Like version changes, upgrades explain other changes and
+	// are only reported next to them
+	upgradesDiff, upgrades, upgraded := compareProviderUpgrades(origPlan, newPlan, diffMap["resources"], diffMap["tag_changes"])
+	outputsDiff, outputsMap, outputsHasDiff := compareOutputSections(origPlan, newPlan, opts)
+	hasDiff = hasDiff || outputsHasDiff
+
+	if upgraded && hasDiff {
+		diff.WriteString(upgradesDiff)
+		diffMap["provider_upgrades"] = upgrades
+	}
+
+	if outputsHasDiff {
 		diff.WriteString(outputsDiff)
 		diffMap["outputs"] = outputsMap
 	}
 
+	// Report Terraform and provider version changes, which may explain the other changes. Without other
+	// changes they are only recorded in the diff map, so the text of identical plans stays empty.
+	if metaDiff, metaMap, metaHasDiff := compareProviderVersions(origPlan, newPlan, opts); metaHasDiff {
+		if hasDiff {
+			diff.WriteString(metaDiff)
+		}
+		diffMap["meta"] = metaMap
+	}
+
+	// Explain what was compared when nothing differs
+	if !hasDiff && opts.ExplainIdentical {
+		diffMap["explanation"] = explainIdentical(origPlan, newPlan, opts)
+	}
+
+	// Render the stand-ins of sensitive values as their redaction text
+	resolveSensitiveStandIns(diffMap)
+
+	// Mask values that must never be shown, before capping can cut a match short
+	if len(opts.ValueMasks) > 0 {
+		maskDiffMapValues(diffMap, opts)
+	}
+
+	// Cap huge values such as base64 packages in the diff map
+	if opts.MaxValueLength > 0 {
+		capDiffMapValues(diffMap, opts)
+	}
+
+	// Give every change a stable ID for correlation across runs
+	if opts.IncludeChangeIDs {
+		assignChangeIDs(diffMap)
+	}
+
+	// Stream each change to the caller
+	if opts.OnChange != nil {
+		visitChanges(diffMap, opts.OnChange)
+	}
+
+	// Lead with a one-line count of the changes per section
+	if hasDiff {
+		return SummarizeDiff(diffMap).String() + "\n\n" + diff.String(), diffMap, hasDiff
+	}
 	return diff.String(), diffMap, hasDiff
 }
 
 // compareVariables compares variables between two plans and returns the diff.
-func compareVariables(origPlan, newPlan map[string]interface{}) (string, map[string]interface{}, bool) {
+// Added, removed and changed variables are each listed by name, so the diff is the same on every run.
+// With CompareVariableMetadata, changes to the declared type, sensitivity and nullability are reported
+// too, and the values of sensitive variables are redacted.
+func compareVariables(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
 	origVars, newVars := getVariables(origPlan), getVariables(newPlan)
-	if reflect.DeepEqual(origVars, newVars) {
+
+	var origMetadata, newMetadata map[string]map[string]interface{}
+	if opts.CompareVariableMetadata {
+		origMetadata, newMetadata = getVariableMetadata(origPlan), getVariableMetadata(newPlan)
+	}
+	metadataChanges := diffVariableMetadata(origMetadata, newMetadata)
+	origVars = redactSensitiveVariables(origVars, origMetadata, newMetadata, opts)
+	newVars = redactSensitiveVariables(newVars, origMetadata, newMetadata, opts)
+
+	if reflect.DeepEqual(origVars, newVars) && len(metadataChanges) == 0 {
 		return "", nil, false
 	}
 
@@ -95,38 +214,44 @@ func compareVariables(origPlan, newPlan map[string]interface{}) (string, map[str
 	diff.WriteString("Variables:\n")
 	diff.WriteString("----------\n")
 
+	changes := diffTopLevel(origVars, newVars)
+
 	// Find added variables
-	for k, v := range newVars {
-		if _, exists := origVars[k]; !exists {
-			diff.WriteString(fmt.Sprintf("+ %s: %v\n", k, formatValue(v)))
-			added = append(added, map[string]interface{}{
-				"name":  k,
-				"value": v,
-			})
-		}
+	for _, c := range changesOfKind(changes, ChangeAdded) {
+		diff.WriteString(fmt.Sprintf("+ %s: %v\n", c.Path, formatValue(c.New, opts)))
+		added = append(added, map[string]interface{}{
+			"name":  c.Path,
+			"value": c.New,
+		})
 	}
 
 	// Find removed variables
-	for k, v := range origVars {
-		if _, exists := newVars[k]; !exists {
-			diff.WriteString(fmt.Sprintf("- %s: %v\n", k, formatValue(v)))
-			removed = append(removed, map[string]interface{}{
-				"name":  k,
-				"value": v,
-			})
-		}
+	for _, c := range changesOfKind(changes, ChangeRemoved) {
+		diff.WriteString(fmt.Sprintf("- %s: %v\n", c.Path, formatValue(c.Old, opts)))
+		removed = append(removed, map[string]interface{}{
+			"name":  c.Path,
+			"value": c.Old,
+		})
 	}
 
 	// Find changed variables
-	for k, origV := range origVars {
-		if newV, exists := newVars[k]; exists && !reflect.DeepEqual(origV, newV) {
-			diff.WriteString(fmt.Sprintf("~ %s: %v => %v\n", k, formatValue(origV), formatValue(newV)))
-			changed = append(changed, map[string]interface{}{
-				"name": k,
-				"old":  origV,
-				"new":  newV,
-			})
+	modified := make(map[string]Change)
+	for _, c := range changesOfKind(changes, ChangeModified) {
+		modified[c.Path] = c
+	}
+	for _, name := range changedVariableNames(modified, metadataChanges) {
+		entry := map[string]interface{}{
+			"name": name,
+			"old":  origVars[name],
+			"new":  newVars[name],
+		}
+		if _, ok := modified[name]; ok {
+			diff.WriteString(fmt.Sprintf("~ %s\n", formatChange(name, formatValue(origVars[name], opts), formatValue(newVars[name], opts), opts)))
+		}
+		if fields, ok := metadataChanges[name]; ok {
+			entry["metadata"] = writeVariableMetadataChanges(&diff, name, fields, opts)
 		}
+		changed = append(changed, entry)
 	}
 
 	diff.WriteString("\n")
@@ -138,27 +263,124 @@ func compareVariables(origPlan, newPlan map[string]interface{}) (string, map[str
 	return diff.String(), diffMap, true
 }
 
+// comparedResourceSets returns the resources of two plans that take part in the comparison, filtered and
+// keyed by pairResources.
+func comparedResourceSets(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}) {
+	origResources, newResources := getComparedResources(origPlan, opts), getComparedResources(newPlan, opts)
+
+	// Compare drift-only resources alongside the planned changes
+	if opts.DriftMode == DriftModeMerge {
+		origResources = mergeDriftResources(origPlan, origResources)
+		newResources = mergeDriftResources(newPlan, newResources)
+	}
+
+	return pairResources(origResources, newResources, opts)
+}
+
+// pairResources limits two resource sets to the included addresses and types, and rekeys them by
+// ResourceKeyFunc and the address and type aliases, so resources are paired by the key they share.
+func pairResources(origResources, newResources map[string]interface{}, opts *CompareOptions) (map[string]interface{}, map[string]interface{}) {
+	// Limit the comparison to the included addresses
+	origResources, newResources = filterAddresses(origResources, opts), filterAddresses(newResources, opts)
+
+	// Key resources by the caller's identity instead of their address
+	if opts.ResourceKeyFunc != nil {
+		origResources = keyResources(origResources, opts.ResourceKeyFunc)
+		newResources = keyResources(newResources, opts.ResourceKeyFunc)
+	}
+
+	// Rekey the original resources so aliased addresses pair with their new counterparts
+	if len(opts.AddressAliases) > 0 {
+		origResources = aliasResourceAddresses(origResources, opts.AddressAliases)
+	}
+
+	// Rename aliased resource types so type-renamed resources pair with their new counterparts
+	if len(opts.TypeAliases) > 0 {
+		origResources = aliasResourceTypes(origResources, opts.TypeAliases)
+	}
+
+	return origResources, newResources
+}
+
 // compareResourceSections compares resource sections between two plans and returns the diff.
-func compareResourceSections(origPlan, newPlan map[string]interface{}) (string, map[string]interface{}, bool) {
-	origResources, newResources := getResources(origPlan), getResources(newPlan)
-	if reflect.DeepEqual(origResources, newResources) {
+func compareResourceSections(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
+	origResources, newResources := comparedResourceSets(origPlan, newPlan, opts)
+
+	// Drop provider-computed attributes so only declared intent is compared
+	if opts.IgnoreComputed {
+		declared := getDeclaredAttributes(origPlan, newPlan)
+		origResources = dropComputedAttributes(origResources, declared)
+		newResources = dropComputedAttributes(newResources, declared)
+	}
+
+	// Classify attribute changes by whether the configuration sets them
+	if opts.GroupByDriver {
+		opts.declaredAttributes = getDeclaredAttributes(origPlan, newPlan)
+	}
+
+	// Leave out the ignored resources and attributes, counting the changes they hide
+	origResources, newResources, ignored := ignoreResources(origResources, newResources, opts)
+
+	if sameResources(origResources, newResources, opts) {
+		if ignored > 0 {
+			return "", map[string]interface{}{"ignored": ignored}, false
+		}
 		return "", nil, false
 	}
 
+	resourceDiff, resourceDiffMap := compareResources(origResources, newResources, opts)
+
+	// Take resources whose only changes are to tags out of the resources section
+	var tagDiff string
+	var tagDiffMap map[string]interface{}
+	if opts.SeparateTagChanges {
+		origResources, newResources, tagDiff, tagDiffMap = separateTagChanges(origResources, newResources, resourceDiffMap, opts)
+		if tagDiffMap != nil {
+			resourceDiff, resourceDiffMap = compareResources(origResources, newResources, opts)
+			mergeSetAsideChanges(resourceDiffMap, tagDiffMap)
+		}
+	}
+
+	if ignored > 0 {
+		resourceDiffMap["ignored"] = ignored
+	}
+
+	if resourceDiff == "" && tagDiff == "" {
+		return "", resourceDiffMap, false
+	}
+
 	var diff strings.Builder
-	diff.WriteString("Resources:\n")
-	diff.WriteString("-----------\n")
-	diff.WriteString("\n")
+	if resourceDiff != "" {
+		diff.WriteString("Resources:\n")
+		diff.WriteString("-----------\n")
+		diff.WriteString("\n")
+		diff.WriteString(resourceDiff)
+
+		// Attach estimated cost deltas and their total
+		if opts.CostEstimator != nil {
+			total := annotateCostDeltas(resourceDiffMap, origResources, newResources, opts)
+			resourceDiffMap["cost_delta"] = total
+			diff.WriteString(fmt.Sprintf("\nEstimated monthly cost delta: %s\n", formatCostDelta(total)))
+		}
 
-	resourceDiff, resourceDiffMap := compareResources(origResources, newResources)
-	diff.WriteString(resourceDiff)
-	diff.WriteString("\n")
+		diff.WriteString("\n")
+	}
+
+	// List the tag-only changes in a section of their own
+	if tagDiff != "" {
+		diff.WriteString("Tag Changes:\n")
+		diff.WriteString("------------\n")
+		diff.WriteString("\n")
+		diff.WriteString(tagDiff)
+		diff.WriteString("\n")
+		resourceDiffMap["tag_changes"] = tagDiffMap["changed"]
+	}
 
 	return diff.String(), resourceDiffMap, true
 }
 
 // compareOutputSections compares output sections between two plans and returns the diff.
-func compareOutputSections(origPlan, newPlan map[string]interface{}) (string, map[string]interface{}, bool) {
+func compareOutputSections(origPlan, newPlan map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}, bool) {
 	origOutputs, newOutputs := getOutputs(origPlan), getOutputs(newPlan)
 	if reflect.DeepEqual(origOutputs, newOutputs) {
 		return "", nil, false
@@ -168,9 +390,12 @@ func compareOutputSections(origPlan, newPlan map[string]interface{}) (string, ma
 	diff.WriteString("Outputs:\n")
 	diff.WriteString("--------\n")
 
-	outputDiff, outputDiffMap := compareOutputs(origOutputs, newOutputs)
+	outputDiff, outputDiffMap := compareOutputs(origOutputs, newOutputs, opts)
 	diff.WriteString(outputDiff)
 
+	// Record the action the new plan takes on each output
+	addOutputActions(outputDiffMap, getOutputActions(newPlan))
+
 	return diff.String(), outputDiffMap, true
 }
 
@@ -236,8 +461,10 @@ func processRootModuleResources(rootModule map[string]interface{}, result map[st
 	}
 }
 
-// processResourceChanges extracts resources from resource_changes.
-func processResourceChanges(plan map[string]interface{}, result map[string]interface{}) {
+// processResourceChanges extracts resources from resource_changes. Entries whose actions are ["no-op"] are
+// skipped unless includeNoOp is set, as long as prior_state or planned_values already hold the resource:
+// Terraform leaves those resources unchanged, and their before and after may still differ in computed fields.
+func processResourceChanges(plan map[string]interface{}, result map[string]interface{}, includeNoOp bool) {
 	resourceChanges, ok := plan["resource_changes"].([]interface{})
 	if !ok {
 		return
@@ -259,6 +486,10 @@ func processResourceChanges(plan map[string]interface{}, result map[string]inter
 			continue
 		}
 
+		if _, exists := result[address]; exists && !includeNoOp && isNoOpChange(changeMap) {
+			continue
+		}
+
 		result[address] = changeMap
 	}
 }
@@ -280,19 +511,26 @@ func getVariables(plan map[string]interface{}) map[string]interface{} {
 	return result
 }
 
-// getResources extracts resources from a terraform plan.
+// getResources extracts resources from a terraform plan, leaving out no-op entries of resource_changes.
 func getResources(plan map[string]interface{}) map[string]interface{} {
+	return readResources(plan, false)
+}
+
+// readResources extracts resources from a terraform plan. No-op entries of resource_changes are only
+// read when includeNoOp is set.
+func readResources(plan map[string]interface{}, includeNoOp bool) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	// Process resources from different sections
 	processPriorStateResources(plan, result)
 	processPlannedValuesResources(plan, result)
-	processResourceChanges(plan, result)
+	processResourceChanges(plan, result, includeNoOp)
 
 	return result
 }
 
-// getOutputs extracts outputs from a terraform plan.
+// getOutputs extracts outputs from a terraform plan in the canonical {value, sensitive} shape.
+// Outputs in planned_values take precedence over output_changes, and outputs the plan deletes are left out.
 func getOutputs(plan map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 
@@ -300,7 +538,7 @@ func getOutputs(plan map[string]interface{}) map[string]interface{} {
 	if plannedValues, ok := plan["planned_values"].(map[string]interface{}); ok {
 		if outputs, ok := plannedValues["outputs"].(map[string]interface{}); ok {
 			for k, v := range outputs {
-				result[k] = v
+				result[k] = canonicalPlannedOutput(v)
 			}
 		}
 	}
@@ -308,8 +546,11 @@ func getOutputs(plan map[string]interface{}) map[string]interface{} {
 	// Check output_changes
 	if outputChanges, ok := plan["output_changes"].(map[string]interface{}); ok {
 		for k, v := range outputChanges {
-			if _, exists := result[k]; !exists {
-				result[k] = v
+			if _, exists := result[k]; exists {
+				continue
+			}
+			if output, ok := canonicalOutputChange(v); ok {
+				result[k] = output
 			}
 		}
 	}
@@ -318,45 +559,47 @@ func getOutputs(plan map[string]interface{}) map[string]interface{} {
 }
 
 // compareOutputs compares outputs between two terraform plans.
-func compareOutputs(origOutputs, newOutputs map[string]interface{}) (string, map[string]interface{}) {
+// Added, removed and changed outputs are each listed by name, so the diff is the same on every run.
+func compareOutputs(origOutputs, newOutputs map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}) {
 	var diff strings.Builder
 	diffMap := make(map[string]interface{})
 	added := make([]map[string]interface{}, 0)
 	removed := make([]map[string]interface{}, 0)
 	changed := make([]map[string]interface{}, 0)
 
+	changes := diffTopLevel(origOutputs, newOutputs)
+
 	// Find added outputs
-	for k, v := range newOutputs {
-		if _, exists := origOutputs[k]; !exists {
-			diff.WriteString(fmt.Sprintf("+ %s: %v\n", k, formatValue(v)))
-			added = append(added, map[string]interface{}{
-				"name":  k,
-				"value": v,
-			})
-		}
+	for _, c := range changesOfKind(changes, ChangeAdded) {
+		diff.WriteString(fmt.Sprintf("+ %s: %v\n", c.Path, formatValue(c.New, opts)))
+		added = append(added, map[string]interface{}{
+			"name":  c.Path,
+			"value": c.New,
+		})
 	}
 
 	// Find removed outputs
-	for k, v := range origOutputs {
-		if _, exists := newOutputs[k]; !exists {
-			diff.WriteString(fmt.Sprintf("- %s: %v\n", k, formatValue(v)))
-			removed = append(removed, map[string]interface{}{
-				"name":  k,
-				"value": v,
-			})
-		}
+	for _, c := range changesOfKind(changes, ChangeRemoved) {
+		diff.WriteString(fmt.Sprintf("- %s: %v\n", c.Path, formatValue(c.Old, opts)))
+		removed = append(removed, map[string]interface{}{
+			"name":  c.Path,
+			"value": c.Old,
+		})
 	}
 
 	// Find changed outputs
-	for k, origV := range origOutputs {
-		if newV, exists := newOutputs[k]; exists && !reflect.DeepEqual(origV, newV) {
-			diff.WriteString(formatOutputChange(k, origV, newV))
-			changed = append(changed, map[string]interface{}{
-				"name": k,
-				"old":  origV,
-				"new":  newV,
-			})
-		}
+	for _, c := range changesOfKind(changes, ChangeModified) {
+		diff.WriteString(formatOutputChange(c.Path, c.Old, c.New, opts))
+		changed = append(changed, map[string]interface{}{
+			"name": c.Path,
+			"old":  c.Old,
+			"new":  c.New,
+		})
+	}
+
+	// Flag outputs whose sensitivity changed, since losing it may expose their values
+	if sensitivityChanges := compareOutputSensitivity(&diff, origOutputs, newOutputs, opts); len(sensitivityChanges) > 0 {
+		diffMap["sensitivity_changed"] = sensitivityChanges
 	}
 
 	diffMap["added"] = added
@@ -367,111 +610,253 @@ func compareOutputs(origOutputs, newOutputs map[string]interface{}) (string, map
 }
 
 // compareResources compares resources between two terraform plans.
-func compareResources(origResources, newResources map[string]interface{}) (string, map[string]interface{}) {
+func compareResources(origResources, newResources map[string]interface{}, opts *CompareOptions) (string, map[string]interface{}) {
 	var diff strings.Builder
 	diffMap := make(map[string]interface{})
 
-	// Process resource additions and removals
-	added, removed := processResourceAdditionsAndRemovals(&diff, origResources, newResources)
-	diffMap["added"] = added
-	diffMap["removed"] = removed
+	// Summarize resources that only scaled their number of count instances as a change of count
+	if opts.CollapseCountChurn {
+		var countChanged []map[string]interface{}
+		origResources, newResources, countChanged = collapseCountChurn(&diff, origResources, newResources, opts)
+		diffMap["count_changed"] = countChanged
+	}
+
+	// Align count instances by content so inserted elements do not shift every following index
+	var alignment countAlignment
+	origByAddress, newByAddress := origResources, newResources
+	if opts.AlignCountIndexes {
+		alignment = alignCountInstances(origResources, newResources, opts)
+		origResources, newResources = alignment.origResources, alignment.newResources
+	}
+
+	// Process resource additions, removals and moves unless only shared resources are compared
+	if opts.IntersectionOnly {
+		diffMap["added"] = make([]map[string]interface{}, 0)
+		diffMap["removed"] = make([]map[string]interface{}, 0)
+		diffMap["moved"] = make([]map[string]interface{}, 0)
+	} else {
+		added, removed, moved := processResourceAdditionsAndRemovals(&diff, origResources, newResources, opts)
+		countAdded, countRemoved := processAlignedCountChanges(&diff, alignment, origByAddress, newByAddress, opts)
+		diffMap["added"] = sortEntries(append(added, countAdded...))
+		diffMap["removed"] = sortEntries(append(removed, countRemoved...))
+		diffMap["moved"] = moved
+	}
+
+	// Process resources that switched between managed and data
+	diffMap["mode_changed"] = processModeChanges(&diff, origResources, newResources, opts)
 
 	// Process resource changes
-	changed := processChangedResources(&diff, origResources, newResources)
+	changed, normalizedAway, noise := processChangedResources(&diff, origResources, newResources, opts)
 	diffMap["changed"] = changed
 
+	// Record changes that look like generated values
+	if len(noise) > 0 {
+		diffMap["noise"] = noise
+	}
+
+	// Record changes that normalization suppressed
+	if opts.ReportNormalized && len(normalizedAway) > 0 {
+		sortNormalizedAway(normalizedAway)
+		diffMap["normalized_away"] = normalizedAway
+	}
+
 	return diff.String(), diffMap
 }
 
-// processResourceAdditionsAndRemovals adds information about added and removed resources to the diff.
-func processResourceAdditionsAndRemovals(diff *strings.Builder, origResources, newResources map[string]interface{}) ([]map[string]interface{}, []map[string]interface{}) {
+// processResourceAdditionsAndRemovals adds information about added, removed and moved resources to the diff.
+func processResourceAdditionsAndRemovals(diff *strings.Builder, origResources, newResources map[string]interface{}, opts *CompareOptions) ([]map[string]interface{}, []map[string]interface{}, []map[string]interface{}) {
 	added := make([]map[string]interface{}, 0)
 	removed := make([]map[string]interface{}, 0)
+	moved := make([]map[string]interface{}, 0)
+
+	addedAddrs := missingAddresses(newResources, origResources)
+	removedAddrs := missingAddresses(origResources, newResources)
 
-	// Find added resources
-	for k, v := range newResources {
-		if _, exists := origResources[k]; !exists {
+	// Pair up removals and additions that are really the same resource at a new address
+	moves := detectMoves(removedAddrs, addedAddrs, origResources, newResources, opts)
+	movedAddrs := make(map[string]bool, len(moves)*2)
+	for _, move := range moves {
+		movedAddrs[move.from] = true
+		movedAddrs[move.to] = true
+	}
+
+	// Find added resources, marking imports apart from genuine creates
+	for _, k := range addedAddrs {
+		if movedAddrs[k] {
+			continue
+		}
+		if _, ok := importID(newResources[k]); ok {
+			diff.WriteString(fmt.Sprintf("%s %s (import)\n", importMarker, k))
+		} else {
 			diff.WriteString(fmt.Sprintf("+ %s\n", k))
-			added = append(added, map[string]interface{}{
-				"address": k,
-				"value":   v,
-			})
 		}
+		added = append(added, tagImport(map[string]interface{}{
+			"address": k,
+			"value":   redactResource(newResources[k], opts),
+		}, newResources[k]))
 	}
 
 	// Find removed resources
-	for k, v := range origResources {
-		if _, exists := newResources[k]; !exists {
-			diff.WriteString(fmt.Sprintf("- %s\n", k))
-			removed = append(removed, map[string]interface{}{
-				"address": k,
-				"value":   v,
-			})
+	for _, k := range removedAddrs {
+		if movedAddrs[k] {
+			continue
 		}
+		diff.WriteString(fmt.Sprintf("- %s\n", k))
+		removed = append(removed, map[string]interface{}{
+			"address": k,
+			"value":   redactResource(origResources[k], opts),
+		})
 	}
 
-	return added, removed
+	// Find moved resources
+	for _, move := range moves {
+		moved = append(moved, processMovedResource(diff, move, origResources[move.from], newResources[move.to], opts))
+	}
+
+	return added, removed, moved
+}
+
+// missingAddresses returns the sorted addresses in resources that are not present in other.
+func missingAddresses(resources, other map[string]interface{}) []string {
+	addresses := make([]string, 0)
+	for k := range resources {
+		if _, exists := other[k]; !exists {
+			addresses = append(addresses, k)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// sortEntries sorts diff map entries by their address or name, keeping the order of entries with equal keys.
+func sortEntries(entries []map[string]interface{}) []map[string]interface{} {
+	sort.SliceStable(entries, func(i, j int) bool { return entryKey(entries[i]) < entryKey(entries[j]) })
+	return entries
 }
 
 // processChangedResources processes resources that exist in both but have changes.
-func processChangedResources(diff *strings.Builder, origResources, newResources map[string]interface{}) []map[string]interface{} {
+// It also returns the attribute changes that normalization suppressed when ReportNormalized is set.
+func processChangedResources(diff *strings.Builder, origResources, newResources map[string]interface{}, opts *CompareOptions) ([]map[string]interface{}, []map[string]interface{}, []map[string]interface{}) {
 	changed := make([]map[string]interface{}, 0)
+	normalizedAway := make([]map[string]interface{}, 0)
+	noise := make([]map[string]interface{}, 0)
 
-	for k, origV := range origResources {
+	// Visit resources by address so the diff is the same on every run
+	for _, k := range getSortedKeys(origResources, nil) {
+		origV := origResources[k]
 		newV, exists := newResources[k]
-		if !exists || reflect.DeepEqual(origV, newV) {
+		if !exists || sameResource(origV, newV, opts) || modeChanged(origV, newV) {
 			continue
 		}
 
-		diff.WriteString(fmt.Sprintf("%s\n", k))
+		// Compare resource attributes, leaving out the skipped and ignored ones
+		origAttrs, newAttrs, skippedChanges := dropSkippedAttributes(getComparedAttributes(origV, opts), getComparedAttributes(newV, opts), opts)
+		origAttrs, newAttrs, ignoredChanges := dropIgnoredAttributes(origAttrs, newAttrs, opts)
 
-		// Compare resource attributes
-		origAttrs := getResourceAttributes(origV)
-		newAttrs := getResourceAttributes(newV)
+		// Skip resources whose attributes are equal once normalized, expanded from flatmap or redacted
+		normalized, resolved := normalizeAttributeSets(newV, origAttrs, newAttrs, opts)
+		for _, entry := range resolved {
+			entry["address"] = k
+			normalizedAway = append(normalizedAway, entry)
+		}
 
-		// Process attribute differences
-		attrChanges := processAttributeDifferences(diff, origAttrs, newAttrs)
+		// Set aside attributes whose values both look generated
+		noisy := separateNoise(origAttrs, newAttrs, opts)
+		for _, entry := range noisy {
+			entry["address"] = k
+			noise = append(noise, entry)
+		}
 
-		changed = append(changed, map[string]interface{}{
-			"address":    k,
-			"attributes": attrChanges,
-			// "old":        origV,
-			// "new":        newV,
-		})
-	}
+		if rewrittenAttributesEqual(origV, newV, origAttrs, newAttrs, normalized || skippedChanges > 0 || ignoredChanges > 0 || len(noisy) > 0, opts) {
+			continue
+		}
 
-	return changed
-}
+		// Write the resource to a block of its own, so its attribute values can be aligned, and name the
+		// attribute paths that force its replacement
+		replaced := replacePaths(newV)
+		var block strings.Builder
+		block.WriteString(fmt.Sprintf("%s%s\n", k, replaceNote(replaced)))
+		if opts.ShowContextAttributes {
+			writeContextAttributes(&block, origAttrs, newAttrs, opts)
+		}
 
-// processAttributeDifferences handles comparing and generating diff for resource attributes.
-func processAttributeDifferences(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}) map[string]interface{} {
-	// Important attributes to always show first if they exist
-	priorityAttrs := []string{"id", "url", "content"}
+		entry := map[string]interface{}{
+			"address": k,
+		}
+
+		// Capture the full resources for consumers that evaluate more than the changed attributes
+		if opts.IncludeFullState {
+			entry["old"], entry["new"] = redactResource(origV, opts), redactResource(newV, opts)
+		}
+
+		// Process attribute differences, grouped by driver when the resource has configuration
+		if declared, ok := opts.declaredAttributes[configAddress(k)]; opts.GroupByDriver && ok {
+			entry["attributes"], entry["drivers"] = processAttributesByDriver(&block, k, origAttrs, newAttrs, declared, opts)
+		} else {
+			entry["attributes"] = processAttributeDifferences(&block, origAttrs, newAttrs, opts.attributeOrder(k), opts)
+		}
 
-	// Attributes to skip in the diff to keep it clean
-	skipAttrs := map[string]bool{
-		"response_body_base64": true,
-		"content_base64sha256": true,
-		"content_base64sha512": true,
-		"content_md5":          true,
-		"content_sha1":         true,
-		"content_sha256":       true,
-		"content_sha512":       true,
+		if opts.AlignAttributeValues {
+			diff.WriteString(alignAttributeLines(block.String()))
+		} else {
+			diff.WriteString(block.String())
+		}
+
+		changed = append(changed, tagReplacement(tagImport(entry, newV), replaced))
 	}
 
+	return changed, normalizedAway, noise
+}
+
+// priorityAttrs are important attributes to always show first if they exist.
+var priorityAttrs = []string{"id", "url", "content"}
+
+// priorityAttrSet holds priorityAttrs for constant-time lookups while scanning wide attribute maps.
+var priorityAttrSet = stringSet(priorityAttrs)
+
+// skipAttrs are attributes to skip in the diff to keep it clean.
+var skipAttrs = stringSet([]string{
+	"response_body_base64",
+	"content_base64sha256",
+	"content_base64sha512",
+	"content_md5",
+	"content_sha1",
+	"content_sha256",
+	"content_sha512",
+})
+
+// rewrittenAttributesEqual reports whether the attributes of a changed resource are equal because they
+// were normalized, expanded from flatmap or redacted, or only differ in how numbers are encoded, in which
+// case the resource is not reported.
+func rewrittenAttributesEqual(origV, newV interface{}, origAttrs, newAttrs map[string]interface{}, normalized bool, opts *CompareOptions) bool {
+	if !reflect.DeepEqual(origAttrs, newAttrs) {
+		return valuesEqual(origAttrs, newAttrs, opts)
+	}
+	return normalized || flatmapEncoded(origV) || flatmapEncoded(newV) || hasSensitiveMarks(origV) || hasSensitiveMarks(newV)
+}
+
+// processAttributeDifferences handles comparing and generating diff for resource attributes.
+// A non-nil order lists attributes in their declared order and replaces the default priority ordering.
+func processAttributeDifferences(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, order []string, opts *CompareOptions) map[string]interface{} {
 	attrChanges := make(map[string]interface{})
 	added := make([]map[string]interface{}, 0)
 	removed := make([]map[string]interface{}, 0)
 	changed := make([]map[string]interface{}, 0)
+	skip := opts.skipAttributes(origAttrs, newAttrs)
 
-	// Process priority attributes first
-	processPriorityAttributes(diff, origAttrs, newAttrs, priorityAttrs, &added, &removed, &changed)
+	if order != nil {
+		// Follow the declared attribute order instead of the priority list
+		processOrderedAttributes(diff, origAttrs, newAttrs, order, skip, &added, &removed, &changed, opts)
+	} else {
+		// Process priority attributes first
+		processPriorityAttributes(diff, origAttrs, newAttrs, priorityAttrs, &added, &removed, &changed, opts)
 
-	// Process other attribute changes (not priority, not skipped)
-	processRegularAttributeChanges(diff, origAttrs, newAttrs, priorityAttrs, skipAttrs, &added, &removed, &changed)
+		// Process other attribute changes (not priority, not skipped)
+		processRegularAttributeChanges(diff, origAttrs, newAttrs, priorityAttrSet, skip, &added, &removed, &changed, opts)
 
-	// Find added attributes (that weren't in the priority list)
-	processAddedAttributes(diff, origAttrs, newAttrs, priorityAttrs, skipAttrs, &added)
+		// Find added attributes (that weren't in the priority list)
+		processAddedAttributes(diff, origAttrs, newAttrs, priorityAttrSet, skip, &added, opts)
+	}
 
 	attrChanges["added"] = added
 	attrChanges["removed"] = removed
@@ -481,27 +866,22 @@ func processAttributeDifferences(diff *strings.Builder, origAttrs, newAttrs map[
 }
 
 // processPriorityAttributes handles high-priority attributes that should be shown first.
-func processPriorityAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs []string, added, removed, changed *[]map[string]interface{}) {
+func processPriorityAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs []string, added, removed, changed *[]map[string]interface{}, opts *CompareOptions) {
 	for _, attrK := range priorityAttrs {
 		origAttrV, origExists := origAttrs[attrK]
 		newAttrV, newExists := newAttrs[attrK]
 
 		switch {
-		case origExists && newExists && !reflect.DeepEqual(origAttrV, newAttrV):
-			printAttributeDiff(diff, attrK, origAttrV, newAttrV)
-			*changed = append(*changed, map[string]interface{}{
-				"name": attrK,
-				"old":  origAttrV,
-				"new":  newAttrV,
-			})
+		case origExists && newExists && !valuesEqual(origAttrV, newAttrV, opts):
+			*changed = append(*changed, processAttributeChange(diff, attrK, attrK, origAttrV, newAttrV, 0, opts))
 		case origExists && !newExists:
-			diff.WriteString(fmt.Sprintf("  - %s: %v\n", attrK, formatValue(origAttrV)))
+			diff.WriteString(fmt.Sprintf("  - %s: %v\n", attrK, formatValue(origAttrV, opts)))
 			*removed = append(*removed, map[string]interface{}{
 				"name":  attrK,
 				"value": origAttrV,
 			})
 		case !origExists && newExists:
-			diff.WriteString(fmt.Sprintf("  + %s: %v\n", attrK, formatValue(newAttrV)))
+			diff.WriteString(fmt.Sprintf("  + %s: %v\n", attrK, formatValue(newAttrV, opts)))
 			*added = append(*added, map[string]interface{}{
 				"name":  attrK,
 				"value": newAttrV,
@@ -511,22 +891,18 @@ func processPriorityAttributes(diff *strings.Builder, origAttrs, newAttrs map[st
 }
 
 // processRegularAttributeChanges handles changed and removed attributes.
-func processRegularAttributeChanges(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs []string, skipAttrs map[string]bool, added, removed, changed *[]map[string]interface{}) {
-	for attrK, origAttrV := range origAttrs {
+func processRegularAttributeChanges(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs, skipAttrs map[string]bool, added, removed, changed *[]map[string]interface{}, opts *CompareOptions) {
+	for _, attrK := range getSortedKeys(origAttrs, nil) {
 		// Skip priority attributes (already processed) and attributes in the skip list
-		if contains(priorityAttrs, attrK) || skipAttrs[attrK] {
+		if priorityAttrs[attrK] || skipAttrs[attrK] {
 			continue
 		}
+		origAttrV := origAttrs[attrK]
 
-		if newAttrV, exists := newAttrs[attrK]; exists && !reflect.DeepEqual(origAttrV, newAttrV) {
-			printAttributeDiff(diff, attrK, origAttrV, newAttrV)
-			*changed = append(*changed, map[string]interface{}{
-				"name": attrK,
-				"old":  origAttrV,
-				"new":  newAttrV,
-			})
+		if newAttrV, exists := newAttrs[attrK]; exists && !valuesEqual(origAttrV, newAttrV, opts) {
+			*changed = append(*changed, processAttributeChange(diff, attrK, attrK, origAttrV, newAttrV, 0, opts))
 		} else if !exists {
-			diff.WriteString(fmt.Sprintf("  - %s: %v\n", attrK, formatValue(origAttrV)))
+			diff.WriteString(fmt.Sprintf("  - %s: %v\n", attrK, formatValue(origAttrV, opts)))
 			*removed = append(*removed, map[string]interface{}{
 				"name":  attrK,
 				"value": origAttrV,
@@ -536,10 +912,11 @@ func processRegularAttributeChanges(diff *strings.Builder, origAttrs, newAttrs m
 }
 
 // processAddedAttributes handles new attributes that didn't exist before.
-func processAddedAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs []string, skipAttrs map[string]bool, added *[]map[string]interface{}) {
-	for attrK, newAttrV := range newAttrs {
-		if _, exists := origAttrs[attrK]; !exists && !contains(priorityAttrs, attrK) && !skipAttrs[attrK] {
-			diff.WriteString(fmt.Sprintf("  + %s: %v\n", attrK, formatValue(newAttrV)))
+func processAddedAttributes(diff *strings.Builder, origAttrs, newAttrs map[string]interface{}, priorityAttrs, skipAttrs map[string]bool, added *[]map[string]interface{}, opts *CompareOptions) {
+	for _, attrK := range getSortedKeys(newAttrs, nil) {
+		newAttrV := newAttrs[attrK]
+		if _, exists := origAttrs[attrK]; !exists && !priorityAttrs[attrK] && !skipAttrs[attrK] {
+			diff.WriteString(fmt.Sprintf("  + %s: %v\n", attrK, formatValue(newAttrV, opts)))
 			*added = append(*added, map[string]interface{}{
 				"name":  attrK,
 				"value": newAttrV,
@@ -548,8 +925,20 @@ func processAddedAttributes(diff *strings.Builder, origAttrs, newAttrs map[strin
 	}
 }
 
-// getResourceAttributes extracts attributes from a resource.
-func getResourceAttributes(resource interface{}) map[string]interface{} {
+// getResourceAttributes extracts attributes from a resource, with sensitive values redacted.
+func getResourceAttributes(resource interface{}, opts *CompareOptions) map[string]interface{} {
+	return readResourceAttributes(resource, opts, func(v interface{}) interface{} { return redactedValue(v, opts) })
+}
+
+// getComparedAttributes extracts attributes from a resource for comparison, with sensitive values replaced
+// by stand-ins that still tell changed secrets apart.
+func getComparedAttributes(resource interface{}, opts *CompareOptions) map[string]interface{} {
+	return readResourceAttributes(resource, opts, func(v interface{}) interface{} { return standInValue(v, opts) })
+}
+
+// readResourceAttributes extracts attributes from a resource, replacing sensitive values with the result
+// of redact.
+func readResourceAttributes(resource interface{}, opts *CompareOptions, redact func(interface{}) interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	resMap, ok := resource.(map[string]interface{})
@@ -563,6 +952,25 @@ func getResourceAttributes(resource interface{}) map[string]interface{} {
 	// Extract values from the "change.after" field
 	extractChangeAfterField(resMap, result)
 
+	// Mark values that are only known after apply
+	extractAfterUnknownField(resMap, result)
+
+	// Redact values marked sensitive so they never reach the diff
+	result = replaceSensitiveAttributes(resMap, result, redact)
+
+	// Rebuild nested structures from the legacy flatmap encoding
+	if isFlatmap(result) {
+		result = expandFlatmap(result)
+	}
+
+	// Restrict the comparison to the projected attributes
+	if len(opts.ProjectAttributes) > 0 {
+		result = projectAttributes(result, opts.ProjectAttributes)
+	}
+	if len(opts.AttributeAllowlist) > 0 {
+		result = allowAttributes(result, opts.AttributeAllowlist)
+	}
+
 	return result
 }
 
@@ -595,12 +1003,11 @@ func extractChangeAfterField(resMap map[string]interface{}, result map[string]in
 	}
 }
 
-// contains checks if a string is in a slice.
-func contains(slice []string, s string) bool {
-	for _, item := range slice {
-		if item == s {
-			return true
-		}
+// stringSet builds a set from a slice of strings.
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
 	}
-	return false
+	return set
 }
@@ -1,9 +1,13 @@
 package comparison
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompareOutputs_AllScenarios(t *testing.T) {
@@ -112,7 +116,7 @@ func TestCompareOutputs_AllScenarios(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			diff, _ := compareOutputs(tc.origOutput, tc.newOutput)
+			diff, _ := compareOutputs(tc.origOutput, tc.newOutput, &CompareOptions{})
 
 			if tc.expectDiff {
 				assert.NotEmpty(t, diff, "Expected non-empty diff for different outputs")
@@ -219,7 +223,7 @@ func TestCompareVariables_AllScenarios(t *testing.T) {
 				"variables": makeVariablesMap(tc.newVars),
 			}
 
-			diff, _, hasDiff := compareVariables(origPlan, newPlan)
+			diff, _, hasDiff := compareVariables(origPlan, newPlan, &CompareOptions{})
 
 			assert.Equal(t, tc.expectDiff, hasDiff, "Expected hasDiff to be %v", tc.expectDiff)
 
@@ -322,7 +326,7 @@ func TestCompareResources_AllScenarios(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			diff, _ := compareResources(tc.origRes, tc.newRes)
+			diff, _ := compareResources(tc.origRes, tc.newRes, &CompareOptions{})
 
 			if tc.expectDiff {
 				assert.NotEmpty(t, diff, "Expected non-empty diff for different resources")
@@ -337,6 +341,183 @@ func TestCompareResources_AllScenarios(t *testing.T) {
 }
 
 // makeVariablesMap converts a map of values to a terraform variables map format.
+func TestCompareResources_IntersectionOnly(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_instance.shared":  map[string]interface{}{"values": map[string]interface{}{"instance_type": "t2.micro"}},
+		"aws_instance.removed": map[string]interface{}{"values": map[string]interface{}{"instance_type": "t2.micro"}},
+	}
+	newRes := map[string]interface{}{
+		"aws_instance.shared": map[string]interface{}{"values": map[string]interface{}{"instance_type": "t2.small"}},
+		"aws_instance.added":  map[string]interface{}{"values": map[string]interface{}{"instance_type": "t2.micro"}},
+	}
+
+	diff, diffMap := compareResources(origRes, newRes, &CompareOptions{IntersectionOnly: true})
+
+	assert.Contains(t, diff, "aws_instance.shared")
+	assert.Contains(t, diff, "~ instance_type: t2.micro => t2.small")
+	assert.NotContains(t, diff, "aws_instance.added")
+	assert.NotContains(t, diff, "aws_instance.removed")
+	assert.Empty(t, diffMap["added"])
+	assert.Empty(t, diffMap["removed"])
+	assert.Len(t, diffMap["changed"], 1)
+
+	// Only adds and removes leave nothing to report
+	diff, _ = compareResources(
+		map[string]interface{}{"aws_instance.removed": map[string]interface{}{}},
+		map[string]interface{}{"aws_instance.added": map[string]interface{}{}},
+		&CompareOptions{IntersectionOnly: true},
+	)
+	assert.Empty(t, diff)
+}
+
+func TestCompareResources_DeterministicOrder(t *testing.T) {
+	origRes, newRes := make(map[string]interface{}), make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		origRes[fmt.Sprintf("aws_instance.changed_%02d", i)] = map[string]interface{}{
+			"values": map[string]interface{}{"a": 1, "b": 1, "c": 1, "removed": 1},
+		}
+		newRes[fmt.Sprintf("aws_instance.changed_%02d", i)] = map[string]interface{}{
+			"values": map[string]interface{}{"a": 2, "b": 2, "c": 2, "x_added": 1, "w_added": 1},
+		}
+		origRes[fmt.Sprintf("aws_s3_bucket.removed_%02d", i)] = map[string]interface{}{}
+		newRes[fmt.Sprintf("aws_iam_role.added_%02d", i)] = map[string]interface{}{}
+	}
+
+	opts := resolveOptions(nil)
+	firstDiff, firstMap := compareResources(origRes, newRes, opts)
+	for i := 0; i < 10; i++ {
+		diff, diffMap := compareResources(origRes, newRes, opts)
+		assert.Equal(t, firstDiff, diff)
+		assert.Equal(t, firstMap, diffMap)
+	}
+
+	for _, kind := range []string{"added", "removed", "changed"} {
+		addresses := make([]string, 0)
+		for _, entry := range entryList(firstMap[kind]) {
+			addresses = append(addresses, entryKey(entry))
+		}
+		assert.Len(t, addresses, 20, kind)
+		assert.True(t, sort.StringsAreSorted(addresses), kind)
+	}
+	assert.Contains(t, firstDiff, "aws_instance.changed_00\n  ~ a: 1 => 2\n  ~ b: 1 => 2\n  ~ c: 1 => 2\n  - removed: 1\n  + w_added: 1\n  + x_added: 1\n")
+}
+
+func TestCompareVariablesAndOutputs_SortedByName(t *testing.T) {
+	origValues := map[string]interface{}{"zeta": 1, "beta": 1, "mu": 1, "omega": 1, "alpha": 1}
+	newValues := map[string]interface{}{"zeta": 2, "beta": 2, "mu": 2, "kappa": 1, "delta": 1}
+
+	origPlan := map[string]interface{}{"variables": makeVariablesMap(origValues)}
+	newPlan := map[string]interface{}{"variables": makeVariablesMap(newValues)}
+
+	for i := 0; i < 10; i++ {
+		varsDiff, varsMap, _ := compareVariables(origPlan, newPlan, &CompareOptions{})
+		assert.Equal(t, "Variables:\n----------\n"+
+			"+ delta: 1\n+ kappa: 1\n"+
+			"- alpha: 1\n- omega: 1\n"+
+			"~ beta: 1 => 2\n~ mu: 1 => 2\n~ zeta: 1 => 2\n\n", varsDiff)
+		assert.Equal(t, []string{"beta", "mu", "zeta"}, entryNames(varsMap["changed"]))
+
+		outputsDiff, outputsMap := compareOutputs(origValues, newValues, &CompareOptions{})
+		assert.Equal(t, []string{"delta", "kappa"}, entryNames(outputsMap["added"]))
+		assert.Equal(t, []string{"alpha", "omega"}, entryNames(outputsMap["removed"]))
+		assert.Equal(t, []string{"beta", "mu", "zeta"}, entryNames(outputsMap["changed"]))
+		assert.Less(t, strings.Index(outputsDiff, "+ delta"), strings.Index(outputsDiff, "+ kappa"))
+	}
+}
+
+func entryNames(entries interface{}) []string {
+	names := make([]string, 0)
+	for _, entry := range entryList(entries) {
+		names = append(names, entryKey(entry))
+	}
+	return names
+}
+
+// wideResourceAttributes builds attribute maps with the given number of attributes, where every
+// third attribute changes and the priority and skipped attributes are mixed in.
+func wideResourceAttributes(n int) (map[string]interface{}, map[string]interface{}) {
+	origAttrs := map[string]interface{}{"id": "i-1", "content_md5": "a"}
+	newAttrs := map[string]interface{}{"id": "i-2", "content_md5": "b"}
+
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("attr_%05d", i)
+		origAttrs[key] = "value"
+		newAttrs[key] = "value"
+		if i%3 == 0 {
+			newAttrs[key] = "changed"
+		}
+	}
+
+	return origAttrs, newAttrs
+}
+
+func TestProcessAttributeDifferences_WideAttributes(t *testing.T) {
+	origAttrs, newAttrs := wideResourceAttributes(300)
+
+	var diff strings.Builder
+	attrChanges := processAttributeDifferences(&diff, origAttrs, newAttrs, nil, &CompareOptions{})
+
+	lines := strings.Split(strings.TrimSuffix(diff.String(), "\n"), "\n")
+	assert.Equal(t, "  ~ id: i-1 => i-2", lines[0])
+
+	expected := make([]string, 0)
+	for i := 0; i < 300; i += 3 {
+		expected = append(expected, fmt.Sprintf("  ~ attr_%05d: value => changed", i))
+	}
+	rest := append([]string{}, lines[1:]...)
+	sort.Strings(rest)
+	assert.Equal(t, expected, rest)
+	assert.Len(t, attrChanges["changed"], 101)
+}
+
+func TestProcessAttributeDifferences_SkipAttributes(t *testing.T) {
+	origAttrs := map[string]interface{}{"content_md5": "a", "etag": "1", "source_hash": "x", "bucket": "logs"}
+	newAttrs := map[string]interface{}{"content_md5": "b", "etag": "2", "source_hash": "y", "bucket": "logs-v2"}
+
+	tests := []struct {
+		name    string
+		opts    *CompareOptions
+		changed []string
+	}{
+		{name: "built-in defaults", opts: &CompareOptions{}, changed: []string{"bucket", "etag", "source_hash"}},
+		{name: "custom merged with defaults", opts: &CompareOptions{SkipAttributes: []string{"etag", "source_hash"}}, changed: []string{"bucket"}},
+		{name: "defaults disabled", opts: &CompareOptions{DisableDefaultSkipAttributes: true}, changed: []string{"bucket", "content_md5", "etag", "source_hash"}},
+		{
+			name:    "only custom",
+			opts:    &CompareOptions{SkipAttributes: []string{"etag"}, DisableDefaultSkipAttributes: true},
+			changed: []string{"bucket", "content_md5", "source_hash"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var diff strings.Builder
+			attrChanges := processAttributeDifferences(&diff, origAttrs, newAttrs, nil, tc.opts)
+
+			changed := make([]string, 0)
+			for _, entry := range entryList(attrChanges["changed"]) {
+				changed = append(changed, entryKey(entry))
+			}
+			sort.Strings(changed)
+			assert.Equal(t, tc.changed, changed)
+		})
+	}
+
+	// The built-in set is shared and must not pick up custom attributes
+	assert.False(t, skipAttrs["etag"])
+}
+
+func BenchmarkProcessAttributeDifferences_WideAttributes(b *testing.B) {
+	origAttrs, newAttrs := wideResourceAttributes(5000)
+	opts := &CompareOptions{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var diff strings.Builder
+		processAttributeDifferences(&diff, origAttrs, newAttrs, nil, opts)
+	}
+}
+
 func makeVariablesMap(vars map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 	for k, v := range vars {
@@ -346,3 +527,36 @@ func makeVariablesMap(vars map[string]interface{}) map[string]interface{} {
 	}
 	return result
 }
+
+func TestCompareResources_IncludeFullState(t *testing.T) {
+	origRes := map[string]interface{}{
+		"aws_db_instance.main": map[string]interface{}{"values": map[string]interface{}{
+			"instance_class": "db.t3.micro", "engine": "postgres", "password": "old-secret",
+		}, "sensitive_values": map[string]interface{}{"password": true}},
+	}
+	newRes := map[string]interface{}{
+		"aws_db_instance.main": map[string]interface{}{"values": map[string]interface{}{
+			"instance_class": "db.t3.large", "engine": "postgres", "password": "new-secret",
+		}, "sensitive_values": map[string]interface{}{"password": true}},
+	}
+
+	_, diffMap := compareResources(origRes, newRes, &CompareOptions{})
+	changed := entryList(diffMap["changed"])
+	require.Len(t, changed, 1)
+	assert.NotContains(t, changed[0], "old")
+	assert.NotContains(t, changed[0], "new")
+
+	_, diffMap = compareResources(origRes, newRes, &CompareOptions{IncludeFullState: true})
+	changed = entryList(diffMap["changed"])
+	require.Len(t, changed, 1)
+	assert.Equal(t, map[string]interface{}{"values": map[string]interface{}{
+		"instance_class": "db.t3.micro", "engine": "postgres", "password": "(sensitive value)",
+	}, "sensitive_values": map[string]interface{}{"password": true}}, changed[0]["old"])
+	assert.Equal(t, map[string]interface{}{"values": map[string]interface{}{
+		"instance_class": "db.t3.large", "engine": "postgres", "password": "(sensitive value)",
+	}, "sensitive_values": map[string]interface{}{"password": true}}, changed[0]["new"])
+	assert.Equal(t, []ExpectedChange{
+		{Section: "resources", Kind: "changed", Address: "aws_db_instance.main", Attribute: "instance_class"},
+		{Section: "resources", Kind: "changed", Address: "aws_db_instance.main", Attribute: "password"},
+	}, actualChanges(map[string]interface{}{"resources": diffMap}))
+}
@@ -2,7 +2,6 @@ package comparison
 
 import (
 	"fmt"
-	"reflect"
 	"sort"
 	"strings"
 )
@@ -15,46 +14,54 @@ import (
 // )
 
 // formatOutputChange formats the change between two output values.
-func formatOutputChange(key string, origValue, newValue interface{}) string {
+func formatOutputChange(key string, origValue, newValue interface{}, opts *CompareOptions) string {
 	origSensitive := isSensitive(origValue)
 	newSensitive := isSensitive(newValue)
 
 	switch {
 	case origSensitive && newSensitive:
-		return fmt.Sprintf("~ %s: (sensitive value) => (sensitive value)\n", key)
+		return fmt.Sprintf("~ %s\n", formatChange(key, "(sensitive value)", "(sensitive value)", opts))
 	case origSensitive:
-		return fmt.Sprintf("~ %s: (sensitive value) => %v\n", key, formatValue(newValue))
+		return fmt.Sprintf("~ %s\n", formatChange(key, "(sensitive value)", formatValue(newValue, opts), opts))
 	case newSensitive:
-		return fmt.Sprintf("~ %s: %v => (sensitive value)\n", key, formatValue(origValue))
+		return fmt.Sprintf("~ %s\n", formatChange(key, formatValue(origValue, opts), "(sensitive value)", opts))
 	default:
-		return fmt.Sprintf("~ %s: %v => %v\n", key, formatValue(origValue), formatValue(newValue))
+		return fmt.Sprintf("~ %s\n", formatChange(key, formatValue(origValue, opts), formatValue(newValue, opts), opts))
 	}
 }
 
 // printAttributeDiff handles the formatting of an attribute diff.
-func printAttributeDiff(diff *strings.Builder, attrK string, origAttrV, newAttrV interface{}) {
+func printAttributeDiff(diff *strings.Builder, attrK string, origAttrV, newAttrV interface{}, opts *CompareOptions) {
 	origSensitive := isSensitive(origAttrV)
 	newSensitive := isSensitive(newAttrV)
 
 	switch {
 	case origSensitive && newSensitive:
-		diff.WriteString(fmt.Sprintf("  ~ %s: (sensitive value) => (sensitive value)\n", attrK))
+		diff.WriteString(fmt.Sprintf("  ~ %s\n", formatChange(attrK, "(sensitive value)", "(sensitive value)", opts)))
 	case origSensitive:
-		diff.WriteString(fmt.Sprintf("  ~ %s: (sensitive value) => %v\n", attrK, formatValue(newAttrV)))
+		diff.WriteString(fmt.Sprintf("  ~ %s\n", formatChange(attrK, "(sensitive value)", formatValue(newAttrV, opts), opts)))
 	case newSensitive:
-		diff.WriteString(fmt.Sprintf("  ~ %s: %v => (sensitive value)\n", attrK, formatValue(origAttrV)))
+		diff.WriteString(fmt.Sprintf("  ~ %s\n", formatChange(attrK, formatValue(origAttrV, opts), "(sensitive value)", opts)))
+	case isUnknown(newAttrV):
+		diff.WriteString(fmt.Sprintf("  ~ %s\n", formatChange(attrK, formatValue(origAttrV, opts), knownAfterApplyText, opts)))
 	default:
+		// Show multi-line strings such as user_data or policies line by line, unless a formatter renders them
+		if origStr, newStr, ok := multiLineStrings(origAttrV, newAttrV); ok && opts.ValueFormatter == nil {
+			diff.WriteString(formatUnifiedDiff(attrK, origStr, newStr, opts))
+			return
+		}
+
 		// Check if both values are maps and use the specialized diff function
 		origMap, origIsMap := origAttrV.(map[string]interface{})
 		newMap, newIsMap := newAttrV.(map[string]interface{})
 
 		if origIsMap && newIsMap {
-			mapDiff := formatMapDiff(origMap, newMap)
+			mapDiff := formatMapDiff(origMap, newMap, opts)
 			if mapDiff != noChangesText {
 				diff.WriteString(fmt.Sprintf("  ~ %s: %s\n", attrK, mapDiff))
 			}
 		} else {
-			diff.WriteString(fmt.Sprintf("  ~ %s: %v => %v\n", attrK, formatValue(origAttrV), formatValue(newAttrV)))
+			diff.WriteString(fmt.Sprintf("  ~ %s\n", formatChange(attrK, formatValue(origAttrV, opts), formatValue(newAttrV, opts), opts)))
 		}
 	}
 }
@@ -70,15 +77,26 @@ func isSensitive(value interface{}) bool {
 }
 
 // // formatValue formats a value for display, handling sensitive values.
-func formatValue(value interface{}) string {
+func formatValue(value interface{}, opts *CompareOptions) string {
 	if isSensitive(value) {
 		return "(sensitive value)"
 	}
 
+	formatted := ""
+	if opts.ValueFormatter != nil {
+		formatted = opts.ValueFormatter.FormatValue(value)
+	} else {
+		formatted = formatDefaultValue(value, opts)
+	}
+	return capString(maskString(formatted, opts), opts)
+}
+
+// formatDefaultValue formats a value for display with the default rendering.
+func formatDefaultValue(value interface{}, opts *CompareOptions) string {
 	// Handle different value types
 	switch v := value.(type) {
 	case string:
-		return formatStringValue(v)
+		return formatStringValue(v, opts)
 	case map[string]interface{}:
 		return formatMapValue(v)
 	default:
@@ -87,7 +105,7 @@ func formatValue(value interface{}) string {
 }
 
 // formatStringValue handles formatting of string values.
-func formatStringValue(strVal string) string {
+func formatStringValue(strVal string, opts *CompareOptions) string {
 	// Keep weather report content intact
 	if strings.Contains(strVal, "Weather report:") {
 		return strVal
@@ -98,8 +116,8 @@ func formatStringValue(strVal string) string {
 		return "(base64 encoded value)"
 	}
 
-	// For other very long strings, show start and end
-	if len(strVal) > maxStringDisplayLength {
+	// For other very long strings, show start and end unless plain output was requested
+	if !opts.Plain && len(strVal) > maxStringDisplayLength {
 		return fmt.Sprintf("%s...%s", strVal[:halfStringDisplayLength], strVal[len(strVal)-halfStringDisplayLength:])
 	}
 
@@ -175,22 +193,22 @@ func formatMapForDisplay(m map[string]interface{}) string {
 }
 
 // formatMapDiff formats the difference between two maps showing only changed keys.
-func formatMapDiff(origMap, newMap map[string]interface{}) string {
+func formatMapDiff(origMap, newMap map[string]interface{}, opts *CompareOptions) string {
 	// Get all keys from both maps and sort them
 	keys := getSortedKeys(origMap, newMap)
 
 	// If no differences, return early
-	if reflect.DeepEqual(origMap, newMap) {
+	if valuesEqual(origMap, newMap, opts) {
 		return noChangesText
 	}
 
 	// For empty or very small diffs, use a compact representation
 	if len(keys) <= 3 {
-		return formatCompactMapDiff(keys, origMap, newMap)
+		return formatCompactMapDiff(keys, origMap, newMap, opts)
 	}
 
 	// For larger diffs, show a structured representation with indentation
-	return formatStructuredMapDiff(keys, origMap, newMap)
+	return formatStructuredMapDiff(keys, origMap, newMap, opts)
 }
 
 // getSortedKeys returns a sorted slice of all keys from both maps.
@@ -215,7 +233,7 @@ func getSortedKeys(mapA, mapB map[string]interface{}) []string {
 }
 
 // formatStructuredMapDiff formats a map diff with structured representation for larger diffs.
-func formatStructuredMapDiff(keys []string, origMap, newMap map[string]interface{}) string {
+func formatStructuredMapDiff(keys []string, origMap, newMap map[string]interface{}, opts *CompareOptions) string {
 	var sb strings.Builder
 	sb.WriteString("{\n")
 	changesFound := false
@@ -225,12 +243,12 @@ func formatStructuredMapDiff(keys []string, origMap, newMap map[string]interface
 		newVal, newExists := newMap[k]
 
 		// Skip keys that haven't changed
-		if origExists && newExists && reflect.DeepEqual(origVal, newVal) {
+		if origExists && newExists && valuesEqual(origVal, newVal, opts) {
 			continue
 		}
 
 		changesFound = true
-		formatKeyDiff(&sb, keyDiffParams{k, origVal, newVal, origExists, newExists})
+		formatKeyDiff(&sb, keyDiffParams{k, origVal, newVal, origExists, newExists}, opts)
 	}
 
 	if !changesFound {
@@ -251,19 +269,19 @@ type keyDiffParams struct {
 }
 
 // formatKeyDiff formats a single key difference and appends it to the given string builder.
-func formatKeyDiff(sb *strings.Builder, params keyDiffParams) {
+func formatKeyDiff(sb *strings.Builder, params keyDiffParams, opts *CompareOptions) {
 	// Format based on what changed
 	switch {
 	case !params.origExists:
-		sb.WriteString(fmt.Sprintf("    + %s: %s\n", params.key, formatValue(params.newVal)))
+		sb.WriteString(fmt.Sprintf("    + %s: %s\n", params.key, formatValue(params.newVal, opts)))
 	case !params.newExists:
-		sb.WriteString(fmt.Sprintf("    - %s: %s\n", params.key, formatValue(params.origVal)))
+		sb.WriteString(fmt.Sprintf("    - %s: %s\n", params.key, formatValue(params.origVal, opts)))
 	default:
 		// Value changed
 		if origMap, ok := params.origVal.(map[string]interface{}); ok {
 			if newMap, ok := params.newVal.(map[string]interface{}); ok {
 				// Recursively diff nested maps
-				nestedDiff := formatMapDiff(origMap, newMap)
+				nestedDiff := formatMapDiff(origMap, newMap, opts)
 				if nestedDiff != noChangesText {
 					// Add indentation to nested diff
 					nestedDiff = strings.ReplaceAll(nestedDiff, "\n", "\n    ")
@@ -274,12 +292,12 @@ func formatKeyDiff(sb *strings.Builder, params keyDiffParams) {
 		}
 
 		// Simple value change
-		sb.WriteString(fmt.Sprintf("    ~ %s: %v => %v\n", params.key, formatValue(params.origVal), formatValue(params.newVal)))
+		sb.WriteString(fmt.Sprintf("    ~ %s\n", formatChange(params.key, formatValue(params.origVal, opts), formatValue(params.newVal, opts), opts)))
 	}
 }
 
 // formatCompactMapDiff creates a compact string representation for small map diffs.
-func formatCompactMapDiff(keys []string, origMap, newMap map[string]interface{}) string {
+func formatCompactMapDiff(keys []string, origMap, newMap map[string]interface{}, opts *CompareOptions) string {
 	changes := make([]string, 0, len(keys))
 
 	for _, k := range keys {
@@ -288,11 +306,11 @@ func formatCompactMapDiff(keys []string, origMap, newMap map[string]interface{})
 
 		switch {
 		case !origExists:
-			changes = append(changes, fmt.Sprintf("+%s: %v", k, formatValue(newVal)))
+			changes = append(changes, fmt.Sprintf("+%s: %v", k, formatValue(newVal, opts)))
 		case !newExists:
-			changes = append(changes, fmt.Sprintf("-%s: %v", k, formatValue(origVal)))
-		case !reflect.DeepEqual(origVal, newVal):
-			changes = append(changes, fmt.Sprintf("~%s: %v => %v", k, formatValue(origVal), formatValue(newVal)))
+			changes = append(changes, fmt.Sprintf("-%s: %v", k, formatValue(origVal, opts)))
+		case !valuesEqual(origVal, newVal, opts):
+			changes = append(changes, "~"+formatChange(k, formatValue(origVal, opts), formatValue(newVal, opts), opts))
 		}
 	}
 
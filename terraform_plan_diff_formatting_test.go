@@ -1,5 +1,12 @@
 package comparison
 
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
 // import (
 // 	"fmt"
 // 	"strings"
@@ -226,3 +233,13 @@ package comparison
 // 		})
 // 	}
 // }
+
+func TestFormatStringValue_Plain(t *testing.T) {
+	long := strings.Repeat("a", maxStringDisplayLength) + strings.Repeat("b", maxStringDisplayLength)
+
+	truncated := formatStringValue(long, &CompareOptions{})
+	assert.Contains(t, truncated, "...")
+
+	plain := formatStringValue(long, &CompareOptions{Plain: true})
+	assert.Equal(t, long, plain)
+}
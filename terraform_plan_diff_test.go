@@ -1,5 +1,123 @@
 package comparison
 
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunComparison(t *testing.T) {
+	tests := []struct {
+		name    string
+		newJSON string
+		errIs   error
+		output  string
+	}{
+		{name: "identical plans", newJSON: `{"variables": {"stage": {"value": "dev"}}}`, output: "The planfiles are identical"},
+		{name: "different plans", newJSON: `{"variables": {"stage": {"value": "prod"}}}`, errIs: ErrPlanHasDiff, output: "~ stage: dev => prod"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := RunComparison(`{"variables": {"stage": {"value": "dev"}}}`, tc.newJSON, &CompareOptions{Writer: &out})
+
+			if tc.errIs == nil {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.errIs)
+			}
+			assert.Contains(t, out.String(), tc.output)
+		})
+	}
+
+	err := RunComparison(`{`, `{}`, nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrPlanHasDiff)
+}
+
+func TestExtractJSONFromOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "plain JSON",
+			output:   `{"format_version": "1.2"}`,
+			expected: `{"format_version": "1.2"}`,
+		},
+		{
+			name:     "log lines before the JSON",
+			output:   "Terraform show output\n{\"resource\": {\"nested\": {\"value\": true}}}\n",
+			expected: `{"resource": {"nested": {"value": true}}}`,
+		},
+		{
+			name:     "warning after the JSON",
+			output:   "{\"format_version\": \"1.2\"}\nWarning: Deprecated attribute {foo}\n",
+			expected: `{"format_version": "1.2"}`,
+		},
+		{
+			name:     "brace inside a log line",
+			output:   "[WARN] provider {aws} is deprecated\n{\"format_version\": \"1.2\"}",
+			expected: `{"format_version": "1.2"}`,
+		},
+		{
+			name:     "log line starting with a brace",
+			output:   "{aws} provider is deprecated\n{\"format_version\": \"1.2\"}",
+			expected: `{"format_version": "1.2"}`,
+		},
+		{
+			name:     "braces and escaped quotes inside strings",
+			output:   `{"description": "a \"}\" brace {", "value": "}"} trailing`,
+			expected: `{"description": "a \"}\" brace {", "value": "}"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := extractJSONFromOutput(tc.output)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestExtractJSONFromOutput_NoJSON(t *testing.T) {
+	for _, output := range []string{
+		"Terraform show output without JSON",
+		"{\"format_version\": \"1.2\"",
+		"{\n  \"terraform_version\": \"1.0.0\",\n  invalid json\n}\n",
+		`{"variables": {"env": {"value": "dev"}}, oops}`,
+		"{\"resource_changes\": [\n{\"address\": \"a\"},\n{\"address\": \"b\"",
+		"[WARN] provider {\"aws\": true} is deprecated\n",
+	} {
+		_, err := extractJSONFromOutput(output)
+		assert.Equal(t, ErrNoJSONOutput, err, output)
+	}
+}
+
+func TestComparePlansFromShowOutput(t *testing.T) {
+	origShowOut := "[INFO] running terraform show\n{\"variables\": {\"stage\": {\"value\": \"dev\"}}}\n"
+	newShowOut := "{\"variables\": {\"stage\": {\"value\": \"prod\"}}}\nWarning: deprecated flag\n"
+
+	diff, diffMap, hasDiff, err := ComparePlansFromShowOutput(origShowOut, newShowOut)
+	require.NoError(t, err)
+	assert.True(t, hasDiff)
+	assert.Contains(t, diff, "~ stage: dev => prod")
+	assert.Contains(t, diffMap, "variables")
+
+	_, _, _, err = ComparePlansFromShowOutput("no plan here", newShowOut)
+	assert.ErrorIs(t, err, ErrNoJSONOutput)
+	assert.ErrorContains(t, err, "error extracting original plan JSON")
+
+	_, _, _, err = ComparePlansFromShowOutput(origShowOut, "Error: plan file not found")
+	assert.ErrorIs(t, err, ErrNoJSONOutput)
+	assert.ErrorContains(t, err, "error extracting new plan JSON")
+}
+
 // import (
 // 	"os"
 // 	"path/filepath"
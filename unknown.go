@@ -0,0 +1,74 @@
+package comparison
+
+// extractAfterUnknownField merges the unknown-after-apply markers of a resource's change.after_unknown
+// into its attributes, so attributes that are only known after apply are compared as such instead of
+// looking unchanged or missing.
+func extractAfterUnknownField(resMap map[string]interface{}, result map[string]interface{}) {
+	change, ok := resMap["change"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	afterUnknown, ok := change["after_unknown"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for k, mask := range afterUnknown {
+		if merged, ok := mergeUnknown(result[k], mask); ok {
+			result[k] = merged
+		}
+	}
+}
+
+// mergeUnknown returns value with every part marked in an after_unknown mask replaced by the
+// known-after-apply marker, and whether anything was marked. The mask mirrors the value: true marks a
+// whole value, and nested maps and lists mark their elements.
+func mergeUnknown(value, mask interface{}) (interface{}, bool) {
+	switch typed := mask.(type) {
+	case bool:
+		if typed {
+			return knownAfterApplyText, true
+		}
+	case map[string]interface{}:
+		valueMap, _ := value.(map[string]interface{})
+
+		result := make(map[string]interface{}, len(valueMap))
+		for k, v := range valueMap {
+			result[k] = v
+		}
+		marked := false
+		for k, m := range typed {
+			if merged, ok := mergeUnknown(valueMap[k], m); ok {
+				result[k] = merged
+				marked = true
+			}
+		}
+		if marked {
+			return result, true
+		}
+	case []interface{}:
+		valueList, _ := value.([]interface{})
+
+		result := make([]interface{}, max(len(valueList), len(typed)))
+		copy(result, valueList)
+		marked := false
+		for i, m := range typed {
+			if merged, ok := mergeUnknown(result[i], m); ok {
+				result[i] = merged
+				marked = true
+			}
+		}
+		if marked {
+			return result, true
+		}
+	}
+
+	return value, false
+}
+
+// isUnknown reports whether a value is the known-after-apply marker.
+func isUnknown(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && s == knownAfterApplyText
+}
@@ -0,0 +1,104 @@
+package comparison
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractAfterUnknownField(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    map[string]interface{}
+		unknown  interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "no markers",
+			after:    map[string]interface{}{"ami": "ami-1"},
+			expected: map[string]interface{}{"ami": "ami-1"},
+		},
+		{
+			name:     "top-level attribute missing from after",
+			after:    map[string]interface{}{"ami": "ami-1"},
+			unknown:  map[string]interface{}{"id": true, "arn": true, "ami": false},
+			expected: map[string]interface{}{"ami": "ami-1", "id": knownAfterApplyText, "arn": knownAfterApplyText},
+		},
+		{
+			name:    "nested map and list markers",
+			after:   map[string]interface{}{"tags": map[string]interface{}{"Name": "web"}, "ips": []interface{}{"10.0.0.1"}},
+			unknown: map[string]interface{}{"tags": map[string]interface{}{"Owner": true}, "ips": []interface{}{false, true}},
+			expected: map[string]interface{}{
+				"tags": map[string]interface{}{"Name": "web", "Owner": knownAfterApplyText},
+				"ips":  []interface{}{"10.0.0.1", knownAfterApplyText},
+			},
+		},
+		{
+			name:     "unmarked nested mask keeps the value",
+			after:    map[string]interface{}{"tags": map[string]interface{}{"Name": "web"}},
+			unknown:  map[string]interface{}{"tags": map[string]interface{}{"Name": false}},
+			expected: map[string]interface{}{"tags": map[string]interface{}{"Name": "web"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resMap := map[string]interface{}{"change": map[string]interface{}{"after": tc.after, "after_unknown": tc.unknown}}
+
+			assert.Equal(t, tc.expected, getResourceAttributes(resMap, &CompareOptions{}))
+		})
+	}
+}
+
+func TestComparePlans_KnownAfterApply(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_instance.web", "change": {
+		"after": {"ami": "ami-1", "id": "i-123", "private_ip": "10.0.0.1"}
+	}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_instance.web", "change": {
+		"after": {"ami": "ami-2"},
+		"after_unknown": {"id": true, "private_ip": true}
+	}}]}`
+
+	tests := []struct {
+		name        string
+		opts        *CompareOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "unknown values are shown",
+			opts:        &CompareOptions{},
+			contains:    []string{"  ~ id: i-123 => (known after apply)\n", "  ~ private_ip: 10.0.0.1 => (known after apply)\n"},
+			notContains: []string{"  - id", "  - private_ip"},
+		},
+		{
+			name:        "ignore computed drops unknown values",
+			opts:        &CompareOptions{IgnoreComputed: true},
+			contains:    []string{"  ~ ami: ami-1 => ami-2\n"},
+			notContains: []string{"known after apply"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ComparePlans(origJSON, newJSON, tc.opts)
+			require.NoError(t, err)
+
+			for _, expected := range tc.contains {
+				assert.Contains(t, result.Text, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, result.Text, notExpected)
+			}
+		})
+	}
+}
+
+func TestPrintAttributeDiff_Unknown(t *testing.T) {
+	var diff strings.Builder
+	printAttributeDiff(&diff, "arn", strings.Repeat("a", 200), knownAfterApplyText, &CompareOptions{RenderStyle: RenderStyle{Change: ChangeStyleLabels}})
+
+	assert.True(t, strings.HasSuffix(diff.String(), " to (known after apply)\n"))
+}
@@ -0,0 +1,69 @@
+package comparison
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// planContentKeys are the top-level keys of which a plan has at least one.
+var planContentKeys = []string{"resource_changes", "planned_values", "variables"}
+
+// stateFileKeys are top-level keys of a Terraform state file that a plan never has.
+var stateFileKeys = []string{"lineage", "serial"}
+
+// ValidatePlanJSON checks that data is a Terraform plan as produced by `terraform show -json`, without
+// comparing it: valid JSON holding an object with a format_version and at least one of resource_changes,
+// planned_values or variables. It returns ErrNotAPlan with a description of what is wrong otherwise,
+// including when data is a terraform.tfstate file or the JSON form of a state.
+func ValidatePlanJSON(data []byte) error {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return errors.Wrap(ErrNotAPlan, describeJSONError(data, err).Error())
+	}
+
+	plan, ok := document.(map[string]interface{})
+	if !ok {
+		return errors.Wrapf(ErrNotAPlan, "expected a JSON object, got %s", jsonKind(document))
+	}
+
+	for _, key := range stateFileKeys {
+		if _, ok := plan[key]; ok {
+			return errors.Wrapf(ErrNotAPlan, "found %q, which looks like a Terraform state file", key)
+		}
+	}
+
+	if _, ok := plan["format_version"].(string); !ok {
+		return errors.Wrap(ErrNotAPlan, "format_version is missing")
+	}
+
+	for _, key := range planContentKeys {
+		if _, ok := plan[key]; ok {
+			return nil
+		}
+	}
+	if _, ok := plan["values"]; ok {
+		return errors.Wrapf(ErrNotAPlan, "found values but none of %s, which looks like the output of `terraform show -json` for a state",
+			strings.Join(planContentKeys, ", "))
+	}
+	return errors.Wrapf(ErrNotAPlan, "none of %s found", strings.Join(planContentKeys, ", "))
+}
+
+// jsonKind names the JSON type of a decoded value for error messages.
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,48 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePlanJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		errContains string
+	}{
+		{name: "plan", data: `{"format_version": "1.2", "resource_changes": []}`},
+		{name: "plan with only variables", data: `{"format_version": "1.2", "variables": {}}`},
+		{name: "plan with only planned values", data: `{"format_version": "1.2", "planned_values": {}}`},
+		{
+			name:        "state file",
+			data:        `{"version": 4, "terraform_version": "1.6.0", "serial": 3, "lineage": "abc", "resources": []}`,
+			errContains: `found "lineage", which looks like a Terraform state file: not a terraform plan`,
+		},
+		{
+			name:        "state shown as JSON",
+			data:        `{"format_version": "1.0", "terraform_version": "1.6.0", "values": {"root_module": {}}}`,
+			errContains: "found values but none of resource_changes, planned_values, variables, which looks like the output of `terraform show -json` for a state",
+		},
+		{name: "missing format version", data: `{"resource_changes": []}`, errContains: "format_version is missing"},
+		{name: "arbitrary object", data: `{"format_version": "1.2", "name": "x"}`, errContains: "none of resource_changes, planned_values, variables found"},
+		{name: "array", data: `[{"format_version": "1.2"}]`, errContains: "expected a JSON object, got an array"},
+		{name: "null", data: `null`, errContains: "expected a JSON object, got null"},
+		{name: "malformed", data: `{"format_version": "1.2", "resource_changes": [`, errContains: "invalid JSON at byte offset 47 (recoverable top-level keys: format_version)"},
+		{name: "empty", data: ``, errContains: "invalid JSON at byte offset 0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePlanJSON([]byte(tc.data))
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+			assert.True(t, errors.Is(err, ErrNotAPlan))
+		})
+	}
+}
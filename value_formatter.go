@@ -0,0 +1,63 @@
+package comparison
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// base64TruncatedLength is the number of characters TruncateBase64Formatter keeps of a base64 value.
+const base64TruncatedLength = 40
+
+// ValueFormatter renders the values of attributes, variables and outputs in the text diff. Sensitive
+// values are redacted before a formatter sees them, and the diff map always holds the raw values.
+type ValueFormatter interface {
+	FormatValue(value interface{}) string
+}
+
+// ValueFormatterFunc adapts a function to a ValueFormatter.
+type ValueFormatterFunc func(value interface{}) string
+
+// FormatValue calls f(value).
+func (f ValueFormatterFunc) FormatValue(value interface{}) string {
+	return f(value)
+}
+
+// DefaultValueFormatter is the ValueFormatter used when none is configured. Strings longer than 100
+// characters are shortened to their start and end unless Plain is set, and maps are listed by key.
+type DefaultValueFormatter struct {
+	Plain bool
+}
+
+// FormatValue renders a value the way the text diff does by default.
+func (f DefaultValueFormatter) FormatValue(value interface{}) string {
+	return formatDefaultValue(value, &CompareOptions{Plain: f.Plain})
+}
+
+// TruncateBase64Formatter shortens base64 encoded strings longer than 40 characters, such as Lambda
+// deployment packages, to their first 40 characters followed by "...(truncated)". Other values are
+// rendered by Next, or by the default formatter when Next is nil.
+type TruncateBase64Formatter struct {
+	Next ValueFormatter
+}
+
+// FormatValue renders a value, truncating base64 encoded strings.
+func (f TruncateBase64Formatter) FormatValue(value interface{}) string {
+	if str, ok := value.(string); ok && isBase64(str) {
+		return str[:base64TruncatedLength] + "...(truncated)"
+	}
+
+	if f.Next == nil {
+		return DefaultValueFormatter{}.FormatValue(value)
+	}
+	return f.Next.FormatValue(value)
+}
+
+// isBase64 reports whether s is a standard base64 encoding longer than base64TruncatedLength.
+func isBase64(s string) bool {
+	if len(s) <= base64TruncatedLength || len(s)%4 != 0 || strings.ContainsAny(s, " \t\r\n") {
+		return false
+	}
+
+	_, err := base64.StdEncoding.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package comparison
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultValueFormatter(t *testing.T) {
+	long := strings.Repeat("a", 50) + strings.Repeat("b", 60)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		plain    bool
+		expected string
+	}{
+		{name: "string", value: "t3.micro", expected: "t3.micro"},
+		{name: "number", value: 80.0, expected: "80"},
+		{name: "map", value: map[string]interface{}{"b": 2, "a": 1}, expected: "{a: 1, b: 2}"},
+		{name: "long string", value: long, expected: long[:40] + "..." + long[len(long)-40:]},
+		{name: "long string with plain output", value: long, plain: true, expected: long},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DefaultValueFormatter{Plain: tc.plain}.FormatValue(tc.value))
+			assert.Equal(t, formatValue(tc.value, &CompareOptions{Plain: tc.plain}), DefaultValueFormatter{Plain: tc.plain}.FormatValue(tc.value))
+		})
+	}
+}
+
+func TestTruncateBase64Formatter(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("zip file contents ", 10)))
+
+	tests := []struct {
+		name      string
+		formatter TruncateBase64Formatter
+		value     interface{}
+		expected  string
+	}{
+		{name: "base64 blob", value: blob, expected: blob[:40] + "...(truncated)"},
+		{name: "short base64", value: "aGVsbG8=", expected: "aGVsbG8="},
+		{name: "not base64", value: strings.Repeat("not base64! ", 5), expected: strings.Repeat("not base64! ", 5)},
+		{name: "other values use the default", value: map[string]interface{}{"a": 1}, expected: "{a: 1}"},
+		{
+			name:      "other values use next",
+			formatter: TruncateBase64Formatter{Next: ValueFormatterFunc(func(value interface{}) string { return "custom" })},
+			value:     42,
+			expected:  "custom",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.formatter.FormatValue(tc.value))
+		})
+	}
+}
+
+func TestComparePlans_ValueFormatter(t *testing.T) {
+	orig := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("old package ", 10)))
+	updated := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("new package ", 10)))
+	plan := func(source, password string) string {
+		return `{"resource_changes": [{"address": "aws_lambda_function.api", "change": {
+			"after": {"source_code": "` + source + `", "password": "` + password + `"},
+			"after_sensitive": {"password": true}
+		}}]}`
+	}
+
+	result, err := ComparePlans(plan(orig, "a"), plan(updated, "b"), &CompareOptions{
+		ValueFormatter:      TruncateBase64Formatter{},
+		HashSensitiveValues: true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "~ source_code: "+orig[:40]+"...(truncated) => "+updated[:40]+"...(truncated)")
+	assert.Contains(t, result.Text, "~ password: (sensitive value ")
+
+	changes := result.Changes["resources"].(map[string]interface{})["changed"].([]map[string]interface{})
+	attrs := changes[0]["attributes"].(map[string]interface{})["changed"].([]map[string]interface{})
+	assert.Contains(t, attrs, map[string]interface{}{"name": "source_code", "old": orig, "new": updated})
+}
@@ -0,0 +1,84 @@
+package comparison
+
+import "fmt"
+
+// valueTotalNote follows a value cut to MaxValueLength, and plainValueTotalNote does with Plain output.
+// valueMoreLinesNote and plainValueMoreLinesNote replace the lines of a multi-line value past the cap.
+const (
+	valueTotalNote          = "…(%d bytes total)"
+	plainValueTotalNote     = "...(%d bytes total)"
+	valueMoreLinesNote      = "…(%d more lines)"
+	plainValueMoreLinesNote = "...(%d more lines)"
+)
+
+// diffMapValueKeys are the keys of diff map entries that hold plan values.
+var diffMapValueKeys = stringSet([]string{"old", "new", "value", "import_id"})
+
+// capString cuts s to MaxValueLength bytes, without splitting a UTF-8 sequence, and notes its full length.
+// A MaxValueLength of zero or less leaves s as it is.
+func capString(s string, opts *CompareOptions) string {
+	if opts.MaxValueLength <= 0 || len(s) <= opts.MaxValueLength {
+		return s
+	}
+
+	note := valueTotalNote
+	if opts.Plain {
+		note = plainValueTotalNote
+	}
+	return truncateString(s, opts.MaxValueLength) + fmt.Sprintf(note, len(s))
+}
+
+// moreLinesNote notes the number of lines of a multi-line value left out past MaxValueLength.
+func moreLinesNote(n int, opts *CompareOptions) string {
+	if opts.Plain {
+		return fmt.Sprintf(plainValueMoreLinesNote, n)
+	}
+	return fmt.Sprintf(valueMoreLinesNote, n)
+}
+
+// capDiffMapValues caps the strings in the values of every diff map entry to MaxValueLength bytes.
+func capDiffMapValues(m map[string]interface{}, opts *CompareOptions) {
+	rewriteDiffMapValues(m, func(s string) string { return capString(s, opts) })
+}
+
+// rewriteDiffMapValues replaces every string in the values of every diff map entry with the result of
+// rewrite, recursing into sections and nested attribute changes. Values are replaced by rewritten copies;
+// the plans they came from are untouched.
+func rewriteDiffMapValues(m map[string]interface{}, rewrite func(string) string) {
+	for k, v := range m {
+		if diffMapValueKeys[k] {
+			m[k] = rewriteStrings(v, rewrite)
+			continue
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok {
+			rewriteDiffMapValues(nested, rewrite)
+			continue
+		}
+		for _, entry := range entryList(v) {
+			rewriteDiffMapValues(entry, rewrite)
+		}
+	}
+}
+
+// rewriteStrings returns a copy of a value with every string in it replaced by the result of rewrite.
+func rewriteStrings(v interface{}, rewrite func(string) string) interface{} {
+	switch typed := v.(type) {
+	case string:
+		return rewrite(typed)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, nested := range typed {
+			result[k] = rewriteStrings(nested, rewrite)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, nested := range typed {
+			result[i] = rewriteStrings(nested, rewrite)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,74 @@
+package comparison
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapString(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		maxLength int
+		plain     bool
+		expected  string
+	}{
+		{name: "unlimited", value: strings.Repeat("a", 20), expected: strings.Repeat("a", 20)},
+		{name: "short", value: "abc", maxLength: 5, expected: "abc"},
+		{name: "exact", value: "abcde", maxLength: 5, expected: "abcde"},
+		{name: "long", value: strings.Repeat("a", 20), maxLength: 5, expected: "aaaaa…(20 bytes total)"},
+		{name: "multi-byte", value: "ééééé", maxLength: 3, expected: "é…(10 bytes total)"},
+		{name: "plain", value: strings.Repeat("a", 20), maxLength: 5, plain: true, expected: "aaaaa...(20 bytes total)"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			capped := capString(tc.value, &CompareOptions{MaxValueLength: tc.maxLength, Plain: tc.plain})
+			assert.Equal(t, tc.expected, capped)
+			assert.True(t, utf8.ValidString(capped))
+		})
+	}
+}
+
+func TestComparePlans_MaxValueLength(t *testing.T) {
+	orig := strings.Repeat("a", 500)
+	updated := strings.Repeat("b", 500) + "ü"
+	plan := func(userData string) string {
+		return `{
+			"variables": {"script": {"value": "` + userData + `"}},
+			"resource_changes": [{"address": "aws_instance.web", "change": {"after": {
+				"user_data": "` + userData + `", "ami": "ami-1", "tags": {"script": "` + userData + `"}
+			}}}]
+		}`
+	}
+
+	result, err := ComparePlans(plan(orig), plan(updated), &CompareOptions{MaxValueLength: 10, Plain: true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "~ user_data: aaaaaaaaaa...(500 bytes total) => bbbbbbbbbb...(502 bytes total)")
+	assert.Contains(t, result.Text, "~ script: aaaaaaaaaa...(500 bytes total) => bbbbbbbbbb...(502 bytes total)")
+	assert.NotContains(t, result.Text, strings.Repeat("a", 11))
+
+	resources := result.Changes["resources"].(map[string]interface{})["changed"].([]map[string]interface{})
+	attrs := resources[0]["attributes"].(map[string]interface{})["changed"].([]map[string]interface{})
+	assert.Contains(t, attrs, map[string]interface{}{
+		"name": "user_data",
+		"old":  "aaaaaaaaaa...(500 bytes total)",
+		"new":  "bbbbbbbbbb...(502 bytes total)",
+	})
+
+	marshaled, err := json.Marshal(result.Changes)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(marshaled))
+	assert.NotContains(t, string(marshaled), strings.Repeat("b", 11))
+
+	// Without the option the values are reported in full
+	result, err = ComparePlans(plan(orig), plan(updated), &CompareOptions{Plain: true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, orig)
+	assert.NotContains(t, result.Text, "bytes total")
+}
@@ -0,0 +1,35 @@
+package comparison
+
+// maskedValue replaces the parts of a value that match one of the ValueMasks.
+const maskedValue = "***"
+
+// maskString replaces every match of the ValueMasks in s with maskedValue.
+func maskString(s string, opts *CompareOptions) string {
+	for _, pattern := range opts.ValueMasks {
+		if pattern != nil {
+			s = pattern.ReplaceAllLiteralString(s, maskedValue)
+		}
+	}
+	return s
+}
+
+// maskDiffMapValues masks the strings in the values of every diff map entry, including those nested in
+// maps and lists.
+func maskDiffMapValues(m map[string]interface{}, opts *CompareOptions) {
+	rewriteDiffMapValues(m, func(s string) string { return maskString(s, opts) })
+}
+
+// maskChanges returns copies of changes with the strings in their values masked.
+func maskChanges(changes []Change, opts *CompareOptions) []Change {
+	if len(opts.ValueMasks) == 0 {
+		return changes
+	}
+
+	mask := func(s string) string { return maskString(s, opts) }
+	masked := make([]Change, len(changes))
+	for i, c := range changes {
+		c.Old, c.New = rewriteStrings(c.Old, mask), rewriteStrings(c.New, mask)
+		masked[i] = c
+	}
+	return masked
+}
@@ -0,0 +1,94 @@
+package comparison
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	accountIDMask = regexp.MustCompile(`\b\d{12}\b`)
+	emailMask     = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+)
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		masks    []*regexp.Regexp
+		expected string
+	}{
+		{name: "no masks", value: "arn:aws:iam::123456789012:role/app", expected: "arn:aws:iam::123456789012:role/app"},
+		{name: "account ID", value: "arn:aws:iam::123456789012:role/app", masks: []*regexp.Regexp{accountIDMask}, expected: "arn:aws:iam::***:role/app"},
+		{name: "every match", value: "ops@example.com, dev@example.com", masks: []*regexp.Regexp{emailMask}, expected: "***, ***"},
+		{name: "several masks", value: "123456789012 ops@example.com", masks: []*regexp.Regexp{accountIDMask, emailMask}, expected: "*** ***"},
+		{name: "replacement is literal", value: "a$1b", masks: []*regexp.Regexp{regexp.MustCompile(`\$1`)}, expected: "a***b"},
+		{name: "nil mask", value: "abc", masks: []*regexp.Regexp{nil}, expected: "abc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, maskString(tc.value, &CompareOptions{ValueMasks: tc.masks}))
+		})
+	}
+}
+
+func TestComparePlans_ValueMasks(t *testing.T) {
+	origJSON := `{
+		"variables": {"owner": {"value": "ops@example.com"}},
+		"resource_changes": [
+			{"address": "aws_iam_role.app", "change": {"after": {
+				"arn": "arn:aws:iam::123456789012:role/app",
+				"tags": {"Owner": "ops@example.com"},
+				"trusted": ["arn:aws:iam::123456789012:root"],
+				"policy": "line 1\naccount 123456789012\nline 3"
+			}}}
+		],
+		"planned_values": {"outputs": {"account": {"value": "123456789012"}}}
+	}`
+	newJSON := `{
+		"variables": {"owner": {"value": "dev@example.com"}},
+		"resource_changes": [
+			{"address": "aws_iam_role.app", "change": {"after": {
+				"arn": "arn:aws:iam::210987654321:role/app",
+				"tags": {"Owner": "dev@example.com"},
+				"trusted": ["arn:aws:iam::210987654321:root"],
+				"policy": "line 1\naccount 210987654321\nline 3"
+			}}},
+			{"address": "aws_s3_bucket.logs", "change": {"after": {"bucket": "logs-123456789012"}}}
+		],
+		"planned_values": {"outputs": {"account": {"value": "210987654321"}}}
+	}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{ValueMasks: []*regexp.Regexp{accountIDMask, emailMask}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Text)
+
+	for _, secret := range []string{"123456789012", "210987654321", "ops@example.com", "dev@example.com"} {
+		assert.NotContains(t, result.Text, secret)
+
+		encoded, err := json.Marshal(result.Changes)
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), secret)
+	}
+	assert.Contains(t, result.Text, "~ owner: *** => ***\n")
+	assert.Contains(t, result.Text, "~ arn: arn:aws:iam::***:role/app => arn:aws:iam::***:role/app\n")
+	assert.Contains(t, result.Text, "  ~ policy:\n      line 1\n    - account ***\n    + account ***\n      line 3\n")
+}
+
+func TestCompareStreaming_ValueMasks(t *testing.T) {
+	origJSON := `{"resource_changes": [{"address": "aws_iam_role.app", "change": {"after": {"arn": "arn:aws:iam::123456789012:role/app"}}}]}`
+	newJSON := `{"resource_changes": [{"address": "aws_iam_role.app", "change": {"after": {"arn": "arn:aws:iam::210987654321:role/app"}}}]}`
+
+	var streamed []StreamedChange
+	_, err := CompareStreaming(origJSON, strings.NewReader(newJSON), &CompareOptions{ValueMasks: []*regexp.Regexp{accountIDMask}}, func(c StreamedChange) {
+		streamed = append(streamed, c)
+	})
+	require.NoError(t, err)
+	require.Len(t, streamed, 1)
+	assert.Equal(t, []Change{{Path: "arn", Kind: ChangeModified, Old: "arn:aws:iam::***:role/app", New: "arn:aws:iam::***:role/app"}}, streamed[0].Attributes)
+}
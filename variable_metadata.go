@@ -0,0 +1,124 @@
+package comparison
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// variableMetadataFields are the variable declaration fields compared by CompareVariableMetadata.
+var variableMetadataFields = []string{"type", "sensitive", "nullable"}
+
+// variableMetadataDefaults are the values Terraform assumes for declaration fields a plan leaves out.
+var variableMetadataDefaults = map[string]interface{}{"sensitive": false, "nullable": true}
+
+// getVariableMetadata returns the declaration fields of each variable, read from the variables section
+// and from the variable blocks of the plan's root module configuration. Missing fields take their defaults.
+func getVariableMetadata(plan map[string]interface{}) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	sources := make([]map[string]interface{}, 0, 2)
+
+	if vars, ok := plan["variables"].(map[string]interface{}); ok {
+		sources = append(sources, vars)
+	}
+	if configuration, ok := plan["configuration"].(map[string]interface{}); ok {
+		if rootModule, ok := configuration["root_module"].(map[string]interface{}); ok {
+			if vars, ok := rootModule["variables"].(map[string]interface{}); ok {
+				sources = append(sources, vars)
+			}
+		}
+	}
+
+	for _, vars := range sources {
+		for name, v := range vars {
+			varMap, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			metadata, ok := result[name]
+			if !ok {
+				metadata = make(map[string]interface{})
+				for field, value := range variableMetadataDefaults {
+					metadata[field] = value
+				}
+				result[name] = metadata
+			}
+			for _, field := range variableMetadataFields {
+				if value, exists := varMap[field]; exists {
+					metadata[field] = value
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// diffVariableMetadata returns the declaration fields that changed for each variable declared in both plans.
+func diffVariableMetadata(origMetadata, newMetadata map[string]map[string]interface{}) map[string][]Change {
+	result := make(map[string][]Change)
+
+	for name, origFields := range origMetadata {
+		newFields, ok := newMetadata[name]
+		if !ok {
+			continue
+		}
+
+		for _, field := range variableMetadataFields {
+			if !reflect.DeepEqual(origFields[field], newFields[field]) {
+				result[name] = append(result[name], Change{Path: field, Kind: ChangeModified, Old: origFields[field], New: newFields[field]})
+			}
+		}
+	}
+
+	return result
+}
+
+// isSensitiveVariable reports whether a variable is declared sensitive.
+func isSensitiveVariable(metadata map[string]map[string]interface{}, name string) bool {
+	sensitive, _ := metadata[name]["sensitive"].(bool)
+	return sensitive
+}
+
+// writeVariableMetadataChanges writes a line for each changed declaration field of a variable and
+// returns the changes as a map of field to old and new value.
+func writeVariableMetadataChanges(diff *strings.Builder, name string, changes []Change, opts *CompareOptions) map[string]interface{} {
+	result := make(map[string]interface{}, len(changes))
+
+	for _, c := range changes {
+		line := formatChange(joinPath(name, c.Path), maskString(fmt.Sprint(c.Old), opts), maskString(fmt.Sprint(c.New), opts), opts)
+		if c.Path == "sensitive" && c.New == false {
+			line += " (no longer sensitive)"
+		}
+		diff.WriteString(fmt.Sprintf("~ %s\n", line))
+		result[c.Path] = map[string]interface{}{"old": c.Old, "new": c.New}
+	}
+
+	return result
+}
+
+// redactSensitiveVariables returns a copy of vars with the values of variables declared sensitive in
+// either plan replaced by stand-ins, so changed values are still reported but never shown.
+func redactSensitiveVariables(vars map[string]interface{}, origMetadata, newMetadata map[string]map[string]interface{}, opts *CompareOptions) map[string]interface{} {
+	result := make(map[string]interface{}, len(vars))
+	for name, value := range vars {
+		if value != nil && (isSensitiveVariable(origMetadata, name) || isSensitiveVariable(newMetadata, name)) {
+			value = standInValue(value, opts)
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// changedVariableNames returns the sorted names of variables whose value or declaration changed.
+func changedVariableNames(modified map[string]Change, metadataChanges map[string][]Change) []string {
+	names := make(map[string]interface{}, len(modified)+len(metadataChanges))
+	for name := range modified {
+		names[name] = true
+	}
+	for name := range metadataChanges {
+		names[name] = true
+	}
+	return getSortedKeys(names, nil)
+}
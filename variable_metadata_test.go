@@ -0,0 +1,146 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVariableMetadata(t *testing.T) {
+	plan := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"region":   map[string]interface{}{"value": "eu-west-1"},
+			"password": map[string]interface{}{"value": "secret", "type": "string"},
+		},
+		"configuration": map[string]interface{}{
+			"root_module": map[string]interface{}{
+				"variables": map[string]interface{}{
+					"password": map[string]interface{}{"sensitive": true, "description": "ignored"},
+					"tags":     map[string]interface{}{"nullable": false},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]map[string]interface{}{
+		"region":   {"sensitive": false, "nullable": true},
+		"password": {"sensitive": true, "nullable": true, "type": "string"},
+		"tags":     {"sensitive": false, "nullable": false},
+	}, getVariableMetadata(plan))
+}
+
+func TestCompareVariables_Metadata(t *testing.T) {
+	plan := func(value string, sensitive bool, varType string) map[string]interface{} {
+		return map[string]interface{}{
+			"variables": map[string]interface{}{
+				"password": map[string]interface{}{"value": value, "type": varType},
+				"region":   map[string]interface{}{"value": "eu-west-1"},
+			},
+			"configuration": map[string]interface{}{
+				"root_module": map[string]interface{}{
+					"variables": map[string]interface{}{"password": map[string]interface{}{"sensitive": sensitive}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		orig, new   map[string]interface{}
+		opts        *CompareOptions
+		hasDiff     bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:    "metadata ignored by default",
+			orig:    plan("a", false, "string"),
+			new:     plan("a", true, "string"),
+			opts:    &CompareOptions{},
+			hasDiff: false,
+		},
+		{
+			name:        "becomes sensitive",
+			orig:        plan("a", false, "string"),
+			new:         plan("a", true, "string"),
+			opts:        &CompareOptions{CompareVariableMetadata: true},
+			hasDiff:     true,
+			contains:    []string{"~ password.sensitive: false => true\n"},
+			notContains: []string{"~ password:", "region"},
+		},
+		{
+			name:     "no longer sensitive",
+			orig:     plan("a", true, "string"),
+			new:      plan("a", false, "string"),
+			opts:     &CompareOptions{CompareVariableMetadata: true},
+			hasDiff:  true,
+			contains: []string{"~ password.sensitive: true => false (no longer sensitive)\n"},
+		},
+		{
+			name:        "type only change",
+			orig:        plan("a", false, "string"),
+			new:         plan("a", false, "number"),
+			opts:        &CompareOptions{CompareVariableMetadata: true},
+			hasDiff:     true,
+			contains:    []string{"~ password.type: string => number\n"},
+			notContains: []string{"sensitive"},
+		},
+		{
+			name:        "sensitive value changes are redacted",
+			orig:        plan("a", true, "string"),
+			new:         plan("b", true, "string"),
+			opts:        &CompareOptions{CompareVariableMetadata: true},
+			hasDiff:     true,
+			contains:    []string{"~ password: (sensitive value) => (sensitive value)\n"},
+			notContains: []string{"a =>", "=> b"},
+		},
+		{
+			name:    "unchanged sensitive values compare equal",
+			orig:    plan("a", true, "string"),
+			new:     plan("a", true, "string"),
+			opts:    &CompareOptions{CompareVariableMetadata: true},
+			hasDiff: false,
+		},
+		{
+			name:        "sensitive values are hashed",
+			orig:        plan("a", true, "string"),
+			new:         plan("b", true, "string"),
+			opts:        &CompareOptions{CompareVariableMetadata: true, HashSensitiveValues: true},
+			hasDiff:     true,
+			contains:    []string{"~ password: (sensitive value "},
+			notContains: []string{"a =>", "=> b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, _, hasDiff := compareVariables(tc.orig, tc.new, tc.opts)
+			assert.Equal(t, tc.hasDiff, hasDiff)
+			for _, expected := range tc.contains {
+				assert.Contains(t, diff, expected)
+			}
+			for _, notExpected := range tc.notContains {
+				assert.NotContains(t, diff, notExpected)
+			}
+		})
+	}
+}
+
+func TestComparePlans_VariableMetadataMap(t *testing.T) {
+	origJSON := `{"variables": {"token": {"value": "abc"}}, "configuration": {"root_module": {"variables": {"token": {"sensitive": true}}}}}`
+	newJSON := `{"variables": {"token": {"value": "abc"}}, "configuration": {"root_module": {"variables": {"token": {}}}}}`
+
+	result, err := ComparePlans(origJSON, newJSON, &CompareOptions{CompareVariableMetadata: true})
+	require.NoError(t, err)
+
+	changed := result.Changes["variables"].(map[string]interface{})["changed"].([]map[string]interface{})
+	require.Len(t, changed, 1)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "token",
+		"old":      "(sensitive value)",
+		"new":      "(sensitive value)",
+		"metadata": map[string]interface{}{"sensitive": map[string]interface{}{"old": true, "new": false}},
+	}, changed[0])
+	assert.NotContains(t, result.Text, "abc")
+}